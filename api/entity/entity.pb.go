@@ -24,8 +24,9 @@ const (
 type EntityResponse_RespCodeType int32
 
 const (
-	EntityResponse_OK     EntityResponse_RespCodeType = 0
-	EntityResponse_FAILED EntityResponse_RespCodeType = 1
+	EntityResponse_OK           EntityResponse_RespCodeType = 0
+	EntityResponse_FAILED       EntityResponse_RespCodeType = 1
+	EntityResponse_RATE_LIMITED EntityResponse_RespCodeType = 2
 )
 
 // Enum value maps for EntityResponse_RespCodeType.
@@ -33,10 +34,12 @@ var (
 	EntityResponse_RespCodeType_name = map[int32]string{
 		0: "OK",
 		1: "FAILED",
+		2: "RATE_LIMITED",
 	}
 	EntityResponse_RespCodeType_value = map[string]int32{
-		"OK":     0,
-		"FAILED": 1,
+		"OK":           0,
+		"FAILED":       1,
+		"RATE_LIMITED": 2,
 	}
 )
 
@@ -828,15 +831,16 @@ const file_entity_entity_proto_rawDesc = "" +
 	"\x02id\x18\x02 \x01(\tR\x02id\x12\x18\n" +
 	"\asession\x18\x03 \x01(\x03R\asession\x12\x18\n" +
 	"\afunName\x18\x04 \x01(\tR\afunName\x12\x18\n" +
-	"\acontent\x18\x05 \x03(\fR\acontent\"\xad\x01\n" +
+	"\acontent\x18\x05 \x03(\fR\acontent\"\xbf\x01\n" +
 	"\x0eEntityResponse\x12C\n" +
 	"\brespCode\x18\x01 \x01(\x0e2'.kratos.api.EntityResponse.RespCodeTypeR\brespCode\x12\x18\n" +
 	"\asession\x18\x02 \x01(\x03R\asession\x12\x18\n" +
-	"\acontent\x18\x03 \x03(\fR\acontent\"\"\n" +
+	"\acontent\x18\x03 \x03(\fR\acontent\"4\n" +
 	"\fRespCodeType\x12\x06\n" +
 	"\x02OK\x10\x00\x12\n" +
 	"\n" +
-	"\x06FAILED\x10\x01\"k\n" +
+	"\x06FAILED\x10\x01\x12\x10\n" +
+	"\fRATE_LIMITED\x10\x02\"k\n" +
 	"\x16BroadcastEntityRequest\x12?\n" +
 	"\rentityRequest\x18\x01 \x01(\v2\x19.kratos.api.EntityRequestR\rentityRequest\x12\x10\n" +
 	"\x03ids\x18\x02 \x03(\tR\x03ids\"W\n" +
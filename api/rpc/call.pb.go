@@ -149,6 +149,9 @@ type StreamReq struct {
 	MsgContent    [][]byte               `protobuf:"bytes,3,rep,name=msgContent,proto3" json:"msgContent,omitempty"`
 	ReqId         string                 `protobuf:"bytes,4,opt,name=reqId,proto3" json:"reqId,omitempty"`
 	Traceinfo     *TraceInfo             `protobuf:"bytes,5,opt,name=traceinfo,proto3" json:"traceinfo,omitempty"`
+	OrderKey      string                 `protobuf:"bytes,6,opt,name=orderKey,proto3" json:"orderKey,omitempty"`
+	Header        map[string]string      `protobuf:"bytes,7,rep,name=header,proto3" json:"header,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Chunk         *Chunk                 `protobuf:"bytes,8,opt,name=chunk,proto3" json:"chunk,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -218,6 +221,27 @@ func (x *StreamReq) GetTraceinfo() *TraceInfo {
 	return nil
 }
 
+func (x *StreamReq) GetOrderKey() string {
+	if x != nil {
+		return x.OrderKey
+	}
+	return ""
+}
+
+func (x *StreamReq) GetHeader() map[string]string {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *StreamReq) GetChunk() *Chunk {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
 type JsonReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SrcService    string                 `protobuf:"bytes,1,opt,name=srcService,proto3" json:"srcService,omitempty"`
@@ -388,6 +412,8 @@ type StreamRsp struct {
 	MsgContent    [][]byte               `protobuf:"bytes,2,rep,name=msgContent,proto3" json:"msgContent,omitempty"`
 	ReqId         string                 `protobuf:"bytes,3,opt,name=reqId,proto3" json:"reqId,omitempty"`
 	Spnid         int64                  `protobuf:"varint,4,opt,name=spnid,proto3" json:"spnid,omitempty"`
+	Error         *RpcError              `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Chunk         *Chunk                 `protobuf:"bytes,6,opt,name=chunk,proto3" json:"chunk,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -450,6 +476,181 @@ func (x *StreamRsp) GetSpnid() int64 {
 	return 0
 }
 
+func (x *StreamRsp) GetError() *RpcError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *StreamRsp) GetChunk() *Chunk {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type RpcError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcError) Reset() {
+	*x = RpcError{}
+	mi := &file_rpc_call_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcError) ProtoMessage() {}
+
+func (x *RpcError) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_call_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcError.ProtoReflect.Descriptor instead.
+func (*RpcError) Descriptor() ([]byte, []int) {
+	return file_rpc_call_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RpcError) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *RpcError) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RpcError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RpcError) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// Chunk 大负载（RpcContentFile）分块：同一 reqId 的分块按 offset 连续传输
+type Chunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Eof           bool                   `protobuf:"varint,3,opt,name=eof,proto3" json:"eof,omitempty"`
+	Crc32         uint32                 `protobuf:"varint,4,opt,name=crc32,proto3" json:"crc32,omitempty"`
+	Ack           int64                  `protobuf:"varint,5,opt,name=ack,proto3" json:"ack,omitempty"`
+	Resume        bool                   `protobuf:"varint,6,opt,name=resume,proto3" json:"resume,omitempty"`
+	Cancel        bool                   `protobuf:"varint,7,opt,name=cancel,proto3" json:"cancel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chunk) Reset() {
+	*x = Chunk{}
+	mi := &file_rpc_call_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chunk) ProtoMessage() {}
+
+func (x *Chunk) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_call_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chunk.ProtoReflect.Descriptor instead.
+func (*Chunk) Descriptor() ([]byte, []int) {
+	return file_rpc_call_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Chunk) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *Chunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Chunk) GetEof() bool {
+	if x != nil {
+		return x.Eof
+	}
+	return false
+}
+
+func (x *Chunk) GetCrc32() uint32 {
+	if x != nil {
+		return x.Crc32
+	}
+	return 0
+}
+
+func (x *Chunk) GetAck() int64 {
+	if x != nil {
+		return x.Ack
+	}
+	return 0
+}
+
+func (x *Chunk) GetResume() bool {
+	if x != nil {
+		return x.Resume
+	}
+	return false
+}
+
+func (x *Chunk) GetCancel() bool {
+	if x != nil {
+		return x.Cancel
+	}
+	return false
+}
+
 var File_rpc_call_proto protoreflect.FileDescriptor
 
 const file_rpc_call_proto_rawDesc = "" +
@@ -466,7 +667,7 @@ const file_rpc_call_proto_rawDesc = "" +
 	"\tTraceInfo\x12\x18\n" +
 	"\atraceid\x18\x01 \x01(\x03R\atraceid\x12\x16\n" +
 	"\x06spanid\x18\x02 \x01(\x03R\x06spanid\x12\x16\n" +
-	"\x06sample\x18\x03 \x01(\bR\x06sample\"\xa5\x01\n" +
+	"\x06sample\x18\x03 \x01(\bR\x06sample\"\xd2\x02\n" +
 	"\tStreamReq\x12\x1e\n" +
 	"\n" +
 	"srcService\x18\x01 \x01(\tR\n" +
@@ -476,7 +677,14 @@ const file_rpc_call_proto_rawDesc = "" +
 	"msgContent\x18\x03 \x03(\fR\n" +
 	"msgContent\x12\x14\n" +
 	"\x05reqId\x18\x04 \x01(\tR\x05reqId\x12,\n" +
-	"\ttraceinfo\x18\x05 \x01(\v2\x0e.rpc.TraceInfoR\ttraceinfo\"_\n" +
+	"\ttraceinfo\x18\x05 \x01(\v2\x0e.rpc.TraceInfoR\ttraceinfo\x12\x1a\n" +
+	"\borderKey\x18\x06 \x01(\tR\borderKey\x122\n" +
+	"\x06header\x18\a \x03(\v2\x1a.rpc.StreamReq.HeaderEntryR\x06header\x12 \n" +
+	"\x05chunk\x18\b \x01(\v2\n" +
+	".rpc.ChunkR\x05chunk\x1a9\n" +
+	"\vHeaderEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"_\n" +
 	"\aJsonReq\x12\x1e\n" +
 	"\n" +
 	"srcService\x18\x01 \x01(\tR\n" +
@@ -494,14 +702,33 @@ const file_rpc_call_proto_rawDesc = "" +
 	"\x05msgId\x18\x01 \x01(\tR\x05msgId\x12\x1e\n" +
 	"\n" +
 	"msgContent\x18\x02 \x03(\fR\n" +
-	"msgContent\"m\n" +
+	"msgContent\"\xb4\x01\n" +
 	"\tStreamRsp\x12\x14\n" +
 	"\x05msgId\x18\x01 \x01(\tR\x05msgId\x12\x1e\n" +
 	"\n" +
 	"msgContent\x18\x02 \x03(\fR\n" +
 	"msgContent\x12\x14\n" +
 	"\x05reqId\x18\x03 \x01(\tR\x05reqId\x12\x14\n" +
-	"\x05spnid\x18\x04 \x01(\x03R\x05spnid2\xc3\x01\n" +
+	"\x05spnid\x18\x04 \x01(\x03R\x05spnid\x12#\n" +
+	"\x05error\x18\x05 \x01(\v2\r.rpc.RpcErrorR\x05error\x12 \n" +
+	"\x05chunk\x18\x06 \x01(\v2\n" +
+	".rpc.ChunkR\x05chunk\"\xc6\x01\n" +
+	"\bRpcError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x127\n" +
+	"\bmetadata\x18\x04 \x03(\v2\x1b.rpc.RpcError.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9d\x01\n" +
+	"\x05Chunk\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x03R\x06offset\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x10\n" +
+	"\x03eof\x18\x03 \x01(\bR\x03eof\x12\x14\n" +
+	"\x05crc32\x18\x04 \x01(\rR\x05crc32\x12\x10\n" +
+	"\x03ack\x18\x05 \x01(\x03R\x03ack\x12\x16\n" +
+	"\x06resume\x18\x06 \x01(\bR\x06resume\x12\x16\n" +
+	"\x06cancel\x18\a \x01(\bR\x06cancel2\xc3\x01\n" +
 	"\rRouterService\x12F\n" +
 	"\vJsonMessage\x12\f.rpc.JsonReq\x1a\f.rpc.JsonRsp\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/rpc/JsonMessage\x126\n" +
 	"\aRpcCall\x12\b.rpc.Req\x1a\b.rpc.Rsp\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/rpc/RpcCall\x122\n" +
@@ -521,7 +748,7 @@ func file_rpc_call_proto_rawDescGZIP() []byte {
 	return file_rpc_call_proto_rawDescData
 }
 
-var file_rpc_call_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_rpc_call_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_rpc_call_proto_goTypes = []any{
 	(*Req)(nil),       // 0: rpc.Req
 	(*TraceInfo)(nil), // 1: rpc.TraceInfo
@@ -530,20 +757,29 @@ var file_rpc_call_proto_goTypes = []any{
 	(*JsonRsp)(nil),   // 4: rpc.JsonRsp
 	(*Rsp)(nil),       // 5: rpc.Rsp
 	(*StreamRsp)(nil), // 6: rpc.StreamRsp
+	(*RpcError)(nil),  // 7: rpc.RpcError
+	(*Chunk)(nil),     // 8: rpc.Chunk
+	nil,               // 9: rpc.StreamReq.HeaderEntry
+	nil,               // 10: rpc.RpcError.MetadataEntry
 }
 var file_rpc_call_proto_depIdxs = []int32{
-	1, // 0: rpc.StreamReq.traceinfo:type_name -> rpc.TraceInfo
-	3, // 1: rpc.RouterService.JsonMessage:input_type -> rpc.JsonReq
-	0, // 2: rpc.RouterService.RpcCall:input_type -> rpc.Req
-	2, // 3: rpc.RouterService.StreamCall:input_type -> rpc.StreamReq
-	4, // 4: rpc.RouterService.JsonMessage:output_type -> rpc.JsonRsp
-	5, // 5: rpc.RouterService.RpcCall:output_type -> rpc.Rsp
-	6, // 6: rpc.RouterService.StreamCall:output_type -> rpc.StreamRsp
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1,  // 0: rpc.StreamReq.traceinfo:type_name -> rpc.TraceInfo
+	9,  // 1: rpc.StreamReq.header:type_name -> rpc.StreamReq.HeaderEntry
+	8,  // 2: rpc.StreamReq.chunk:type_name -> rpc.Chunk
+	7,  // 3: rpc.StreamRsp.error:type_name -> rpc.RpcError
+	8,  // 4: rpc.StreamRsp.chunk:type_name -> rpc.Chunk
+	10, // 5: rpc.RpcError.metadata:type_name -> rpc.RpcError.MetadataEntry
+	3,  // 6: rpc.RouterService.JsonMessage:input_type -> rpc.JsonReq
+	0,  // 7: rpc.RouterService.RpcCall:input_type -> rpc.Req
+	2,  // 8: rpc.RouterService.StreamCall:input_type -> rpc.StreamReq
+	4,  // 9: rpc.RouterService.JsonMessage:output_type -> rpc.JsonRsp
+	5,  // 10: rpc.RouterService.RpcCall:output_type -> rpc.Rsp
+	6,  // 11: rpc.RouterService.StreamCall:output_type -> rpc.StreamRsp
+	9,  // [9:12] is the sub-list for method output_type
+	6,  // [6:9] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_rpc_call_proto_init() }
@@ -557,7 +793,7 @@ func file_rpc_call_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_call_proto_rawDesc), len(file_rpc_call_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -14,6 +14,8 @@ var (
 	fun2abiMap = make(map[string]reflect.Type)
 	// ability reflect.Type(ptr) -> CallDispatcher（可长期复用）
 	abi2callMap = make(map[reflect.Type]*rpc.CallDispatcher)
+	// ability reflect.Type(ptr) -> Ability.Name()，用于定位实体上已挂载的能力实例
+	abi2nameMap = make(map[reflect.Type]string)
 )
 
 // RegisterType 通过 Ability 实现类型进行注册（建议传入指针类型，例如：reflect.TypeOf((*MyAbility)(nil)))
@@ -30,6 +32,9 @@ func RegisterType(abilityType reflect.Type) {
 	inst := reflect.New(ptrType.Elem()).Interface()
 	abi, _ := inst.(facade.Ability)
 	abi2callMap[ptrType] = rpc.NewCallDispatcher(abi)
+	if abi != nil {
+		abi2nameMap[ptrType] = abi.Name()
+	}
 
 	// 基础能力类型集合，用于过滤（避免将 Name/Attach/Detach 以及 BaseAbility 的方法注册为业务方法）
 	baseAbilityType := reflect.TypeOf((*base.BaseAbility)(nil)).Elem()
@@ -66,7 +71,8 @@ type CallAbleAbility struct {
 func (a *CallAbleAbility) Name() string { return "call" }
 
 // onCall 通过 funName 找到目标 Ability 类型，并使用已缓存的 dispatcher 分发
-func (a *CallAbleAbility) onCall(ctx context.Context, funName string, req rpc.RpcContent) (rpc.RpcContent, error) {
+// 若 owner 上挂载了同类型能力实例，则以该实例为接收者调用（dispatcher 仅提供编解码信息）
+func (a *CallAbleAbility) onCall(ctx context.Context, owner facade.Entity, funName string, req rpc.RpcContent) (rpc.RpcContent, error) {
 	abiType, ok := fun2abiMap[funName]
 	if !ok {
 		return nil, facade.ErrMethodNotFound
@@ -75,5 +81,14 @@ func (a *CallAbleAbility) onCall(ctx context.Context, funName string, req rpc.Rp
 	if disp == nil {
 		return nil, facade.ErrAbilityNotFound
 	}
+	if owner != nil {
+		if inst := owner.GetAbility(abi2nameMap[abiType]); inst != nil && reflect.TypeOf(inst) == abiType {
+			if fi := disp.RpcMethod[funName]; fi != nil && fi.Packer != nil {
+				bound := *fi
+				bound.Method = reflect.ValueOf(inst).MethodByName(funName)
+				return fi.Packer.Call(ctx, req, &bound)
+			}
+		}
+	}
 	return disp.Dispatch(ctx, funName, req)
 }
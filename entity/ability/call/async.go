@@ -0,0 +1,192 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/entity"
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/internal/promise"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 异步调用
+// - CallAsync/LocalCallAsync 立即返回 Promise，结果在目标实体执行器上完成
+// - 异步调用不阻塞调用方实体，因此不延续调用方的同步调用链（被调方回调调用方不会被视为成环）
+// - 在实体方法内等待 Promise 请使用 ContinueWith：方法先返回释放 mailbox，完成后续体再投递回本实体执行器
+//   注意：在实体方法内直接 Await 会重新阻塞 mailbox，且无法参与环检测
+
+const (
+	// continueRetryInterval 续体投递遇到队列溢出时的首次重试间隔，之后逐次翻倍
+	continueRetryInterval = time.Millisecond
+	// continueMaxRetries 续体投递遇到队列溢出时的最大重试次数
+	continueMaxRetries = 10
+)
+
+// CallAsync 异步版 Call：入队失败时返回已拒绝的 Promise
+func (c *CallSystemImpl) CallAsync(ctx context.Context, srcName string, funName string, req *entity.EntityRequest) *promise.Promise[[][]byte] {
+	p, resolve, reject := promise.Pending[[][]byte]()
+	if err := c.dispatch(detachChain(ctx), srcName, funName, req, func(ret [][]byte, err error) {
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(ret)
+	}); err != nil {
+		reject(err)
+	}
+	return p
+}
+
+// LocalCallAsync 本地异步调用：不经编解码，直接以 params 调用实体上已挂载能力的 funName 方法
+// 返回值为方法的非 error 返回值列表
+func (c *CallSystemImpl) LocalCallAsync(ctx context.Context, srcName string, entityType, id string, funName string, params []any) *promise.Promise[[]any] {
+	p, resolve, reject := promise.Pending[[]any]()
+	owner, _, err := c.prepare(ctx, entityType, id)
+	if err == nil {
+		err = c.admit(srcName, entityType, id, funName)
+	}
+	if err != nil {
+		reject(err)
+		return p
+	}
+	var out []any
+	if err := c.schedule(detachChain(ctx), entityType, id, funName, func(callCtx context.Context) error {
+		var err error
+		out, err = localInvoke(callCtx, owner, funName, params)
+		return err
+	}, func(err error) {
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(out)
+	}); err != nil {
+		reject(err)
+	}
+	return p
+}
+
+// ContinueWith 在实体方法内等待 f 而不阻塞当前实体的 mailbox
+// 当前实体取自 ctx 上的调用链末端；本函数立即返回，f 完成后 cont 被投递到当前实体的执行器上串行执行。
+// 续体的 ctx 不随原请求取消，调用链以当前实体为起点。
+// 若实体已移除，或队列持续溢出超过重试次数，cont 不在实体执行器上执行，而是在投递 goroutine 上以
+// facade.ErrNotFound / facade.ErrQueueOverflow 调用，此时 cont 不应访问实体状态。
+func ContinueWith[T any](ctx context.Context, c *CallSystemImpl, f *promise.Promise[T], cont func(ctx context.Context, val T, err error)) error {
+	chain := CallChainFromContext(ctx)
+	if len(chain) == 0 {
+		return errors.New("entity: ContinueWith must be called inside an entity method")
+	}
+	key := chain[len(chain)-1]
+	entityType, id := splitEntityKey(key)
+	contCtx := context.WithValue(context.WithoutCancel(ctx), callChainKey{}, []string{key})
+	run := func(val T, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("entity: panic in continuation of %s: %v", key, r)
+			}
+		}()
+		cont(contCtx, val, err)
+	}
+	go func() {
+		val, err := f.Await(context.Background())
+		task := func() { run(val, err) }
+		interval := continueRetryInterval
+		for attempt := 0; ; attempt++ {
+			act := c.lookupActor(entityType, id)
+			if act == nil {
+				run(val, facade.ErrNotFound)
+				return
+			}
+			qerr := act.Enqueue(contCtx, task)
+			switch {
+			case qerr == nil:
+				return
+			case errors.Is(qerr, facade.ErrQueueOverflow) && attempt < continueMaxRetries:
+				time.Sleep(interval)
+				interval *= 2
+			case errors.Is(qerr, facade.ErrQueueOverflow):
+				log.Errorf("entity: continuation of %s dropped: %v", key, qerr)
+				run(val, qerr)
+				return
+			default:
+				// 执行器已关闭：实体已移除
+				run(val, facade.ErrNotFound)
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// detachChain 清除 ctx 上的同步调用链
+func detachChain(ctx context.Context) context.Context {
+	if len(CallChainFromContext(ctx)) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, callChainKey{}, []string(nil))
+}
+
+func splitEntityKey(key string) (entityType, id string) {
+	for i := 0; i < len(key); i++ {
+		if key[i] == '/' {
+			return key[:i], key[i+1:]
+		}
+	}
+	return key, ""
+}
+
+var (
+	ctxType   = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// localInvoke 以反射直接调用实体上已挂载能力的方法（ctx 参数自动注入）
+func localInvoke(ctx context.Context, owner facade.Entity, funName string, params []any) ([]any, error) {
+	abiType, ok := fun2abiMap[funName]
+	if !ok {
+		return nil, facade.ErrMethodNotFound
+	}
+	inst := owner.GetAbility(abi2nameMap[abiType])
+	if inst == nil || reflect.TypeOf(inst) != abiType {
+		return nil, facade.ErrAbilityNotFound
+	}
+	method := reflect.ValueOf(inst).MethodByName(funName)
+	mt := method.Type()
+	args := make([]reflect.Value, 0, mt.NumIn())
+	if mt.NumIn() > 0 && mt.In(0) == ctxType {
+		args = append(args, reflect.ValueOf(ctx))
+	}
+	for _, p := range params {
+		if len(args) >= mt.NumIn() {
+			return nil, facade.ErrDecode
+		}
+		in := mt.In(len(args))
+		if p == nil {
+			args = append(args, reflect.Zero(in))
+			continue
+		}
+		v := reflect.ValueOf(p)
+		if !v.Type().AssignableTo(in) {
+			return nil, facade.ErrDecode
+		}
+		args = append(args, v)
+	}
+	if len(args) != mt.NumIn() {
+		return nil, facade.ErrDecode
+	}
+	results := method.Call(args)
+	out := make([]any, 0, len(results))
+	for _, r := range results {
+		if r.Type().Implements(errorType) {
+			if !r.IsNil() {
+				return nil, r.Interface().(error)
+			}
+			continue
+		}
+		out = append(out, r.Interface())
+	}
+	return out, nil
+}
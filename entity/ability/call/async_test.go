@@ -0,0 +1,185 @@
+package call
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/entity"
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/internal/promise"
+)
+
+type asyncReq struct {
+	Peer string `json:"peer"`
+}
+type asyncResp struct {
+	Value string `json:"value"`
+}
+
+type asyncState struct {
+	gate   chan struct{}
+	result chan string
+}
+
+// asyncAbility 用于验证异步调用与续体
+type asyncAbility struct {
+	owner facade.Entity
+	cs    *CallSystemImpl
+	st    *asyncState
+	got   string
+}
+
+func (a *asyncAbility) Name() string { return "async" }
+func (a *asyncAbility) Attach(ctx context.Context, owner facade.Entity) error {
+	a.owner = owner
+	owner.AddAbility(a)
+	return nil
+}
+func (a *asyncAbility) Detach(ctx context.Context) error { a.owner = nil; return nil }
+
+// AsyncSlow 阻塞直到 gate 关闭
+func (a *asyncAbility) AsyncSlow(ctx context.Context, req *asyncReq) (*asyncResp, error) {
+	<-a.st.gate
+	return &asyncResp{Value: "slow:" + a.owner.ID()}, nil
+}
+
+// AsyncAsk 异步调用 Peer 的 AsyncSlow，并以续体接收结果（不阻塞本实体 mailbox）
+func (a *asyncAbility) AsyncAsk(ctx context.Context, req *asyncReq) (*asyncResp, error) {
+	b, _ := json.Marshal(&asyncReq{})
+	f := a.cs.CallAsync(ctx, "src", "AsyncSlow", &entity.EntityRequest{Type: "user", Id: req.Peer, FunName: "AsyncSlow", Content: [][]byte{b}})
+	err := ContinueWith(ctx, a.cs, f, func(ctx context.Context, out [][]byte, err error) {
+		var resp asyncResp
+		if err == nil {
+			_ = json.Unmarshal(out[0], &resp)
+		}
+		chain := CallChainFromContext(ctx)
+		a.got = resp.Value
+		a.st.result <- a.got + "@" + chain[len(chain)-1]
+	})
+	return &asyncResp{Value: "pending"}, err
+}
+
+// AsyncPeek 返回已收到的结果
+func (a *asyncAbility) AsyncPeek(ctx context.Context, req *asyncReq) (*asyncResp, error) {
+	return &asyncResp{Value: a.got}, nil
+}
+
+func newAsyncSystem(t *testing.T) (*CallSystemImpl, *asyncState) {
+	t.Helper()
+	ctx := context.Background()
+	RegisterType(reflect.TypeOf((*asyncAbility)(nil)))
+	cs := &CallSystemImpl{}
+	st := &asyncState{gate: make(chan struct{}), result: make(chan string, 1)}
+	ents := map[string]facade.Entity{}
+	for _, id := range []string{"X", "Y"} {
+		e := &testEntity{id: id, typeName: "user"}
+		if err := (&asyncAbility{cs: cs, st: st}).Attach(ctx, e); err != nil {
+			t.Fatalf("attach: %v", err)
+		}
+		ents[id] = e
+	}
+	cs.Init(ctx, &fakeMgr{ents: map[string]map[string]facade.Entity{"user": ents}})
+	return cs, st
+}
+
+func asyncCall(cs *CallSystemImpl, ctx context.Context, id, fun, peer string) (string, error) {
+	b, _ := json.Marshal(&asyncReq{Peer: peer})
+	out, err := cs.Call(ctx, "src", fun, &entity.EntityRequest{Type: "user", Id: id, FunName: fun, Content: [][]byte{b}})
+	if err != nil {
+		return "", err
+	}
+	var resp asyncResp
+	_ = json.Unmarshal(out[0], &resp)
+	return resp.Value, nil
+}
+
+func TestCallSystem_CallAsync_ThenAndTimeout(t *testing.T) {
+	cs, st := newAsyncSystem(t)
+	ctx := context.Background()
+	b, _ := json.Marshal(&asyncReq{})
+	f := cs.CallAsync(ctx, "src", "AsyncSlow", &entity.EntityRequest{Type: "user", Id: "Y", FunName: "AsyncSlow", Content: [][]byte{b}})
+	if _, err := promise.Timeout(f, 20*time.Millisecond).Await(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want timeout while callee blocked, got %v", err)
+	}
+	close(st.gate)
+	v, err := promise.Then(f, func(out [][]byte) *promise.Promise[string] {
+		var resp asyncResp
+		_ = json.Unmarshal(out[0], &resp)
+		return promise.Resolve(resp.Value)
+	}).Await(ctx)
+	if err != nil || v != "slow:Y" {
+		t.Fatalf("want slow:Y, got %q %v", v, err)
+	}
+}
+
+func TestCallSystem_LocalCallAsync(t *testing.T) {
+	cs, _ := newAsyncSystem(t)
+	ctx := context.Background()
+	out, err := cs.LocalCallAsync(ctx, "src", "user", "X", "AsyncPeek", []any{&asyncReq{}}).Await(ctx)
+	if err != nil {
+		t.Fatalf("local call err: %v", err)
+	}
+	if resp, ok := out[0].(*asyncResp); !ok || resp.Value != "" {
+		t.Fatalf("unexpected result: %#v", out)
+	}
+	if _, err := cs.LocalCallAsync(ctx, "src", "user", "X", "AsyncPeek", []any{"bad"}).Await(ctx); !errors.Is(err, facade.ErrDecode) {
+		t.Fatalf("want ErrDecode for bad params, got %v", err)
+	}
+}
+
+func TestCallSystem_ContinueWith_DoesNotBlockMailbox(t *testing.T) {
+	cs, st := newAsyncSystem(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if v, err := asyncCall(cs, ctx, "X", "AsyncAsk", "Y"); err != nil || v != "pending" {
+		t.Fatalf("ask: %q %v", v, err)
+	}
+	// Y 仍阻塞，但 X 的 mailbox 已释放
+	if v, err := asyncCall(cs, ctx, "X", "AsyncPeek", ""); err != nil || v != "" {
+		t.Fatalf("peek while pending: %q %v", v, err)
+	}
+	close(st.gate)
+	select {
+	case got := <-st.result:
+		if got != "slow:Y@user/X" {
+			t.Fatalf("continuation result=%q", got)
+		}
+	case <-ctx.Done():
+		t.Fatalf("continuation not executed")
+	}
+	if v, err := asyncCall(cs, ctx, "X", "AsyncPeek", ""); err != nil || v != "slow:Y" {
+		t.Fatalf("peek after continuation: %q %v", v, err)
+	}
+}
+
+func TestCallSystem_ContinueWith_EntityRemoved(t *testing.T) {
+	cs, _ := newAsyncSystem(t)
+	ctx := context.Background()
+	if _, err := asyncCall(cs, ctx, "X", "AsyncPeek", ""); err != nil {
+		t.Fatalf("peek: %v", err)
+	}
+	f, resolve, _ := promise.Pending[int]()
+	got := make(chan error, 1)
+	methodCtx := context.WithValue(ctx, callChainKey{}, []string{"user/X"})
+	if err := ContinueWith(methodCtx, cs, f, func(ctx context.Context, _ int, err error) { got <- err }); err != nil {
+		t.Fatalf("continue: %v", err)
+	}
+	// 实体在续体投递前被移除：续体收到 ErrNotFound，且不会为其重建执行器
+	cs.CloseActor("user", "X")
+	resolve(1)
+	select {
+	case err := <-got:
+		if !errors.Is(err, facade.ErrNotFound) {
+			t.Fatalf("want ErrNotFound, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("continuation not invoked")
+	}
+	if cs.lookupActor("user", "X") != nil {
+		t.Fatalf("executor re-created for removed entity")
+	}
+}
@@ -0,0 +1,99 @@
+package call
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// ReentrancyPolicy 同步调用链成环时的处理策略（按实体类型配置）
+type ReentrancyPolicy int
+
+const (
+	// ReentrancyReject 检测到环立即返回 ErrCallCycle（默认）
+	ReentrancyReject ReentrancyPolicy = iota
+	// ReentrancyAllow 允许重入：在当前调用链上直接执行，不再经过目标实体的执行器排队
+	// 说明：链上目标实体的执行器正阻塞等待本调用链返回，因此直接执行不会与其并发。
+	ReentrancyAllow
+)
+
+// defaultMaxCallDepth 默认最大同步调用深度
+const defaultMaxCallDepth = 16
+
+type callChainKey struct{}
+
+// CallChainFromContext 返回 ctx 上携带的同步调用链（实体键 type/id，按调用先后排列）
+func CallChainFromContext(ctx context.Context) []string {
+	chain, _ := ctx.Value(callChainKey{}).([]string)
+	return chain
+}
+
+// withCallee 返回追加了被调实体键的新 ctx（调用链不可变，追加时复制）
+func withCallee(ctx context.Context, key string) context.Context {
+	chain := CallChainFromContext(ctx)
+	next := make([]string, len(chain), len(chain)+1)
+	copy(next, chain)
+	return context.WithValue(ctx, callChainKey{}, append(next, key))
+}
+
+// checkChain 在入队前检查调用链：返回是否重入执行；超深或拒绝重入时返回错误
+func (c *CallSystemImpl) checkChain(ctx context.Context, entityType, key string) (reentrant bool, err error) {
+	chain := CallChainFromContext(ctx)
+	c.mu.Lock()
+	maxDepth := c.maxCallDepth
+	policy := c.reentrancy[entityType]
+	c.mu.Unlock()
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxCallDepth
+	}
+	if len(chain) >= maxDepth {
+		return false, fmt.Errorf("%w: depth=%d chain=%s", facade.ErrCallDepthExceeded, len(chain), strings.Join(chain, " -> "))
+	}
+	for _, k := range chain {
+		if k != key {
+			if c.sameGroup(k, key) {
+				// 同组成员共享执行器，排队会等待自身：直接在当前调用链上执行
+				return true, nil
+			}
+			continue
+		}
+		if policy == ReentrancyAllow {
+			return true, nil
+		}
+		return false, fmt.Errorf("%w: %s -> %s", facade.ErrCallCycle, strings.Join(chain, " -> "), key)
+	}
+	return false, nil
+}
+
+// sameGroup 两个实体键是否为同一 id 下同组的不同成员
+func (c *CallSystemImpl) sameGroup(a, b string) bool {
+	if c.groups == nil {
+		return false
+	}
+	at, aid := splitEntityKey(a)
+	bt, bid := splitEntityKey(b)
+	if aid != bid || at == bt {
+		return false
+	}
+	g := c.groups.GroupOf(at)
+	return g != "" && g == c.groups.GroupOf(bt)
+}
+
+// SetReentrancyPolicy 设置某实体类型的重入策略
+func (c *CallSystemImpl) SetReentrancyPolicy(entityType string, policy ReentrancyPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reentrancy == nil {
+		c.reentrancy = make(map[string]ReentrancyPolicy)
+	}
+	c.reentrancy[entityType] = policy
+}
+
+// SetMaxCallDepth 设置最大同步调用深度（<=0 使用默认值）
+func (c *CallSystemImpl) SetMaxCallDepth(depth int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxCallDepth = depth
+}
@@ -0,0 +1,131 @@
+package call
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/entity"
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+type cycleReq struct {
+	Target string `json:"target"`
+	Back   string `json:"back"`
+}
+type cycleResp struct {
+	Depth int `json:"depth"`
+}
+
+// cycleAbility 在方法内同步调用其他实体，用于构造调用链
+type cycleAbility struct {
+	owner facade.Entity
+	cs    *CallSystemImpl
+}
+
+func (a *cycleAbility) Name() string { return "cycle" }
+func (a *cycleAbility) Attach(ctx context.Context, owner facade.Entity) error {
+	a.owner = owner
+	owner.AddAbility(a)
+	return nil
+}
+func (a *cycleAbility) Detach(ctx context.Context) error { a.owner = nil; return nil }
+
+// CycleForward 调用 Target 实体的 CycleBack，并让其回调 Back 实体
+func (a *cycleAbility) CycleForward(ctx context.Context, req *cycleReq) (*cycleResp, error) {
+	b, _ := json.Marshal(&cycleReq{Target: req.Back})
+	out, err := a.cs.Call(ctx, "src", "CycleBack", &entity.EntityRequest{Type: "user", Id: req.Target, FunName: "CycleBack", Content: [][]byte{b}})
+	if err != nil {
+		return nil, err
+	}
+	var resp cycleResp
+	_ = json.Unmarshal(out[0], &resp)
+	return &resp, nil
+}
+
+// CycleBack 回调 Target 实体的 CycleLeaf
+func (a *cycleAbility) CycleBack(ctx context.Context, req *cycleReq) (*cycleResp, error) {
+	b, _ := json.Marshal(&cycleReq{})
+	out, err := a.cs.Call(ctx, "src", "CycleLeaf", &entity.EntityRequest{Type: "user", Id: req.Target, FunName: "CycleLeaf", Content: [][]byte{b}})
+	if err != nil {
+		return nil, err
+	}
+	var resp cycleResp
+	_ = json.Unmarshal(out[0], &resp)
+	return &resp, nil
+}
+
+// CycleLeaf 返回当前调用链深度
+func (a *cycleAbility) CycleLeaf(ctx context.Context, req *cycleReq) (*cycleResp, error) {
+	return &cycleResp{Depth: len(CallChainFromContext(ctx))}, nil
+}
+
+func newCycleSystem(t *testing.T) *CallSystemImpl {
+	t.Helper()
+	ctx := context.Background()
+	RegisterType(reflect.TypeOf((*cycleAbility)(nil)))
+	cs := &CallSystemImpl{}
+	ents := map[string]facade.Entity{}
+	for _, id := range []string{"A", "B"} {
+		e := &testEntity{id: id, typeName: "user"}
+		if err := (&cycleAbility{cs: cs}).Attach(ctx, e); err != nil {
+			t.Fatalf("attach: %v", err)
+		}
+		ents[id] = e
+	}
+	cs.Init(ctx, &fakeMgr{ents: map[string]map[string]facade.Entity{"user": ents}})
+	return cs
+}
+
+func callForward(cs *CallSystemImpl, ctx context.Context, from, target, back string) (*cycleResp, error) {
+	b, _ := json.Marshal(&cycleReq{Target: target, Back: back})
+	out, err := cs.Call(ctx, "src", "CycleForward", &entity.EntityRequest{Type: "user", Id: from, FunName: "CycleForward", Content: [][]byte{b}})
+	if err != nil {
+		return nil, err
+	}
+	var resp cycleResp
+	if err := json.Unmarshal(out[0], &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func TestCallSystem_CycleRejected(t *testing.T) {
+	cs := newCycleSystem(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// A -> B -> A：默认策略应立即失败而不是等到超时
+	_, err := callForward(cs, ctx, "A", "B", "A")
+	if !errors.Is(err, facade.ErrCallCycle) {
+		t.Fatalf("want ErrCallCycle, got %v", err)
+	}
+}
+
+func TestCallSystem_CycleReentrant(t *testing.T) {
+	cs := newCycleSystem(t)
+	cs.SetReentrancyPolicy("user", ReentrancyAllow)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := callForward(cs, ctx, "A", "B", "A")
+	if err != nil {
+		t.Fatalf("reentrant call err: %v", err)
+	}
+	if resp.Depth != 3 {
+		t.Fatalf("chain depth=%d want 3", resp.Depth)
+	}
+}
+
+func TestCallSystem_MaxCallDepth(t *testing.T) {
+	cs := newCycleSystem(t)
+	cs.SetMaxCallDepth(2)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// A -> B -> B(leaf) 需要深度 3
+	_, err := callForward(cs, ctx, "A", "B", "B")
+	if !errors.Is(err, facade.ErrCallDepthExceeded) {
+		t.Fatalf("want ErrCallDepthExceeded, got %v", err)
+	}
+}
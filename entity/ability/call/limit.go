@@ -0,0 +1,207 @@
+package call
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/aegis/ratelimit"
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// maxSourceBuckets 单个实体保留的调用方令牌桶数量上限；超出时回收已回满的桶
+const maxSourceBuckets = 1024
+
+// RateLimit 实体调用限流配置（令牌桶；Rate 为每秒补充的令牌数，0 表示不限）
+type RateLimit struct {
+	EntityRate  float64 // 每个实体
+	EntityBurst int     // <=0 取 max(1, EntityRate)
+	SourceRate  float64 // 每个 (调用方, 实体)
+	SourceBurst int     // <=0 取 max(1, SourceRate)
+	// MethodCost 方法名 -> 每次调用消耗的令牌数（未配置为 1）
+	MethodCost map[string]float64
+}
+
+func (r RateLimit) enabled() bool { return r.EntityRate > 0 || r.SourceRate > 0 }
+
+func (r RateLimit) costOf(funName string) float64 {
+	if c, ok := r.MethodCost[funName]; ok && c > 0 {
+		return c
+	}
+	return 1
+}
+
+// validate 方法消耗不得超过已启用令牌桶的容量，否则该方法永远无法通过
+func (r RateLimit) validate() error {
+	for name, c := range r.MethodCost {
+		if r.EntityRate > 0 && c > burstOf(r.EntityRate, r.EntityBurst) {
+			return fmt.Errorf("%w: method %s cost %v exceeds entity burst %v", facade.ErrInvalidConfig, name, c, burstOf(r.EntityRate, r.EntityBurst))
+		}
+		if r.SourceRate > 0 && c > burstOf(r.SourceRate, r.SourceBurst) {
+			return fmt.Errorf("%w: method %s cost %v exceeds source burst %v", facade.ErrInvalidConfig, name, c, burstOf(r.SourceRate, r.SourceBurst))
+		}
+	}
+	return nil
+}
+
+func burstOf(rate float64, burst int) float64 {
+	if burst > 0 {
+		return float64(burst)
+	}
+	return math.Max(1, rate)
+}
+
+// TokenBucket 令牌桶（按方法消耗不同数量的令牌，拒绝时可归还）
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket 创建令牌桶：每秒补充 rate 个令牌，容量 burst（初始为满）
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	b := burstOf(rate, burst)
+	return &TokenBucket{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+// AllowN 消耗 n 个令牌；不足时不消耗并返回 false
+func (b *TokenBucket) AllowN(now time.Time, n float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked(now)
+	if b.tokens < n {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
+
+// refund 归还令牌（组合限流中后续层拒绝时）
+func (b *TokenBucket) refund(n float64) {
+	b.mu.Lock()
+	b.tokens = math.Min(b.burst, b.tokens+n)
+	b.mu.Unlock()
+}
+
+// full 是否已回满（空闲可回收）
+func (b *TokenBucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked(now)
+	return b.tokens >= b.burst
+}
+
+func (b *TokenBucket) refillLocked(now time.Time) {
+	if d := now.Sub(b.last).Seconds(); d > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+d*b.rate)
+		b.last = now
+	}
+}
+
+// entityLimiter 单个实体的令牌桶：实体整体 + 按调用方
+type entityLimiter struct {
+	mu      sync.Mutex
+	entity  *TokenBucket
+	sources map[string]*TokenBucket
+}
+
+func (l *entityLimiter) source(src string, cfg RateLimit, now time.Time) *TokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.sources[src]
+	if b != nil {
+		return b
+	}
+	if len(l.sources) >= maxSourceBuckets {
+		for s, sb := range l.sources {
+			if sb.full(now) {
+				delete(l.sources, s)
+			}
+		}
+	}
+	b = NewTokenBucket(cfg.SourceRate, cfg.SourceBurst)
+	l.sources[src] = b
+	return b
+}
+
+// SetRateLimit 设置默认限流配置（仅对之后首次被调用的实体生效）
+// 方法消耗超过令牌桶容量时返回 ErrInvalidConfig，配置不变。
+func (c *CallSystemImpl) SetRateLimit(cfg RateLimit) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimit = cfg
+	return nil
+}
+
+// SetTypeRateLimit 整体替换按类型覆盖的限流配置（仅对之后首次被调用的实体生效）
+// 任一类型的配置无效时返回 ErrInvalidConfig，配置不变。
+func (c *CallSystemImpl) SetTypeRateLimit(limits map[string]RateLimit) error {
+	for t, cfg := range limits {
+		if err := cfg.validate(); err != nil {
+			return fmt.Errorf("type %s: %w", t, err)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.typeRateLimit = make(map[string]RateLimit, len(limits))
+	for t, cfg := range limits {
+		c.typeRateLimit[t] = cfg
+	}
+	return nil
+}
+
+// admit 入队前按 (调用方, 实体) 与实体两级令牌桶限流；拒绝时返回 ErrRateLimited
+func (c *CallSystemImpl) admit(src, t, id, funName string) error {
+	c.mu.Lock()
+	cfg, ok := c.typeRateLimit[t]
+	if !ok {
+		cfg = c.rateLimit
+	}
+	if !cfg.enabled() {
+		c.mu.Unlock()
+		return nil
+	}
+	key := t + "/" + id
+	l := c.limiters[key]
+	if l == nil {
+		l = &entityLimiter{sources: make(map[string]*TokenBucket)}
+		if cfg.EntityRate > 0 {
+			l.entity = NewTokenBucket(cfg.EntityRate, cfg.EntityBurst)
+		}
+		if c.limiters == nil {
+			c.limiters = make(map[string]*entityLimiter)
+		}
+		c.limiters[key] = l
+	}
+	c.mu.Unlock()
+
+	now := time.Now()
+	cost := cfg.costOf(funName)
+	var sb *TokenBucket
+	if cfg.SourceRate > 0 {
+		sb = l.source(src, cfg, now)
+		if !sb.AllowN(now, cost) {
+			return fmt.Errorf("%w: source %s on %s.%s (%w)", facade.ErrRateLimited, src, key, funName, ratelimit.ErrLimitExceed)
+		}
+	}
+	if l.entity != nil && !l.entity.AllowN(now, cost) {
+		if sb != nil {
+			sb.refund(cost)
+		}
+		return fmt.Errorf("%w: %s.%s (%w)", facade.ErrRateLimited, key, funName, ratelimit.ErrLimitExceed)
+	}
+	return nil
+}
+
+// dropLimiter 实体移出内存时释放其令牌桶
+func (c *CallSystemImpl) dropLimiter(t, id string) {
+	c.mu.Lock()
+	delete(c.limiters, t+"/"+id)
+	c.mu.Unlock()
+}
@@ -0,0 +1,112 @@
+package call
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/aegis/ratelimit"
+	"github.com/go-kratos/kratos/v2/api/entity"
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := NewTokenBucket(10, 2)
+	now := time.Now()
+	if !b.AllowN(now, 1) || !b.AllowN(now, 1) || b.AllowN(now, 1) {
+		t.Fatalf("burst of 2 not enforced")
+	}
+	// 100ms 补充 1 个令牌
+	if !b.AllowN(now.Add(100*time.Millisecond), 1) {
+		t.Fatalf("token not refilled")
+	}
+	if b.AllowN(now.Add(100*time.Millisecond), 2) {
+		t.Fatalf("cost 2 allowed with empty bucket")
+	}
+}
+
+func TestCallSystem_RateLimit(t *testing.T) {
+	ctx := context.Background()
+	e := &testEntity{id: "R1", typeName: "user"}
+	if err := (&adderAbility{}).Attach(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+	RegisterType(reflect.TypeOf((*adderAbility)(nil)))
+	cs := &CallSystemImpl{}
+	cs.SetRateLimit(RateLimit{
+		EntityRate: 0.001, EntityBurst: 4,
+		SourceRate: 0.001, SourceBurst: 2,
+	})
+	cs.SetTypeRateLimit(map[string]RateLimit{"guild": {}})
+	cs.Init(ctx, &fakeMgr{ents: map[string]map[string]facade.Entity{"user": {"R1": e}}})
+
+	b, _ := json.Marshal(&addReq{A: 1, B: 2})
+	call := func(src string) error {
+		_, err := cs.Call(ctx, src, "Add", &entity.EntityRequest{Type: "user", Id: "R1", FunName: "Add", Content: [][]byte{b}})
+		return err
+	}
+	// 调用方 a 用完自己的配额，不影响调用方 b
+	if err := call("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := call("a"); err != nil {
+		t.Fatal(err)
+	}
+	err := call("a")
+	if !errors.Is(err, facade.ErrRateLimited) || !errors.Is(err, ratelimit.ErrLimitExceed) {
+		t.Fatalf("source limit err=%v", err)
+	}
+	if err := call("b"); err != nil {
+		t.Fatalf("other source limited: %v", err)
+	}
+	// 实体整体配额（4）耗尽：任何调用方都被拒绝
+	if err := call("c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := call("d"); !errors.Is(err, facade.ErrRateLimited) {
+		t.Fatalf("entity limit err=%v", err)
+	}
+}
+
+func TestCallSystem_RateLimitMethodCost(t *testing.T) {
+	ctx := context.Background()
+	e := &testEntity{id: "R2", typeName: "user"}
+	if err := (&adderAbility{}).Attach(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+	RegisterType(reflect.TypeOf((*adderAbility)(nil)))
+	cs := &CallSystemImpl{}
+	cs.SetRateLimit(RateLimit{EntityRate: 0.001, EntityBurst: 5, MethodCost: map[string]float64{"Add": 3}})
+	cs.Init(ctx, &fakeMgr{ents: map[string]map[string]facade.Entity{"user": {"R2": e}}})
+
+	b, _ := json.Marshal(&addReq{A: 1, B: 2})
+	req := &entity.EntityRequest{Type: "user", Id: "R2", FunName: "Add", Content: [][]byte{b}}
+	if _, err := cs.Call(ctx, "src", "Add", req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.Call(ctx, "src", "Add", req); !errors.Is(err, facade.ErrRateLimited) {
+		t.Fatalf("weighted call err=%v", err)
+	}
+}
+
+func TestCallSystem_RateLimitCostExceedsBurst(t *testing.T) {
+	cs := &CallSystemImpl{}
+	if err := cs.SetRateLimit(RateLimit{EntityRate: 1, EntityBurst: 2, MethodCost: map[string]float64{"Add": 3}}); !errors.Is(err, facade.ErrInvalidConfig) {
+		t.Fatalf("entity burst err=%v", err)
+	}
+	// 未显式配置 burst 时取 max(1, rate)
+	if err := cs.SetRateLimit(RateLimit{SourceRate: 2, MethodCost: map[string]float64{"Add": 3}}); !errors.Is(err, facade.ErrInvalidConfig) {
+		t.Fatalf("source burst err=%v", err)
+	}
+	err := cs.SetTypeRateLimit(map[string]RateLimit{"guild": {EntityRate: 1, MethodCost: map[string]float64{"Add": 2}}})
+	if !errors.Is(err, facade.ErrInvalidConfig) || cs.typeRateLimit != nil {
+		t.Fatalf("type limit err=%v applied=%v", err, cs.typeRateLimit)
+	}
+	// 未启用的令牌桶不限制消耗
+	if err := cs.SetRateLimit(RateLimit{EntityRate: 1, EntityBurst: 3, MethodCost: map[string]float64{"Add": 3}}); err != nil {
+		t.Fatal(err)
+	}
+}
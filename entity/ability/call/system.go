@@ -2,6 +2,7 @@ package call
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/go-kratos/kratos/v2/api/entity"
@@ -18,24 +19,45 @@ type CallSystemImpl struct {
 	entityMgr facade.EntityMgr
 	// type -> id -> CallAbleAbility
 	t2Id2Call map[string]map[string]*CallAbleAbility
-	// type -> id -> per-entity actor
-	t2Id2Actor map[string]map[string]*base.Actor
+	// type -> id -> per-entity executor（Actor 或池化 Mailbox）
+	t2Id2Actor map[string]map[string]base.Executor
 	mu         sync.Mutex
 	queueSize  int
+	scheduler  base.Scheduler
+	// 按类型覆盖的队列容量（未设置的类型使用 queueSize）
+	typeQueueSize map[string]int
+
+	// 同步调用链：按类型的重入策略与最大深度
+	reentrancy   map[string]ReentrancyPolicy
+	maxCallDepth int
+
+	// 限流：默认与按类型覆盖的配置；type/id -> 实体令牌桶
+	rateLimit     RateLimit
+	typeRateLimit map[string]RateLimit
+	limiters      map[string]*entityLimiter
+
+	// 实体组：组内成员共享同一执行器（EntityMgr 实现 facade.GroupResolver 时生效）
+	groups facade.GroupResolver
 }
 
 // Init 将 CallAbleAbility 挂载流程注册到 EntityMgr，并保存引用
 func (c *CallSystemImpl) Init(ctx context.Context, eMgr facade.EntityMgr) {
 	c.entityMgr = eMgr
+	if r, ok := eMgr.(facade.GroupResolver); ok {
+		c.groups = r
+	}
 	if c.t2Id2Call == nil {
 		c.t2Id2Call = make(map[string]map[string]*CallAbleAbility)
 	}
 	if c.t2Id2Actor == nil {
-		c.t2Id2Actor = make(map[string]map[string]*base.Actor)
+		c.t2Id2Actor = make(map[string]map[string]base.Executor)
 	}
 	if c.queueSize <= 0 {
 		c.queueSize = 8
 	}
+	if c.scheduler == nil {
+		c.scheduler = base.ActorScheduler{}
+	}
 	// 按能力注册添加流程：为每个实体自动挂载 call 能力
 	eMgr.RegisterAddProcess("call", func(ctx context.Context, owner facade.Entity) {
 		abi := &CallAbleAbility{}
@@ -51,13 +73,17 @@ func (c *CallSystemImpl) Init(ctx context.Context, eMgr facade.EntityMgr) {
 		mp[id] = abi
 		c.mu.Unlock()
 	})
-	// 在移除时关闭并清理对应 actor
+	// 在移除时关闭并清理对应 actor；组共享的执行器在最后一个成员移出时关闭
 	eMgr.RegisterRemoveProcess("call", func(ctx context.Context, owner facade.Entity) {
+		c.dropLimiter(owner.Type(), owner.ID())
+		if c.groupLoaded(ctx, owner.Type(), owner.ID()) {
+			return
+		}
 		c.CloseActor(owner.Type(), owner.ID())
 	})
 }
 
-// SetQueueSize 在 Init 之前设置默认队列大小
+// SetQueueSize 设置默认队列大小（仅对之后创建的执行器生效）
 func (c *CallSystemImpl) SetQueueSize(size int) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -66,27 +92,109 @@ func (c *CallSystemImpl) SetQueueSize(size int) {
 	}
 }
 
-func (c *CallSystemImpl) getActor(t, id string) *base.Actor {
+// SetTypeQueueSize 整体替换按类型覆盖的队列大小（仅对之后创建的执行器生效）
+func (c *CallSystemImpl) SetTypeQueueSize(sizes map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.typeQueueSize = make(map[string]int, len(sizes))
+	for t, size := range sizes {
+		if size > 0 {
+			c.typeQueueSize[t] = size
+		}
+	}
+}
+
+// ApplyConfig 应用 facade.Config 中的队列配置；已存在的执行器保持原容量，
+// 实体下次被加载时按新配置创建。
+func (c *CallSystemImpl) ApplyConfig(cfg facade.Config) {
+	sizes := make(map[string]int, len(cfg.Types))
+	for _, t := range cfg.TypeNames() {
+		sizes[t] = cfg.QueueSizeFor(t)
+	}
+	c.SetQueueSize(cfg.QueueSize)
+	c.SetTypeQueueSize(sizes)
+}
+
+// SetScheduler 在 Init 之前设置实体执行器的调度方式
+// 默认 base.ActorScheduler（每实体一个 goroutine）；海量空闲实体可使用 base.NewPoolScheduler。
+func (c *CallSystemImpl) SetScheduler(s base.Scheduler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s != nil {
+		c.scheduler = s
+	}
+}
+
+// actorScope 返回执行器的归属：组成员为 "#组名"（同一 id 的成员共享执行器），否则为类型本身
+func (c *CallSystemImpl) actorScope(t string) string {
+	if c.groups != nil {
+		if g := c.groups.GroupOf(t); g != "" {
+			return "#" + g
+		}
+	}
+	return t
+}
+
+// groupLoaded 同组的其他成员是否仍在内存中
+func (c *CallSystemImpl) groupLoaded(ctx context.Context, t, id string) bool {
+	if c.groups == nil {
+		return false
+	}
+	g := c.groups.GroupOf(t)
+	if g == "" {
+		return false
+	}
+	for _, mt := range c.groups.GroupMembers(g) {
+		if mt == t {
+			continue
+		}
+		if ok, _ := c.entityMgr.Exists(ctx, mt, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CallSystemImpl) getActor(t, id string) base.Executor {
+	scope := c.actorScope(t)
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	mp, ok := c.t2Id2Actor[t]
+	mp, ok := c.t2Id2Actor[scope]
 	if !ok {
-		mp = make(map[string]*base.Actor)
-		c.t2Id2Actor[t] = mp
+		mp = make(map[string]base.Executor)
+		c.t2Id2Actor[scope] = mp
 	}
 	act := mp[id]
 	if act == nil {
-		act = base.NewActor(c.queueSize)
+		size := c.queueSize
+		if n, ok := c.typeQueueSize[t]; ok {
+			size = n
+		}
+		act = c.scheduler.NewExecutor(size)
 		mp[id] = act
 	}
 	return act
 }
 
-// CloseActor 关闭并移除某个实体的 actor（若存在）
+// lookupActor 返回实体已有的执行器，不存在（实体未加载或已移除）时返回 nil
+func (c *CallSystemImpl) lookupActor(t, id string) base.Executor {
+	scope := c.actorScope(t)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t2Id2Actor[scope][id]
+}
+
+// RunInActor 在实体执行器上执行 fn，与该实体（及同组成员）的业务调用串行（可作为 base.ActorRunner）
+func (c *CallSystemImpl) RunInActor(ctx context.Context, t, id string, fn func()) error {
+	return c.getActor(t, id).Enqueue(ctx, fn)
+}
+
+// CloseActor 关闭并移除某个实体的 actor（若存在）；组成员关闭的是整组共享的执行器
 func (c *CallSystemImpl) CloseActor(t, id string) {
+	scope := c.actorScope(t)
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if mp, ok := c.t2Id2Actor[t]; ok {
+	if mp, ok := c.t2Id2Actor[scope]; ok {
 		if act, ok2 := mp[id]; ok2 {
 			act.Close()
 			delete(mp, id)
@@ -108,9 +216,10 @@ func (c *CallSystemImpl) CloseAll() {
 
 // QueueLen 返回指定实体的队列长度（近似）
 func (c *CallSystemImpl) QueueLen(t, id string) int {
+	scope := c.actorScope(t)
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if mp, ok := c.t2Id2Actor[t]; ok {
+	if mp, ok := c.t2Id2Actor[scope]; ok {
 		if act, ok2 := mp[id]; ok2 {
 			return act.QueueLen()
 		}
@@ -119,13 +228,78 @@ func (c *CallSystemImpl) QueueLen(t, id string) int {
 }
 
 func (c *CallSystemImpl) Call(ctx context.Context, srcName string, funName string, req *entity.EntityRequest) ([][]byte, error) {
+	done := make(chan struct{})
+	var out [][]byte
+	var callErr error
+	if err := c.dispatch(ctx, srcName, funName, req, func(ret [][]byte, err error) {
+		out, callErr = ret, err
+		close(done)
+	}); err != nil {
+		return nil, err
+	}
+	select {
+	case <-done:
+		// ok
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return out, callErr
+}
+
+// dispatch 编解码并在目标实体执行器上执行 funName，完成后回调 onDone（在执行器上调用）
+// 返回的 error 表示未能入队（实体不存在、方法不存在、调用链拒绝、队列溢出等）
+func (c *CallSystemImpl) dispatch(ctx context.Context, srcName, funName string, req *entity.EntityRequest, onDone func([][]byte, error)) error {
 	t := req.Type
 	id := req.Id
 
-	// 确保实体已加载（并触发 AddProcess）
+	owner, callAbi, err := c.prepare(ctx, t, id)
+	if err != nil {
+		return err
+	}
+
+	// 根据 funName 的分发表选择 packer，再构造对应的 RpcContent
+	abiType, ok := fun2abiMap[funName]
+	if !ok {
+		return facade.ErrMethodNotFound
+	}
+	disp := abi2callMap[abiType]
+	if disp == nil {
+		return facade.ErrAbilityNotFound
+	}
+	if err := c.admit(srcName, t, id, funName); err != nil {
+		return err
+	}
+	fi := disp.RpcMethod[funName]
+	var content rpc.RpcContent
+	if fi != nil && fi.Packer != nil && fi.Packer.Name() == "json" { //todo 待优化
+		var jsonStr string
+		if len(req.Content) > 0 {
+			jsonStr = string(req.Content[0])
+		}
+		content = &rpc.Content[string]{CType: rpc.RpcContentJson, Dt: jsonStr}
+	} else {
+		content = &rpc.Content[[][]byte]{CType: rpc.RpcContentBytes, Dt: req.Content}
+	}
+
+	var ret rpc.RpcContent
+	return c.schedule(ctx, t, id, funName, func(callCtx context.Context) error {
+		var err error
+		ret, err = callAbi.onCall(callCtx, owner, funName, content)
+		return err
+	}, func(err error) {
+		if err != nil {
+			onDone(nil, err)
+			return
+		}
+		onDone(contentToBytes(ret))
+	})
+}
+
+// prepare 确保实体已加载（并触发 AddProcess），返回实体及其 call 能力实例
+func (c *CallSystemImpl) prepare(ctx context.Context, t, id string) (facade.Entity, *CallAbleAbility, error) {
 	owner, err := c.entityMgr.Get(ctx, t, id)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// 获取/准备实体的 call 能力实例
@@ -153,50 +327,44 @@ func (c *CallSystemImpl) Call(ctx context.Context, srcName string, funName strin
 		mp[id] = callAbi
 		c.mu.Unlock()
 	}
+	return owner, callAbi, nil
+}
 
-	// 根据 funName 的分发表选择 packer，再构造对应的 RpcContent
-	abiType, ok := fun2abiMap[funName]
-	if !ok {
-		return nil, facade.ErrMethodNotFound
+// schedule 在实体执行器上执行 fn：调用链成环按类型策略拒绝或重入，调用链上已有同组成员时重入，超深拒绝；
+// fn 完成（含 panic）后在同一执行器上回调 done
+func (c *CallSystemImpl) schedule(ctx context.Context, t, id, funName string, fn func(callCtx context.Context) error, done func(error)) error {
+	key := t + "/" + id
+	reentrant, err := c.checkChain(ctx, t, key)
+	if err != nil {
+		return err
 	}
-	disp := abi2callMap[abiType]
-	if disp == nil {
-		return nil, facade.ErrAbilityNotFound
+	callCtx := withCallee(ctx, key)
+	invoke := func() {
+		var callErr error
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					callErr = fmt.Errorf("entity: panic in %s: %v", funName, r)
+				}
+			}()
+			callErr = fn(callCtx)
+		}()
+		done(callErr)
 	}
-	fi := disp.RpcMethod[funName]
-	var content rpc.RpcContent
-	if fi != nil && fi.Packer != nil && fi.Packer.Name() == "json" { //todo 待优化
-		var jsonStr string
-		if len(req.Content) > 0 {
-			jsonStr = string(req.Content[0])
-		}
-		content = &rpc.Content[string]{CType: rpc.RpcContentJson, Dt: jsonStr}
-	} else {
-		content = &rpc.Content[[][]byte]{CType: rpc.RpcContentBytes, Dt: req.Content}
+	if reentrant {
+		// 重入：目标实体的执行器正等待本调用链，直接在当前 goroutine 执行
+		invoke()
+		return nil
 	}
-
 	// 按实体串行：通过 per-entity actor 排队执行
-	actor := c.getActor(t, id)
-	done := make(chan struct{})
-	var ret rpc.RpcContent
-	var callErr error
-	if err := actor.Enqueue(ctx, func() {
-		ret, callErr = callAbi.onCall(ctx, funName, content)
-		close(done)
-	}); err != nil {
-		return nil, err
-	}
-	select {
-	case <-done:
-		// ok
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
-	if callErr != nil {
-		return nil, callErr
-	}
+	return c.getActor(t, id).Enqueue(ctx, invoke)
+}
 
-	// 统一转换为 [][]byte 返回
+// contentToBytes 统一转换为 [][]byte 返回
+func contentToBytes(ret rpc.RpcContent) ([][]byte, error) {
+	if ret == nil {
+		return nil, facade.ErrEncode
+	}
 	switch ret.Type() {
 	case rpc.RpcContentBytes:
 		if resp, ok := ret.Data().([][]byte); ok {
@@ -214,6 +382,6 @@ func (c *CallSystemImpl) Call(ctx context.Context, srcName string, funName strin
 }
 
 func (c *CallSystemImpl) LocalCall(ctx context.Context, srcName string, funName string, params []any) ([]any, error) {
-	// 可选：后续实现直接本地分发（不经编解码）
+	// 可选：后续实现直接本地分发（不经编解码）；指定目标实体请使用 LocalCallAsync
 	return nil, facade.ErrMethodNotFound
 }
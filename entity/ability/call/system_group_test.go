@@ -0,0 +1,79 @@
+package call
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// groupMgr 在 fakeMgr 上声明实体组 player = {bag, mail}
+type groupMgr struct {
+	fakeMgr
+}
+
+func (m *groupMgr) GroupOf(entityType string) string {
+	if entityType == "bag" || entityType == "mail" {
+		return "player"
+	}
+	return ""
+}
+
+func (m *groupMgr) GroupMembers(group string) []string {
+	if group == "player" {
+		return []string{"bag", "mail"}
+	}
+	return nil
+}
+
+var _ facade.GroupResolver = (*groupMgr)(nil)
+
+func TestCallSystem_GroupSharesExecutor(t *testing.T) {
+	ctx := context.Background()
+	cs := &CallSystemImpl{}
+	cs.Init(ctx, &groupMgr{})
+	defer cs.CloseAll()
+
+	held, gate := make(chan struct{}), make(chan struct{})
+	if err := cs.RunInActor(ctx, "bag", "P1", func() { close(held); <-gate }); err != nil {
+		t.Fatal(err)
+	}
+	<-held
+	// mail 与 bag 同组同 id：排在同一执行器上
+	if err := cs.RunInActor(ctx, "mail", "P1", func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if n := cs.QueueLen("bag", "P1"); n != 1 {
+		t.Fatalf("queue len=%d, want 1 (shared executor)", n)
+	}
+	// 不同 id 不共享
+	if n := cs.QueueLen("mail", "P2"); n != 0 {
+		t.Fatalf("queue len=%d for other id", n)
+	}
+	close(gate)
+	deadline := time.Now().Add(time.Second)
+	for cs.QueueLen("mail", "P1") != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("shared executor not drained")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCallSystem_GroupCallReentrant(t *testing.T) {
+	ctx := context.Background()
+	cs := &CallSystemImpl{}
+	cs.Init(ctx, &groupMgr{})
+
+	chainCtx := withCallee(ctx, "bag/P1")
+	if reentrant, err := cs.checkChain(chainCtx, "mail", "mail/P1"); err != nil || !reentrant {
+		t.Fatalf("intra-group call: reentrant=%v err=%v", reentrant, err)
+	}
+	if reentrant, err := cs.checkChain(chainCtx, "mail", "mail/P2"); err != nil || reentrant {
+		t.Fatalf("other id: reentrant=%v err=%v", reentrant, err)
+	}
+	if _, err := cs.checkChain(chainCtx, "bag", "bag/P1"); err == nil {
+		t.Fatalf("self cycle should still follow reentrancy policy")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/api/entity"
 	facade "github.com/go-kratos/kratos/v2/entity/facade"
@@ -57,6 +58,14 @@ func TestCallSystem_Order_SameEntity(t *testing.T) {
 	cs.SetQueueSize(1024)
 	cs.Init(ctx, mgr)
 
+	// hold the executor so that concurrently issued calls queue up in issue order;
+	// without it the enqueue order depends on goroutine scheduling
+	held, gate := make(chan struct{}), make(chan struct{})
+	if err := cs.getActor("user", "O1").Enqueue(ctx, func() { close(held); <-gate }); err != nil {
+		t.Fatalf("hold executor: %v", err)
+	}
+	<-held
+
 	// issue concurrent calls for same entity
 	const N = 50
 	var wg sync.WaitGroup
@@ -72,7 +81,15 @@ func TestCallSystem_Order_SameEntity(t *testing.T) {
 				t.Errorf("call err: %v", err)
 			}
 		}()
+		deadline := time.Now().Add(time.Second)
+		for cs.QueueLen("user", "O1") != i+1 {
+			if time.Now().After(deadline) {
+				t.Fatalf("call %d not enqueued", i)
+			}
+			time.Sleep(time.Millisecond)
+		}
 	}
+	close(gate)
 	wg.Wait()
 
 	// verify order equals 0..N-1 strictly
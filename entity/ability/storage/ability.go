@@ -33,6 +33,15 @@ func (a *StorageAbility) Save(ctx context.Context) ([]byte, error) {
 	if a.owner == nil {
 		return nil, facade.ErrNotFound
 	}
+	// 增量路径：实体支持分区序列化且驱动支持按字段写入时，仅写入变更分区
+	if dso, ok := a.owner.(facade.DeltaSaveObject); ok {
+		if fs, ok := a.driver.(facade.FieldStorage); ok {
+			if err := a.saveDelta(ctx, dso, fs); err != nil {
+				return nil, err
+			}
+			return []byte("ok"), nil
+		}
+	}
 	so, ok := a.owner.(facade.SaveObject)
 	if !ok {
 		return nil, facade.ErrEncode
@@ -47,3 +56,21 @@ func (a *StorageAbility) Save(ctx context.Context) ([]byte, error) {
 	}
 	return []byte("ok"), nil
 }
+
+// saveDelta 仅序列化并写入变更分区，写入成功后清除对应变更标记
+// 对应的加载路径为 base.DeltaLoader（内存驱动见 entity/store.MemoryStore）。
+func (a *StorageAbility) saveDelta(ctx context.Context, dso facade.DeltaSaveObject, fs facade.FieldStorage) error {
+	sections := dso.DirtySections()
+	if len(sections) == 0 {
+		return nil
+	}
+	fields, err := dso.MarshalSections(sections)
+	if err != nil {
+		return err
+	}
+	if err := fs.PutFields(ctx, a.typeName, a.owner.ID(), fields, dso.GetSchemaVersion()); err != nil {
+		return err
+	}
+	dso.ClearSections(sections)
+	return nil
+}
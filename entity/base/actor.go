@@ -27,10 +27,7 @@ func NewActor(queueSize int) *Actor {
 	go func() {
 		defer a.wg.Done()
 		for f := range a.ch {
-			func() {
-				defer func() { _ = recover() }()
-				f()
-			}()
+			runTask(f)
 		}
 	}()
 	return a
@@ -0,0 +1,227 @@
+package base
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// EventBus 实体生命周期事件总线
+// 功能：
+// - 多订阅者；订阅时可按事件类型与实体类型过滤
+// - 同步投递：在发布方协程内直接回调（需快速返回，不可阻塞管理器）
+// - 异步投递：每个订阅者独立协程 + 有界缓冲；缓冲满时丢弃并计数，不阻塞发布方
+// 发布路径无锁：订阅表为写时复制快照，并维护已订阅事件类型掩码，无人关心的事件直接跳过。
+type EventBus struct {
+	mu     sync.Mutex
+	subs   atomic.Pointer[[]*Subscription]
+	mask   atomic.Uint32 // 已订阅事件类型的并集
+	closed bool
+}
+
+// Subscription 一个订阅；通过 Unsubscribe 取消
+type Subscription struct {
+	bus     *EventBus
+	fn      facade.EventHandler
+	kinds   uint32              // 0 表示全部
+	types   map[string]struct{} // nil 表示全部
+	ch      chan asyncEvent     // 非 nil 表示异步投递
+	done    chan struct{}
+	dropped atomic.Uint64
+
+	sendMu  sync.RWMutex // 保护异步通道的发送与关闭
+	stopped bool
+}
+
+type asyncEvent struct {
+	ctx context.Context
+	ev  facade.EntityEvent
+}
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(*Subscription)
+
+// WithEventKinds 仅接收指定类型的事件
+func WithEventKinds(kinds ...facade.EventKind) SubscribeOption {
+	return func(s *Subscription) {
+		for _, k := range kinds {
+			s.kinds |= kindBit(k)
+		}
+	}
+}
+
+// WithEntityTypes 仅接收指定实体类型的事件
+func WithEntityTypes(types ...string) SubscribeOption {
+	return func(s *Subscription) {
+		if s.types == nil {
+			s.types = make(map[string]struct{}, len(types))
+		}
+		for _, t := range types {
+			s.types[t] = struct{}{}
+		}
+	}
+}
+
+// WithAsyncDelivery 异步投递，buffer 为缓冲事件数（<=0 取 1024）
+func WithAsyncDelivery(buffer int) SubscribeOption {
+	return func(s *Subscription) {
+		if buffer <= 0 {
+			buffer = 1024
+		}
+		s.ch = make(chan asyncEvent, buffer)
+	}
+}
+
+// NewEventBus 创建事件总线
+func NewEventBus() *EventBus {
+	b := &EventBus{}
+	b.subs.Store(&[]*Subscription{})
+	return b
+}
+
+func kindBit(k facade.EventKind) uint32 { return 1 << uint32(k) }
+
+// Subscribe 注册订阅者
+func (b *EventBus) Subscribe(fn facade.EventHandler, opts ...SubscribeOption) *Subscription {
+	s := &Subscription{bus: b, fn: fn, done: make(chan struct{})}
+	for _, o := range opts {
+		o(s)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(s.done)
+		return s
+	}
+	if s.ch != nil {
+		go s.run()
+	} else {
+		close(s.done)
+	}
+	old := *b.subs.Load()
+	next := make([]*Subscription, 0, len(old)+1)
+	next = append(next, old...)
+	next = append(next, s)
+	b.storeLocked(next)
+	return s
+}
+
+// Enabled 是否存在关心该事件类型的订阅者（发布前的快速判断）
+func (b *EventBus) Enabled(kind facade.EventKind) bool {
+	return b.mask.Load()&kindBit(kind) != 0
+}
+
+// Publish 发布事件
+func (b *EventBus) Publish(ctx context.Context, ev facade.EntityEvent) {
+	if !b.Enabled(ev.Kind) {
+		return
+	}
+	for _, s := range *b.subs.Load() {
+		if !s.match(ev) {
+			continue
+		}
+		if s.ch == nil {
+			s.fn(ctx, ev)
+			continue
+		}
+		s.enqueue(ctx, ev)
+	}
+}
+
+// Close 取消全部订阅并等待异步订阅者处理完已缓冲事件
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	b.closed = true
+	subs := *b.subs.Load()
+	b.storeLocked(nil)
+	b.mu.Unlock()
+	for _, s := range subs {
+		s.stop()
+	}
+	for _, s := range subs {
+		s.Wait()
+	}
+}
+
+func (b *EventBus) storeLocked(subs []*Subscription) {
+	var mask uint32
+	for _, s := range subs {
+		if s.kinds == 0 {
+			mask = ^uint32(0)
+		} else {
+			mask |= s.kinds
+		}
+	}
+	b.subs.Store(&subs)
+	b.mask.Store(mask)
+}
+
+// Unsubscribe 取消订阅，不等待异步订阅者（可在其自身回调内调用）
+// 异步订阅者会继续处理完已缓冲事件；需要确认处理结束时调用 Wait。
+func (s *Subscription) Unsubscribe() {
+	b := s.bus
+	b.mu.Lock()
+	old := *b.subs.Load()
+	next := make([]*Subscription, 0, len(old))
+	for _, o := range old {
+		if o != s {
+			next = append(next, o)
+		}
+	}
+	b.storeLocked(next)
+	b.mu.Unlock()
+	s.stop()
+}
+
+// Wait 等待订阅者处理完已缓冲事件并退出（取消订阅之后调用；不可在其自身回调内调用）
+func (s *Subscription) Wait() { <-s.done }
+
+// Dropped 异步缓冲满而丢弃的事件数
+func (s *Subscription) Dropped() uint64 { return s.dropped.Load() }
+
+func (s *Subscription) match(ev facade.EntityEvent) bool {
+	if s.kinds != 0 && s.kinds&kindBit(ev.Kind) == 0 {
+		return false
+	}
+	if s.types != nil {
+		if _, ok := s.types[ev.EntityType]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Subscription) run() {
+	defer close(s.done)
+	for e := range s.ch {
+		s.fn(e.ctx, e.ev)
+	}
+}
+
+// enqueue 非阻塞写入异步缓冲；缓冲满或已停止时丢弃
+func (s *Subscription) enqueue(ctx context.Context, ev facade.EntityEvent) {
+	s.sendMu.RLock()
+	defer s.sendMu.RUnlock()
+	if s.stopped {
+		return
+	}
+	select {
+	case s.ch <- asyncEvent{ctx: context.WithoutCancel(ctx), ev: ev}:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// stop 停止投递：关闭异步缓冲，已缓冲事件由订阅者协程继续消费
+func (s *Subscription) stop() {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if !s.stopped {
+		s.stopped = true
+		if s.ch != nil {
+			close(s.ch)
+		}
+	}
+}
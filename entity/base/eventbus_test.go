@@ -0,0 +1,164 @@
+package base
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+func TestEventBus_FilterAndUnsubscribe(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Close()
+	ctx := context.Background()
+
+	var all, removedUsers []facade.EntityEvent
+	bus.Subscribe(func(ctx context.Context, ev facade.EntityEvent) { all = append(all, ev) })
+	sub := bus.Subscribe(func(ctx context.Context, ev facade.EntityEvent) { removedUsers = append(removedUsers, ev) },
+		WithEventKinds(facade.EventRemoved, facade.EventEvicted), WithEntityTypes("user"))
+
+	bus.Publish(ctx, facade.EntityEvent{Kind: facade.EventCreated, EntityType: "user", ID: "1"})
+	bus.Publish(ctx, facade.EntityEvent{Kind: facade.EventRemoved, EntityType: "guild", ID: "1"})
+	bus.Publish(ctx, facade.EntityEvent{Kind: facade.EventEvicted, EntityType: "user", ID: "1"})
+	if len(all) != 3 || len(removedUsers) != 1 || removedUsers[0].Kind != facade.EventEvicted {
+		t.Fatalf("all=%d filtered=%v", len(all), removedUsers)
+	}
+
+	sub.Unsubscribe()
+	bus.Publish(ctx, facade.EntityEvent{Kind: facade.EventRemoved, EntityType: "user", ID: "2"})
+	if len(removedUsers) != 1 {
+		t.Fatalf("received after unsubscribe: %v", removedUsers)
+	}
+}
+
+func TestEventBus_AsyncDelivery(t *testing.T) {
+	bus := NewEventBus()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	block := make(chan struct{})
+	var mu sync.Mutex
+	var got []string
+	sub := bus.Subscribe(func(ctx context.Context, ev facade.EntityEvent) {
+		<-block
+		if ctx.Err() != nil {
+			t.Errorf("async ctx should not inherit cancellation")
+		}
+		mu.Lock()
+		got = append(got, ev.ID)
+		mu.Unlock()
+	}, WithAsyncDelivery(2))
+
+	// 订阅者阻塞时发布方不被阻塞；超出缓冲的事件被丢弃
+	done := make(chan struct{})
+	go func() {
+		for _, id := range []string{"1", "2", "3", "4", "5"} {
+			bus.Publish(ctx, facade.EntityEvent{Kind: facade.EventAccessed, EntityType: "user", ID: id})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("publish blocked by slow async subscriber")
+	}
+	cancel()
+	if sub.Dropped() == 0 {
+		t.Fatalf("expected dropped events")
+	}
+	close(block)
+	bus.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if uint64(len(got))+sub.Dropped() != 5 {
+		t.Fatalf("got=%v dropped=%d", got, sub.Dropped())
+	}
+}
+
+func TestEventBus_AsyncSelfUnsubscribe(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Close()
+
+	returned := make(chan struct{})
+	var sub *Subscription
+	var mu sync.Mutex
+	var got []string
+	sub = bus.Subscribe(func(ctx context.Context, ev facade.EntityEvent) {
+		mu.Lock()
+		got = append(got, ev.ID)
+		mu.Unlock()
+		if ev.ID == "1" {
+			sub.Unsubscribe()
+			close(returned)
+		}
+	}, WithAsyncDelivery(4))
+	for _, id := range []string{"1", "2"} {
+		bus.Publish(context.Background(), facade.EntityEvent{Kind: facade.EventAccessed, EntityType: "user", ID: id})
+	}
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatalf("unsubscribe inside own handler deadlocked")
+	}
+	exited := make(chan struct{})
+	go func() { sub.Wait(); close(exited) }()
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatalf("async subscriber did not exit")
+	}
+	bus.Publish(context.Background(), facade.EntityEvent{Kind: facade.EventAccessed, EntityType: "user", ID: "3"})
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) > 2 || got[0] != "1" {
+		t.Fatalf("got=%v", got)
+	}
+}
+
+func TestMemoryManager_LifecycleEvents(t *testing.T) {
+	mgr := NewMemoryManager(WithCacheTTLMillis(20), WithWheelTickMillis(5), WithKeepAliveOnGet(false), WithDestroyOnUnload(true))
+	mgr.RegisterNotFoundHook("user", func(ctx context.Context, id string) (facade.Entity, error) { return &mmEntity{}, nil })
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var kinds []facade.EventKind
+	mgr.Subscribe(func(ctx context.Context, ev facade.EntityEvent) {
+		if ev.EntityType != "user" || ev.Entity == nil {
+			t.Errorf("unexpected event %+v", ev)
+		}
+		mu.Lock()
+		kinds = append(kinds, ev.Kind)
+		mu.Unlock()
+	})
+
+	if _, err := mgr.Create(ctx, "user", "E1", func() facade.Entity { return &mmEntity{} }); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = mgr.Get(ctx, "user", "E1")
+	_ = mgr.Remove(ctx, "user", "E1")
+	_, _ = mgr.Get(ctx, "user", "E2") // 经 NotFoundHook 加载，随后 TTL 卸载并销毁
+
+	want := []facade.EventKind{facade.EventCreated, facade.EventAccessed, facade.EventRemoved, facade.EventLoaded, facade.EventEvicted, facade.EventDestroyed}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(kinds)
+		mu.Unlock()
+		if n >= len(want) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(kinds) != len(want) {
+		t.Fatalf("kinds=%v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("kinds=%v, want %v", kinds, want)
+		}
+	}
+}
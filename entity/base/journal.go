@@ -0,0 +1,227 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Journal 事件溯源支持（审计轨迹替代最后写入覆盖）
+// 功能：
+// - Emit：能力方法产生领域事件，先同步追加到日志（乐观并发，按序号校验），成功后作用于内存状态
+// - Save：由保存调度器周期调用，事件数达到阈值时写入状态快照（实体嵌入后即具备 SaveAble.Save）
+// - Recover：加载时从最新快照开始重放其后的事件
+// - ReplayEntity：工具函数，将单个实体的历史重放到指定序号或时间点
+// 使用方式：
+//
+//	type Wallet struct {
+//		base.BaseEntity
+//		base.Journal
+//		Balance int64
+//	}
+//	func (w *Wallet) ApplyEvent(ev *facade.JournalEvent) error { ... }
+//	func (w *Wallet) AutoSetDirty() bool { return false }
+//	// 构造后 w.Journal.Bind(w, store)；NotFoundHook 中 SetID 后调用 w.Recover(ctx)
+//	// 可选 w.OnStale(func(ctx context.Context, e facade.Entity) { _ = mgr.Remove(ctx, e.Type(), e.ID()) })
+//
+// 说明：事件已持久化在日志中，快照只用于加速加载；快照失败不丢数据。
+// 已追加的事件应用失败时内存状态不可信：实例被标记为待重新加载，之后的 Emit/Save 均返回 ErrEntityStale。
+// write-behind 管道对事件溯源实体走同步 Save（写快照），不进入批量写出。
+type Journal struct {
+	mu            sync.Mutex
+	owner         facade.EventSourced
+	store         facade.JournalStorage
+	seq           uint64 // 已追加并应用的最新事件序号
+	snapSeq       uint64 // 最近一次快照的序号
+	snapshotEvery uint64
+	stale         bool
+	onStale       func(ctx context.Context, e facade.Entity)
+}
+
+// Bind 绑定实体与日志存储（建议在构造函数中调用）
+func (j *Journal) Bind(owner facade.EventSourced, store facade.JournalStorage) {
+	j.mu.Lock()
+	j.owner, j.store = owner, store
+	j.mu.Unlock()
+}
+
+// OnStale 设置状态失效回调：事件已追加但应用失败时调用（如将实体移出内存，下次访问重新加载）
+func (j *Journal) OnStale(fn func(ctx context.Context, e facade.Entity)) {
+	j.mu.Lock()
+	j.onStale = fn
+	j.mu.Unlock()
+}
+
+// Stale 内存状态是否已失效（需重新加载实体）
+func (j *Journal) Stale() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stale
+}
+
+// SetSnapshotEvery 设置快照间隔：自上次快照起累计至少 n 个事件才写入（<=1 表示有新事件即写）
+func (j *Journal) SetSnapshotEvery(n int) {
+	j.mu.Lock()
+	if n > 1 {
+		j.snapshotEvery = uint64(n)
+	} else {
+		j.snapshotEvery = 0
+	}
+	j.mu.Unlock()
+}
+
+// Seq 当前已应用的最新事件序号
+func (j *Journal) Seq() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.seq
+}
+
+// Emit 产生领域事件：data 为 []byte 时原样写入，否则按 JSON 编码（需在实体执行器内调用）
+// 追加失败时状态不变；日志已被其他写入者推进时返回 ErrJournalConflict（需重新加载实体）。
+// 追加与应用期间不持有锁，ApplyEvent 内可调用 Seq 等方法；应用失败时标记失效并返回 ErrEntityStale。
+func (j *Journal) Emit(ctx context.Context, kind string, data any) error {
+	payload, ok := data.([]byte)
+	if !ok {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("%w: %v", facade.ErrEncode, err)
+		}
+		payload = b
+	}
+	j.mu.Lock()
+	e, err := j.entityLocked()
+	if err != nil {
+		j.mu.Unlock()
+		return err
+	}
+	owner, store, expect := j.owner, j.store, j.seq
+	j.mu.Unlock()
+
+	ev := &facade.JournalEvent{Seq: expect + 1, Kind: kind, Data: payload, TimeMs: time.Now().UnixMilli()}
+	if err := store.AppendEvents(ctx, e.Type(), e.ID(), expect, []*facade.JournalEvent{ev}); err != nil {
+		return err
+	}
+	// 事件即事实：已追加的事件无论应用结果如何都推进序号，保证与日志一致
+	j.mu.Lock()
+	j.seq = ev.Seq
+	j.mu.Unlock()
+	if d, ok := owner.(facade.DirtyOperator); ok {
+		d.SetDirty(true)
+	}
+	if err := owner.ApplyEvent(ev); err != nil {
+		j.mu.Lock()
+		j.stale = true
+		onStale := j.onStale
+		j.mu.Unlock()
+		log.Errorf("entity: journal %s/%s@%d appended but not applied, reload required: %v", e.Type(), e.ID(), ev.Seq, err)
+		if onStale != nil {
+			onStale(ctx, e)
+		}
+		return fmt.Errorf("%w: %s/%s@%d: %v", facade.ErrEntityStale, e.Type(), e.ID(), ev.Seq, err)
+	}
+	return nil
+}
+
+// Save 写入状态快照（自上次快照起的事件数未达阈值时跳过）
+func (j *Journal) Save(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	e, err := j.entityLocked()
+	if err != nil {
+		return err
+	}
+	if j.seq == j.snapSeq || j.seq-j.snapSeq < j.snapshotEvery {
+		return nil
+	}
+	payload, err := j.owner.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("%w: %v", facade.ErrEncode, err)
+	}
+	snap := &facade.JournalSnapshot{Seq: j.seq, Schema: j.owner.GetSchemaVersion(), Payload: payload, TimeMs: time.Now().UnixMilli()}
+	if err := j.store.PutSnapshot(ctx, e.Type(), e.ID(), snap); err != nil {
+		return err
+	}
+	j.snapSeq = j.seq
+	return nil
+}
+
+// Recover 从最新快照与其后的事件恢复实体状态（加载时调用，需已设置 ID）
+// 与 Emit 相同，重放期间不持有锁。
+func (j *Journal) Recover(ctx context.Context) error {
+	j.mu.Lock()
+	e, err := j.entityLocked()
+	owner, store := j.owner, j.store
+	j.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	snapSeq, seq, err := replay(ctx, store, owner, e.Type(), e.ID(), 0, 0)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	j.snapSeq, j.seq = snapSeq, seq
+	j.mu.Unlock()
+	return nil
+}
+
+// entityLocked 返回绑定的实体；未绑定或状态已失效时返回错误
+func (j *Journal) entityLocked() (facade.Entity, error) {
+	if j.owner == nil || j.store == nil {
+		return nil, fmt.Errorf("%w: journal not bound", facade.ErrInvalidConfig)
+	}
+	e, ok := j.owner.(facade.Entity)
+	if !ok {
+		return nil, fmt.Errorf("%w: journal owner is not an entity", facade.ErrInvalidConfig)
+	}
+	if j.stale {
+		return nil, fmt.Errorf("%w: %s/%s", facade.ErrEntityStale, e.Type(), e.ID())
+	}
+	return e, nil
+}
+
+// ReplayEntity 将单个实体的历史重放到 e（应为新构造的实例），返回最后应用的事件序号
+// toSeq/toTimeMs 限定重放终点（0 表示不限），可用于审计或排查某一时刻的状态。
+func ReplayEntity(ctx context.Context, store facade.JournalStorage, e facade.EventSourced, typeName, id string, toSeq uint64, toTimeMs int64) (uint64, error) {
+	_, seq, err := replay(ctx, store, e, typeName, id, toSeq, toTimeMs)
+	return seq, err
+}
+
+// replay 从满足终点约束的最新快照开始重放事件，返回快照序号与最后应用的事件序号
+func replay(ctx context.Context, store facade.JournalStorage, e facade.EventSourced, typeName, id string, toSeq uint64, toTimeMs int64) (uint64, uint64, error) {
+	snap, err := store.LatestSnapshot(ctx, typeName, id, toSeq, toTimeMs)
+	if err != nil {
+		return 0, 0, err
+	}
+	var snapSeq uint64
+	if snap != nil {
+		if err := e.UnmarshalBinary(snap.Payload); err != nil {
+			return 0, 0, fmt.Errorf("%w: snapshot %s/%s@%d: %v", facade.ErrDecode, typeName, id, snap.Seq, err)
+		}
+		snapSeq = snap.Seq
+	}
+	events, err := store.ReadEvents(ctx, typeName, id, snapSeq, toSeq)
+	if err != nil {
+		return 0, 0, err
+	}
+	seq := snapSeq
+	for _, ev := range events {
+		if toTimeMs > 0 && ev.TimeMs > toTimeMs {
+			break
+		}
+		if ev.Seq != seq+1 {
+			return 0, 0, fmt.Errorf("%w: journal gap %s/%s at %d", facade.ErrJournalConflict, typeName, id, seq+1)
+		}
+		if err := e.ApplyEvent(ev); err != nil {
+			return 0, 0, fmt.Errorf("entity: replay %s/%s@%d: %w", typeName, id, ev.Seq, err)
+		}
+		seq = ev.Seq
+	}
+	return snapSeq, seq, nil
+}
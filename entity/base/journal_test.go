@@ -0,0 +1,166 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/entity/store"
+)
+
+type walletEntity struct {
+	BaseEntity
+	Journal
+	Balance int64  `json:"balance"`
+	seen    uint64 // ApplyEvent 时观察到的 Seq
+}
+
+type depositEvent struct {
+	Amount int64 `json:"amount"`
+}
+
+func newWallet(store facade.JournalStorage, id string) *walletEntity {
+	w := &walletEntity{}
+	w.SetTypeName("wallet")
+	w.SetID(id)
+	w.Journal.Bind(w, store)
+	return w
+}
+
+func (w *walletEntity) ApplyEvent(ev *facade.JournalEvent) error {
+	var d depositEvent
+	if err := json.Unmarshal(ev.Data, &d); err != nil {
+		return err
+	}
+	if d.Amount < 0 {
+		return errors.New("negative deposit")
+	}
+	w.seen = w.Seq()
+	w.Balance += d.Amount
+	return nil
+}
+func (w *walletEntity) LogicType() facade.LType        { return 0 }
+func (w *walletEntity) OwnerID() string                { return w.ID() }
+func (w *walletEntity) MarshalBinary() ([]byte, error) { return json.Marshal(w) }
+func (w *walletEntity) UnmarshalBinary(b []byte) error { return json.Unmarshal(b, w) }
+func (w *walletEntity) GetSchemaVersion() int          { return 1 }
+func (w *walletEntity) AutoSetDirty() bool             { return false }
+
+var _ facade.SaveAble = (*walletEntity)(nil)
+var _ facade.EventSourced = (*walletEntity)(nil)
+
+func TestJournal_EmitSnapshotRecover(t *testing.T) {
+	ctx := context.Background()
+	store := store.NewMemoryStore()
+	w := newWallet(store, "W1")
+	w.SetSnapshotEvery(2)
+
+	for _, amt := range []int64{10, 20} {
+		if err := w.Emit(ctx, "deposit", depositEvent{Amount: amt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !w.IsDirty() || w.Balance != 30 || w.Seq() != 2 {
+		t.Fatalf("balance=%d seq=%d dirty=%v", w.Balance, w.Seq(), w.IsDirty())
+	}
+	if err := w.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Emit(ctx, "deposit", depositEvent{Amount: 5}); err != nil {
+		t.Fatal(err)
+	}
+	// 未达快照阈值：不写快照
+	if err := w.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if snap, _ := store.LatestSnapshot(ctx, "wallet", "W1", 0, 0); snap == nil || snap.Seq != 2 {
+		t.Fatalf("latest snapshot=%+v, want seq 2", snap)
+	}
+
+	// 从快照(seq=2) + 事件 3 恢复
+	r := newWallet(store, "W1")
+	if err := r.Recover(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if r.Balance != 35 || r.Seq() != 3 {
+		t.Fatalf("recovered balance=%d seq=%d", r.Balance, r.Seq())
+	}
+
+	// 过期实例继续写入：日志已被推进
+	if err := w.Emit(ctx, "deposit", depositEvent{Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Emit(ctx, "deposit", depositEvent{Amount: 1}); !errors.Is(err, facade.ErrJournalConflict) {
+		t.Fatalf("stale emit err=%v", err)
+	}
+	if r.Balance != 35 {
+		t.Fatalf("state changed on failed append: %d", r.Balance)
+	}
+}
+
+func TestJournal_ReplayToPoint(t *testing.T) {
+	ctx := context.Background()
+	store := store.NewMemoryStore()
+	w := newWallet(store, "W2")
+	for _, amt := range []int64{1, 2, 4, 8} {
+		if err := w.Emit(ctx, "deposit", depositEvent{Amount: amt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// 终点早于快照：忽略快照，从头重放
+	h := newWallet(store, "W2")
+	seq, err := ReplayEntity(ctx, store, h, "wallet", "W2", 2, 0)
+	if err != nil || seq != 2 || h.Balance != 3 {
+		t.Fatalf("replay to 2: seq=%d balance=%d err=%v", seq, h.Balance, err)
+	}
+	// 按时间点：早于首个事件时为空状态
+	evs, _ := store.ReadEvents(ctx, "wallet", "W2", 0, 1)
+	first := evs[0].TimeMs
+	h = newWallet(store, "W2")
+	seq, err = ReplayEntity(ctx, store, h, "wallet", "W2", 0, first-1)
+	if err != nil || seq != 0 || h.Balance != 0 {
+		t.Fatalf("replay to time: seq=%d balance=%d err=%v", seq, h.Balance, err)
+	}
+}
+
+func TestJournal_ApplyFailureMarksStale(t *testing.T) {
+	ctx := context.Background()
+	store := store.NewMemoryStore()
+	w := newWallet(store, "W3")
+	var reloaded []string
+	w.OnStale(func(ctx context.Context, e facade.Entity) { reloaded = append(reloaded, e.ID()) })
+
+	// ApplyEvent 内可读取 Seq（Emit 不持锁回调）
+	if err := w.Emit(ctx, "deposit", depositEvent{Amount: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if w.seen != 1 {
+		t.Fatalf("seq seen in apply=%d, want 1", w.seen)
+	}
+
+	err := w.Emit(ctx, "deposit", depositEvent{Amount: -1})
+	if !errors.Is(err, facade.ErrEntityStale) || !w.Stale() || w.Seq() != 2 {
+		t.Fatalf("err=%v stale=%v seq=%d", err, w.Stale(), w.Seq())
+	}
+	if len(reloaded) != 1 || reloaded[0] != "W3" {
+		t.Fatalf("reload callback=%v", reloaded)
+	}
+	// 失效实例不再写入事件或快照
+	if err := w.Emit(ctx, "deposit", depositEvent{Amount: 1}); !errors.Is(err, facade.ErrEntityStale) {
+		t.Fatalf("emit on stale err=%v", err)
+	}
+	if err := w.Save(ctx); !errors.Is(err, facade.ErrEntityStale) {
+		t.Fatalf("save on stale err=%v", err)
+	}
+	if snap, _ := store.LatestSnapshot(ctx, "wallet", "W3", 0, 0); snap != nil {
+		t.Fatalf("stale state snapshotted: %+v", snap)
+	}
+	if evs, _ := store.ReadEvents(ctx, "wallet", "W3", 0, 0); len(evs) != 2 {
+		t.Fatalf("events=%d, want 2", len(evs))
+	}
+}
@@ -0,0 +1,286 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// DataLifecycle 持久化数据生命周期：删除、软删除与冷热归档
+// 功能：
+// - Delete：移出内存 + 丢弃积压快照 + 删除存储数据与索引 + 解除路由链接（该 id 无其他已加载类型时）
+// - 软删除：配置保留期且热存储实现 SoftDeleteStorage 时仅标记删除，保留期内可 Undelete，过期后后台清除
+// - 归档：热存储实现 ActivityStorage 时，后台将长期未写入的实体搬到冷存储；下次 Get 未命中时透明恢复
+// 热/冷存储均需实现 facade.RecordStorage；已加载在内存中的实体不会被归档。
+type DataLifecycle struct {
+	mgr  *MemoryManager
+	hot  facade.RecordStorage
+	opts lifecycleOptions
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	closeOnce sync.Once
+
+	deleted, softDeleted, archived, restored, purged atomic.Uint64
+}
+
+type lifecycleOptions struct {
+	Cold         facade.RecordStorage
+	Router       facade.Router
+	Retention    time.Duration // 软删除保留期；0 表示直接物理删除
+	ArchiveAfter time.Duration // 未写入超过该时长的实体被归档；0 表示不归档
+	ArchiveTypes []string
+	Interval     time.Duration // 后台归档/清除周期
+	BatchSize    int
+}
+
+// LifecycleOption 配置 DataLifecycle
+type LifecycleOption func(*lifecycleOptions)
+
+// WithColdStorage 设置冷存储（归档目标）；设置后 Get 未命中时自动从冷存储恢复
+func WithColdStorage(cold facade.RecordStorage) LifecycleOption {
+	return func(o *lifecycleOptions) { o.Cold = cold }
+}
+
+// WithLifecycleRouter 设置路由，用于删除时解除本 Pod 的链接
+func WithLifecycleRouter(r facade.Router) LifecycleOption {
+	return func(o *lifecycleOptions) { o.Router = r }
+}
+
+// WithSoftDeleteRetention 删除改为软删除，数据保留 d 后清除
+func WithSoftDeleteRetention(d time.Duration) LifecycleOption {
+	return func(o *lifecycleOptions) { o.Retention = d }
+}
+
+// WithArchiveAfter 归档指定类型中超过 d 未写入的实体
+func WithArchiveAfter(d time.Duration, types ...string) LifecycleOption {
+	return func(o *lifecycleOptions) { o.ArchiveAfter, o.ArchiveTypes = d, append([]string(nil), types...) }
+}
+
+// WithLifecycleInterval 设置后台归档/清除周期（默认 1h；<=0 不启动后台任务，可手动 RunOnce）
+func WithLifecycleInterval(d time.Duration) LifecycleOption {
+	return func(o *lifecycleOptions) { o.Interval = d }
+}
+
+// WithLifecycleBatchSize 设置单轮每类型处理的最大实体数（默认 100）
+func WithLifecycleBatchSize(n int) LifecycleOption {
+	return func(o *lifecycleOptions) {
+		if n > 0 {
+			o.BatchSize = n
+		}
+	}
+}
+
+// LifecycleStats 累计计数
+type LifecycleStats struct {
+	Deleted     uint64
+	SoftDeleted uint64
+	Archived    uint64
+	Restored    uint64
+	Purged      uint64
+}
+
+// NewDataLifecycle 创建并启动数据生命周期管理；配置冷存储时向 mgr 注册透明恢复
+func NewDataLifecycle(mgr *MemoryManager, hot facade.RecordStorage, opts ...LifecycleOption) *DataLifecycle {
+	o := lifecycleOptions{Interval: time.Hour, BatchSize: 100}
+	for _, fn := range opts {
+		fn(&o)
+	}
+	l := &DataLifecycle{mgr: mgr, hot: hot, opts: o, stopCh: make(chan struct{}), doneCh: make(chan struct{})}
+	if o.Cold != nil {
+		mgr.SetRestorer(l.Restore)
+	}
+	if o.Interval > 0 {
+		go l.loop()
+	} else {
+		close(l.doneCh)
+	}
+	return l
+}
+
+// discarder 可丢弃积压快照的保存管道（如 WriteBehind）
+type discarder interface {
+	Discard(typeName, id string) bool
+}
+
+// Delete 删除实体：内存、积压快照、存储数据（或软删除标记）、索引与路由链接
+func (l *DataLifecycle) Delete(ctx context.Context, entityType, id string) error {
+	m := l.mgr
+	_ = m.Remove(ctx, entityType, id)
+	m.mu.RLock()
+	sink, idx := m.saveSink, m.indexStore
+	m.mu.RUnlock()
+	if d, ok := sink.(discarder); ok {
+		d.Discard(entityType, id)
+	}
+
+	if sd, ok := l.hot.(facade.SoftDeleteStorage); ok && l.opts.Retention > 0 {
+		purgeAt := time.Now().Add(l.opts.Retention).UnixMilli()
+		if err := sd.MarkDeleted(ctx, entityType, id, purgeAt); err != nil {
+			return err
+		}
+		l.softDeleted.Add(1)
+	} else {
+		if err := l.hot.DeleteRecord(ctx, entityType, id); err != nil {
+			return err
+		}
+		l.deleted.Add(1)
+	}
+	if l.opts.Cold != nil {
+		if err := l.opts.Cold.DeleteRecord(ctx, entityType, id); err != nil {
+			return err
+		}
+	}
+	if idx != nil {
+		if err := idx.DeleteIndex(ctx, entityType, id); err != nil {
+			return err
+		}
+	}
+	// 路由按 id 链接：同一 id 仍有其他类型在内存中时保留
+	if l.opts.Router != nil && len(m.loadedTypes(id)) == 0 {
+		if err := l.opts.Router.UnlinkLocal(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Undelete 撤销软删除（保留期内），并重新加载以恢复索引
+func (l *DataLifecycle) Undelete(ctx context.Context, entityType, id string) error {
+	sd, ok := l.hot.(facade.SoftDeleteStorage)
+	if !ok {
+		return fmt.Errorf("%w: storage does not support soft delete", facade.ErrInvalidConfig)
+	}
+	if err := sd.Undelete(ctx, entityType, id); err != nil {
+		return err
+	}
+	e, err := l.mgr.Get(ctx, entityType, id)
+	if err != nil {
+		return err
+	}
+	return l.mgr.putIndex(ctx, e)
+}
+
+// Archive 将实体从热存储搬到冷存储；实体在内存中时跳过并返回 false
+// 整个搬移过程持有实体键锁，与加载互斥：搬移期间的 Get 会等待并透明恢复
+func (l *DataLifecycle) Archive(ctx context.Context, entityType, id string) (bool, error) {
+	if l.opts.Cold == nil {
+		return false, fmt.Errorf("%w: no cold storage", facade.ErrInvalidConfig)
+	}
+	lk := l.mgr.lockKey(entityType, id)
+	defer l.mgr.unlockKey(lk)
+	if _, ok := l.mgr.lookup(entityType, id); ok {
+		return false, nil
+	}
+	rec, err := l.hot.GetRecord(ctx, entityType, id)
+	if errors.Is(err, facade.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// 先写冷再删热：中途失败时数据至多存在两份，不会丢失
+	if err := l.opts.Cold.PutRecord(ctx, rec); err != nil {
+		return false, err
+	}
+	if err := l.hot.DeleteRecord(ctx, entityType, id); err != nil {
+		return false, err
+	}
+	l.archived.Add(1)
+	return true, nil
+}
+
+// Restore 将实体从冷存储搬回热存储；未归档返回 false
+// 加载路径在持有实体键锁时调用，此处不再加锁
+func (l *DataLifecycle) Restore(ctx context.Context, entityType, id string) (bool, error) {
+	if l.opts.Cold == nil {
+		return false, nil
+	}
+	rec, err := l.opts.Cold.GetRecord(ctx, entityType, id)
+	if errors.Is(err, facade.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := l.hot.PutRecord(ctx, rec); err != nil {
+		return false, err
+	}
+	if err := l.opts.Cold.DeleteRecord(ctx, entityType, id); err != nil {
+		return false, err
+	}
+	l.restored.Add(1)
+	return true, nil
+}
+
+// RunOnce 执行一轮归档与软删除清除，返回本轮归档与清除的数量
+func (l *DataLifecycle) RunOnce(ctx context.Context) (archived, purged int, err error) {
+	if as, ok := l.hot.(facade.ActivityStorage); ok && l.opts.Cold != nil && l.opts.ArchiveAfter > 0 {
+		before := time.Now().Add(-l.opts.ArchiveAfter).UnixMilli()
+		for _, t := range l.opts.ArchiveTypes {
+			ids, lerr := as.ListInactive(ctx, t, before, l.opts.BatchSize)
+			if lerr != nil {
+				err = errors.Join(err, lerr)
+				continue
+			}
+			for _, id := range ids {
+				ok, aerr := l.Archive(ctx, t, id)
+				if aerr != nil {
+					err = errors.Join(err, fmt.Errorf("archive %s/%s: %w", t, id, aerr))
+					continue
+				}
+				if ok {
+					archived++
+				}
+			}
+		}
+	}
+	if sd, ok := l.hot.(facade.SoftDeleteStorage); ok && l.opts.Retention > 0 {
+		n, perr := sd.PurgeDeleted(ctx, time.Now().UnixMilli(), l.opts.BatchSize)
+		if perr != nil {
+			err = errors.Join(err, perr)
+		}
+		purged = n
+		l.purged.Add(uint64(n))
+	}
+	return archived, purged, err
+}
+
+// Stats 返回累计计数
+func (l *DataLifecycle) Stats() LifecycleStats {
+	return LifecycleStats{
+		Deleted:     l.deleted.Load(),
+		SoftDeleted: l.softDeleted.Load(),
+		Archived:    l.archived.Load(),
+		Restored:    l.restored.Load(),
+		Purged:      l.purged.Load(),
+	}
+}
+
+// Close 停止后台任务
+func (l *DataLifecycle) Close() {
+	l.closeOnce.Do(func() { close(l.stopCh) })
+	<-l.doneCh
+}
+
+func (l *DataLifecycle) loop() {
+	defer close(l.doneCh)
+	ticker := time.NewTicker(l.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stopCh:
+			return
+		case <-ticker.C:
+			if _, _, err := l.RunOnce(context.Background()); err != nil {
+				log.Errorf("entity: data lifecycle: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/entity/index"
+	"github.com/go-kratos/kratos/v2/entity/store"
+)
+
+// hasRecord 热/冷存储中是否存在可见记录
+func hasRecord(s *store.MemoryStore, typeName, id string) bool {
+	_, err := s.GetRecord(context.Background(), typeName, id)
+	return err == nil
+}
+
+type fakeUnlinkRouter struct {
+	mu       sync.Mutex
+	unlinked []string
+}
+
+func (r *fakeUnlinkRouter) TrySetLocal(ctx context.Context, id string) (bool, int, error) {
+	return true, 0, nil
+}
+func (r *fakeUnlinkRouter) UnlinkLocal(ctx context.Context, id string) error {
+	r.mu.Lock()
+	r.unlinked = append(r.unlinked, id)
+	r.mu.Unlock()
+	return nil
+}
+func (r *fakeUnlinkRouter) CurrentPod() int                                        { return 0 }
+func (r *fakeUnlinkRouter) ResolvePod(ctx context.Context, id string) (int, error) { return 0, nil }
+
+// newLifecycleMgr 实体从热存储加载（Payload 即 value）
+func newLifecycleMgr(hot *store.MemoryStore) *MemoryManager {
+	mgr := NewMemoryManager()
+	mgr.RegisterNotFoundHook("user", func(ctx context.Context, id string) (facade.Entity, error) {
+		rec, err := hot.GetRecord(ctx, "user", id)
+		if err != nil {
+			return nil, err
+		}
+		w := &wbEntity{value: string(rec.Payload)}
+		w.SetTypeName("user")
+		return w, nil
+	})
+	return mgr
+}
+
+func TestDataLifecycle_Delete(t *testing.T) {
+	ctx := context.Background()
+	hot := store.NewMemoryStore()
+	_ = hot.PutRecord(ctx, &facade.SaveRecord{TypeName: "user", ID: "D1", Payload: []byte("v")})
+	mgr := newLifecycleMgr(hot)
+	idx := index.NewMemoryIndex()
+	mgr.SetIndexStorage(idx)
+	_ = idx.PutIndex(ctx, "user", "D1", map[string]facade.IndexValue{"guild": facade.ExactIndex("g")})
+	router := &fakeUnlinkRouter{}
+	lc := NewDataLifecycle(mgr, hot, WithLifecycleRouter(router), WithLifecycleInterval(0))
+	defer lc.Close()
+
+	if _, err := mgr.Get(ctx, "user", "D1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lc.Delete(ctx, "user", "D1"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := mgr.Exists(ctx, "user", "D1"); ok || hasRecord(hot, "user", "D1") {
+		t.Fatalf("entity still present: memory=%v storage=%v", ok, hasRecord(hot, "user", "D1"))
+	}
+	res, _ := idx.QueryIndex(ctx, facade.IndexExact, &facade.Query{EntityType: "user", Field: "guild", Eq: "g"})
+	if len(res.IDs) != 0 {
+		t.Fatalf("index not deleted: %v", res.IDs)
+	}
+	if len(router.unlinked) != 1 || router.unlinked[0] != "D1" {
+		t.Fatalf("unlinked=%v", router.unlinked)
+	}
+	if _, err := mgr.Get(ctx, "user", "D1"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("get after delete err=%v", err)
+	}
+}
+
+func TestDataLifecycle_SoftDeleteAndPurge(t *testing.T) {
+	ctx := context.Background()
+	hot := store.NewMemoryStore()
+	_ = hot.PutRecord(ctx, &facade.SaveRecord{TypeName: "user", ID: "S1", Payload: []byte("v")})
+	_ = hot.PutRecord(ctx, &facade.SaveRecord{TypeName: "user", ID: "S2", Payload: []byte("v")})
+	mgr := newLifecycleMgr(hot)
+	lc := NewDataLifecycle(mgr, hot, WithSoftDeleteRetention(time.Hour), WithLifecycleInterval(0))
+	defer lc.Close()
+
+	_ = lc.Delete(ctx, "user", "S1")
+	if _, err := mgr.Get(ctx, "user", "S1"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("soft-deleted entity loadable: %v", err)
+	}
+	if err := lc.Undelete(ctx, "user", "S1"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := mgr.Exists(ctx, "user", "S1"); !ok {
+		t.Fatalf("undeleted entity not reloaded")
+	}
+
+	_ = lc.Delete(ctx, "user", "S2")
+	_ = hot.MarkDeleted(ctx, "user", "S2", time.Now().Add(-time.Second).UnixMilli()) // 保留期已过
+	if _, purged, err := lc.RunOnce(ctx); err != nil || purged != 1 {
+		t.Fatalf("purged=%d err=%v", purged, err)
+	}
+	if err := hot.Undelete(ctx, "user", "S2"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("purged entity still recoverable: %v", err)
+	}
+}
+
+func TestDataLifecycle_ArchiveAndRestore(t *testing.T) {
+	ctx := context.Background()
+	hot, cold := store.NewMemoryStore(), store.NewMemoryStore()
+	_ = hot.PutRecord(ctx, &facade.SaveRecord{TypeName: "user", ID: "A1", Payload: []byte("old")})
+	_ = hot.PutRecord(ctx, &facade.SaveRecord{TypeName: "user", ID: "A2", Payload: []byte("old")})
+	mgr := newLifecycleMgr(hot)
+	lc := NewDataLifecycle(mgr, hot, WithColdStorage(cold), WithArchiveAfter(time.Nanosecond, "user"), WithLifecycleInterval(0))
+	defer lc.Close()
+
+	// A2 在内存中：不归档
+	if _, err := mgr.Get(ctx, "user", "A2"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	archived, _, err := lc.RunOnce(ctx)
+	if err != nil || archived != 1 || hasRecord(hot, "user", "A1") || !hasRecord(cold, "user", "A1") || !hasRecord(hot, "user", "A2") {
+		t.Fatalf("archived=%d err=%v", archived, err)
+	}
+
+	// 下次 Get 透明恢复
+	e, err := mgr.Get(ctx, "user", "A1")
+	if err != nil {
+		t.Fatalf("get archived entity: %v", err)
+	}
+	if e.(*wbEntity).value != "old" || !hasRecord(hot, "user", "A1") || hasRecord(cold, "user", "A1") {
+		t.Fatalf("restore failed: value=%q", e.(*wbEntity).value)
+	}
+	if st := lc.Stats(); st.Archived != 1 || st.Restored != 1 {
+		t.Fatalf("stats=%+v", st)
+	}
+}
+
+func TestDataLifecycle_ArchiveExcludesLoad(t *testing.T) {
+	ctx := context.Background()
+	hot, cold := store.NewMemoryStore(), store.NewMemoryStore()
+	_ = hot.PutRecord(ctx, &facade.SaveRecord{TypeName: "user", ID: "A1", Payload: []byte("old")})
+	mgr := newLifecycleMgr(hot)
+	lc := NewDataLifecycle(mgr, hot, WithColdStorage(cold), WithLifecycleInterval(0))
+	defer lc.Close()
+
+	// 模拟加载进行中：持有键锁期间实体进入内存，归档需等待并随后跳过
+	lk := mgr.lockKey("user", "A1")
+	type result struct {
+		ok  bool
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		ok, err := lc.Archive(ctx, "user", "A1")
+		done <- result{ok, err}
+	}()
+	select {
+	case r := <-done:
+		t.Fatalf("archive ran while key locked: %+v", r)
+	case <-time.After(20 * time.Millisecond):
+	}
+	mgr.insert("user", "A1", &wbEntity{})
+	mgr.unlockKey(lk)
+
+	r := <-done
+	if r.err != nil || r.ok || !hasRecord(hot, "user", "A1") || hasRecord(cold, "user", "A1") {
+		t.Fatalf("resident entity archived: %+v", r)
+	}
+}
+
+func TestDataLifecycle_DeleteDuringFailingFlush(t *testing.T) {
+	ctx := context.Background()
+	hot := store.NewMemoryStore()
+	_ = hot.PutRecord(ctx, &facade.SaveRecord{TypeName: "user", ID: "D2", Payload: []byte("v")})
+	mgr := newLifecycleMgr(hot)
+	bs := newFakeBatchStore()
+	bs.fail["user/D2"] = 1
+	var dead int
+	wb := newTestWriteBehind(t, bs, WithWriteBehindInterval(time.Hour),
+		WithWriteBehindDeadLetter(func(rec *facade.SaveRecord, err error) { dead++ }))
+	defer wb.Close(ctx)
+	mgr.SetSaveSink(wb)
+	lc := NewDataLifecycle(mgr, hot, WithLifecycleInterval(0))
+	defer lc.Close()
+
+	// 快照在途时删除实体：在途写入失败后不得重试写回
+	wb.Submit(&facade.SaveRecord{TypeName: "user", ID: "D2", Payload: []byte("stale")})
+	batch := wb.takeBatch(time.Time{})
+	if err := lc.Delete(ctx, "user", "D2"); err != nil {
+		t.Fatal(err)
+	}
+	wb.flushBatch(batch)
+	if err := wb.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if bs.row("user/D2") != nil || dead != 0 {
+		t.Fatalf("deleted entity written back: row=%v dead=%d", bs.row("user/D2"), dead)
+	}
+	if st := wb.Stats(); st.Pending != 0 || st.InFlight != 0 {
+		t.Fatalf("stats=%+v", st)
+	}
+}
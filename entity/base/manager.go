@@ -2,7 +2,12 @@ package base
 
 import (
 	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	facade "github.com/go-kratos/kratos/v2/entity/facade"
@@ -10,44 +15,52 @@ import (
 
 // MemoryManager 提供最小可用的内存版 EntityMgr 实现
 // 功能点：
-// - 内存索引：type -> id -> entity
+// - 内存索引：按 hash(type, id) 分片，分片内 type -> id -> record（实体 + 元数据）
 // - NotFoundHook：miss 时按 type 构建
 // - ReleaseAll：清空内存实体
 // - IsAllLanded：若存在 SaveAble 实体未落地（脏），返回 false，否则 true
-// - TTL 卸载与周期保存（可选，基于 Option 配置）
+// - 生命周期事件：通过 Events()/Subscribe 订阅，支持多订阅者、同步/异步投递与按类型过滤
+// - TTL 卸载与周期保存（可选，基于 Option 配置；分层时间轮，毫秒精度，保存带抖动）
+//
+// 锁约定：mu 仅保护配置、钩子注册与时间轮引用；实体读写只持对应分片锁，
+// Get 命中路径为分片读锁 + 原子续期，不触碰全局写锁。
 
 type MemoryManager struct {
 	mu            sync.RWMutex
-	entities      map[string]map[string]facade.Entity // type -> id -> entity
+	shards        []*mmShard
 	notFoundHooks map[string]func(ctx context.Context, id string) (facade.Entity, error)
 
-	// ability hooks
+	// ability hooks（按能力名登记；hooks 为锁外读取的扁平快照）
 	createProcesses   map[string][]func(ctx context.Context, e facade.Entity)
 	addProcesses      map[string][]func(ctx context.Context, e facade.Entity)
 	getProcesses      map[string][]func(ctx context.Context, e facade.Entity)
 	removeProcesses   map[string][]func(ctx context.Context, e facade.Entity)
 	beforeAddHandlers map[string][]func(ctx context.Context, e facade.Entity) (facade.Ability, error)
+	hooks             atomic.Pointer[processHooks]
 
-	// on remove callback (optional)
-	onEntityRemoved func(entityType, id string)
+	// 生命周期事件总线（创建/加载/访问/保存/卸载/移除/销毁）
+	events *EventBus
 
-	// key-level locker to merge concurrent operations on same type/id
-	keyMu    sync.Mutex
-	keyLocks map[string]*sync.Mutex
+	// 保存管道（可选）；为 nil 时到期实体在后台协程内同步 Save
+	saveSink SaveSink
 
-	// --- 以下为 TTL/Save 配置与运行时元数据 ---
-	opts mmOptions
+	// 实体组：type -> 组（写时复制，锁外只读）
+	groups map[string]*entityGroup
+
+	// 二级索引：type -> field -> 索引类型（写时复制）；索引存储（可选）
+	indexes    map[string]map[string]facade.IndexKind
+	indexStore facade.IndexStorage
 
-	// meta: 记录每个实体的最近访问与保存时间
-	meta map[string]map[string]entityMeta // type -> id -> meta
+	// 归档恢复（可选）：NotFoundHook 未找到时从冷存储恢复后重试一次
+	restorer func(ctx context.Context, entityType, id string) (bool, error)
+
+	// --- 以下为 TTL/Save 配置与运行时元数据 ---
+	opts      mmOptions
+	keepAlive atomic.Bool // opts.KeepAliveOnGet && opts.ttlEnabled() 的无锁副本（Get 热路径）
 
-	// 分桶时间轮（固定槽位，键为 type/id）
-	ttlBuckets     []map[string]struct{}
-	saveBuckets    []map[string]struct{}
-	ttlIndexByKey  map[string]int // key->slot
-	saveIndexByKey map[string]int // key->slot
-	ttlIdx         int
-	saveIdx        int
+	// 分层时间轮（键为 type/id）
+	ttlWheel  *timingWheel
+	saveWheel *timingWheel
 
 	// 后台推进
 	ttlTicker  *time.Ticker
@@ -55,6 +68,15 @@ type MemoryManager struct {
 	stopCh     chan struct{}
 }
 
+// processHooks 钩子快照（注册时重建，运行时无锁读取）
+type processHooks struct {
+	create    []func(ctx context.Context, e facade.Entity)
+	add       []func(ctx context.Context, e facade.Entity)
+	get       []func(ctx context.Context, e facade.Entity)
+	remove    []func(ctx context.Context, e facade.Entity)
+	beforeAdd []func(ctx context.Context, e facade.Entity) (facade.Ability, error)
+}
+
 // --- Options 定义 ---
 
 type mmOptions struct {
@@ -62,7 +84,21 @@ type mmOptions struct {
 	CacheTTLMillis   int64
 	SavePeriodMillis int64
 	DestroyOnUnload  bool
-	BucketSlots      int
+	BucketSlots      int     // 时间轮每层槽位数
+	WheelTickMillis  int64   // 时间轮最小精度（毫秒）
+	WheelLevels      int     // 时间轮层数
+	WheelShards      int     // 时间轮键索引分片数
+	SaveJitterRatio  float64 // 周期保存抖动比例：实际周期 = period + rand[0, period*ratio]
+	ShardCount       int     // 实体索引分片数（仅构造时生效）
+
+	// 按实体类型覆盖 TTL/保存周期；未出现的类型沿用全局值
+	TypeOptions map[string]TypeOptions
+}
+
+// TypeOptions 单个实体类型的 TTL/保存配置（整体替换全局值）
+type TypeOptions struct {
+	CacheTTLMillis   int64
+	SavePeriodMillis int64
 }
 
 type Option func(*mmOptions)
@@ -72,6 +108,65 @@ func WithCacheTTLMillis(v int64) Option   { return func(o *mmOptions) { o.CacheT
 func WithSavePeriodMillis(v int64) Option { return func(o *mmOptions) { o.SavePeriodMillis = v } }
 func WithDestroyOnUnload(v bool) Option   { return func(o *mmOptions) { o.DestroyOnUnload = v } }
 func WithBucketSlots(v int) Option        { return func(o *mmOptions) { o.BucketSlots = v } }
+func WithWheelTickMillis(v int64) Option  { return func(o *mmOptions) { o.WheelTickMillis = v } }
+func WithWheelLevels(v int) Option        { return func(o *mmOptions) { o.WheelLevels = v } }
+func WithWheelShards(v int) Option        { return func(o *mmOptions) { o.WheelShards = v } }
+func WithShardCount(v int) Option         { return func(o *mmOptions) { o.ShardCount = v } }
+func WithSaveJitterRatio(v float64) Option {
+	return func(o *mmOptions) { o.SaveJitterRatio = v }
+}
+
+// WithTypeOptions 整体替换按类型覆盖表（nil 表示清空）
+func WithTypeOptions(v map[string]TypeOptions) Option {
+	return func(o *mmOptions) {
+		o.TypeOptions = make(map[string]TypeOptions, len(v))
+		for t, to := range v {
+			o.TypeOptions[t] = to
+		}
+	}
+}
+
+// ttlFor 返回某类型生效的 TTL
+func (o *mmOptions) ttlFor(entityType string) int64 {
+	if to, ok := o.TypeOptions[entityType]; ok {
+		return to.CacheTTLMillis
+	}
+	return o.CacheTTLMillis
+}
+
+// savePeriodFor 返回某类型生效的保存周期
+func (o *mmOptions) savePeriodFor(entityType string) int64 {
+	if to, ok := o.TypeOptions[entityType]; ok {
+		return to.SavePeriodMillis
+	}
+	return o.SavePeriodMillis
+}
+
+// ttlEnabled 全局或任一类型开启了 TTL
+func (o *mmOptions) ttlEnabled() bool {
+	if o.CacheTTLMillis > 0 {
+		return true
+	}
+	for _, to := range o.TypeOptions {
+		if to.CacheTTLMillis > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// saveEnabled 全局或任一类型开启了周期保存
+func (o *mmOptions) saveEnabled() bool {
+	if o.SavePeriodMillis > 0 {
+		return true
+	}
+	for _, to := range o.TypeOptions {
+		if to.SavePeriodMillis > 0 {
+			return true
+		}
+	}
+	return false
+}
 
 func defaultMMOptions() mmOptions {
 	return mmOptions{
@@ -80,6 +175,11 @@ func defaultMMOptions() mmOptions {
 		SavePeriodMillis: 0,
 		DestroyOnUnload:  false,
 		BucketSlots:      60,
+		WheelTickMillis:  10,
+		WheelLevels:      4,
+		WheelShards:      64,
+		SaveJitterRatio:  0.1,
+		ShardCount:       32,
 	}
 }
 
@@ -89,28 +189,32 @@ func NewMemoryManager(opts ...Option) *MemoryManager {
 	for _, fn := range opts {
 		fn(&opt)
 	}
+	if opt.ShardCount <= 0 {
+		opt.ShardCount = 1
+	}
 
 	m := &MemoryManager{
-		entities:          make(map[string]map[string]facade.Entity),
+		shards:            make([]*mmShard, opt.ShardCount),
 		notFoundHooks:     make(map[string]func(ctx context.Context, id string) (facade.Entity, error)),
 		createProcesses:   make(map[string][]func(ctx context.Context, e facade.Entity)),
 		addProcesses:      make(map[string][]func(ctx context.Context, e facade.Entity)),
 		getProcesses:      make(map[string][]func(ctx context.Context, e facade.Entity)),
 		removeProcesses:   make(map[string][]func(ctx context.Context, e facade.Entity)),
 		beforeAddHandlers: make(map[string][]func(ctx context.Context, e facade.Entity) (facade.Ability, error)),
-		keyLocks:          make(map[string]*sync.Mutex),
 		opts:              opt,
-		meta:              make(map[string]map[string]entityMeta),
-		ttlIndexByKey:     make(map[string]int),
-		saveIndexByKey:    make(map[string]int),
-		stopCh:            make(chan struct{}),
+		events:            NewEventBus(),
+	}
+	for i := range m.shards {
+		m.shards[i] = newMMShard()
 	}
+	m.hooks.Store(&processHooks{})
+	m.keepAlive.Store(opt.KeepAliveOnGet && opt.ttlEnabled())
 	m.initBucketsLocked()
 	m.startBackgroundLocked()
 	return m
 }
 
-// ApplyOptions 动态更新配置（线程安全）：启停后台任务并在必要时重建桶
+// ApplyOptions 动态更新配置（线程安全）：启停后台任务并在必要时重建时间轮
 func (m *MemoryManager) ApplyOptions(opts ...Option) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -118,52 +222,99 @@ func (m *MemoryManager) ApplyOptions(opts ...Option) {
 	for _, fn := range opts {
 		fn(&opt)
 	}
-	needRebuild := opt.BucketSlots <= 0 || opt.BucketSlots != m.opts.BucketSlots
+	// 分片数仅构造时生效
+	opt.ShardCount = m.opts.ShardCount
+	needRebuild := opt.BucketSlots <= 0 || opt.BucketSlots != m.opts.BucketSlots ||
+		opt.WheelTickMillis != m.opts.WheelTickMillis || opt.WheelLevels != m.opts.WheelLevels ||
+		opt.WheelShards != m.opts.WheelShards
 	m.opts = opt
+	m.keepAlive.Store(opt.KeepAliveOnGet && opt.ttlEnabled())
 	if needRebuild {
 		m.resetBucketsLocked()
 	}
 	m.restartBackgroundLocked()
 
-	// 重新将已存在的实体入桶以应用新的配置
+	// 重新将已存在的实体入轮以应用新的配置
 	m.rebucketExistingEntitiesLocked()
 }
 
-// SetOnEntityRemoved 注入实体移除回调（可选）
-func (m *MemoryManager) SetOnEntityRemoved(fn func(entityType, id string)) {
+// ApplyConfig 将 facade.Config 中的 TTL/保存/KeepAlive 及按类型覆盖应用到当前管理器
+func (m *MemoryManager) ApplyConfig(cfg facade.Config) {
+	types := make(map[string]TypeOptions, len(cfg.Types))
+	for _, t := range cfg.TypeNames() {
+		types[t] = TypeOptions{
+			CacheTTLMillis:   cfg.CacheTTLFor(t),
+			SavePeriodMillis: cfg.SavePeriodFor(t),
+		}
+	}
+	m.ApplyOptions(
+		WithCacheTTLMillis(cfg.CacheTTLMillis),
+		WithSavePeriodMillis(cfg.SavePeriodMillis),
+		WithKeepAliveOnGet(cfg.KeepAliveOnGet),
+		WithTypeOptions(types),
+	)
+}
+
+// Events 返回生命周期事件总线
+func (m *MemoryManager) Events() *EventBus { return m.events }
+
+// Subscribe 订阅生命周期事件（等价于 Events().Subscribe）
+// 实体移出内存的清理（如路由解绑）需同时关心 EventRemoved 与 EventEvicted。
+func (m *MemoryManager) Subscribe(fn facade.EventHandler, opts ...SubscribeOption) *Subscription {
+	return m.events.Subscribe(fn, opts...)
+}
+
+// emit 发布事件；无订阅者关心时不构造事件
+func (m *MemoryManager) emit(ctx context.Context, kind facade.EventKind, entityType, id string, e facade.Entity) {
+	if !m.events.Enabled(kind) {
+		return
+	}
+	m.events.Publish(ctx, facade.EntityEvent{Kind: kind, EntityType: entityType, ID: id, Entity: e, TimeMs: time.Now().UnixMilli()})
+}
+
+// SetSaveSink 设置保存管道（如 WriteBehind）；nil 恢复为后台同步 Save
+func (m *MemoryManager) SetSaveSink(sink SaveSink) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.onEntityRemoved = fn
+	m.saveSink = sink
 }
 
 func makeKey(entityType, id string) string { return entityType + "/" + id }
 
-func (m *MemoryManager) lockKey(entityType, id string) *sync.Mutex {
-	key := makeKey(entityType, id)
-	m.keyMu.Lock()
-	lk, ok := m.keyLocks[key]
-	if !ok {
-		lk = &sync.Mutex{}
-		m.keyLocks[key] = lk
+// splitKey 将 type/id 形式的键拆分（id 中允许出现 '/'）
+func splitKey(key string) (entityType, id string) {
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		return key[:i], key[i+1:]
 	}
-	m.keyMu.Unlock()
-	lk.Lock()
-	return lk
+	return key, ""
+}
+
+// shardOf 返回 type/id 所在分片
+func (m *MemoryManager) shardOf(entityType, id string) *mmShard {
+	return m.shards[shardHash(entityType, id)%uint32(len(m.shards))]
 }
 
-func (m *MemoryManager) unlockKey(lk *sync.Mutex) {
-	lk.Unlock()
+func (m *MemoryManager) lockKey(entityType, id string) *keyLock {
+	return m.shardOf(entityType, id).acquire(makeKey(entityType, id))
+}
+
+func (m *MemoryManager) unlockKey(lk *keyLock) {
+	lk.release()
+}
+
+// lookup 在分片读锁下查找实体
+func (m *MemoryManager) lookup(entityType, id string) (*entityRecord, bool) {
+	sh := m.shardOf(entityType, id)
+	sh.mu.RLock()
+	rec := sh.getLocked(entityType, id)
+	sh.mu.RUnlock()
+	return rec, rec != nil
 }
 
 // Exists: 是否存在（内存或存储）。当前仅内存
 func (m *MemoryManager) Exists(ctx context.Context, entityType, id string) (bool, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	if mm := m.entities[entityType]; mm != nil {
-		_, ok := mm[id]
-		return ok, nil
-	}
-	return false, nil
+	_, ok := m.lookup(entityType, id)
+	return ok, nil
 }
 
 // Create: 创建并初始化实体（不落地）
@@ -185,34 +336,23 @@ func (m *MemoryManager) Create(ctx context.Context, entityType, id string, ctor
 	// add hooks (lock-free)
 	m.runAdd(ctx, inst)
 
-	m.mu.Lock()
-	mm := m.entities[entityType]
-	if mm == nil {
-		mm = make(map[string]facade.Entity)
-		m.entities[entityType] = mm
-	}
-	mm[id] = inst
-	m.onCreatedLocked(entityType, id)
-	m.mu.Unlock()
+	m.insert(entityType, id, inst)
 
 	// create hooks (lock-free)
 	m.runCreate(ctx, inst)
+	m.emit(ctx, facade.EventCreated, entityType, id, inst)
 	return inst, nil
 }
 
 // Get: 获取实体（未命中尝试 NotFoundHook）
 func (m *MemoryManager) Get(ctx context.Context, entityType, id string) (facade.Entity, error) {
-	m.mu.RLock()
-	if mm := m.entities[entityType]; mm != nil {
-		if e, ok := mm[id]; ok {
-			m.mu.RUnlock()
-			// get hooks on hit (lock-free)
-			m.runGet(ctx, e)
-			m.touchOnGet(entityType, id)
-			return e, nil
-		}
+	if rec, ok := m.lookup(entityType, id); ok {
+		// get hooks on hit (lock-free)
+		m.runGet(ctx, rec.entity)
+		m.touchOnGet(rec)
+		m.emit(ctx, facade.EventAccessed, entityType, id, rec.entity)
+		return rec.entity, nil
 	}
-	m.mu.RUnlock()
 	//todo 从storage中加载
 	// miss: merge concurrent load/create via key-level lock
 	return m.loadOrCreateWithHook(ctx, entityType, id)
@@ -220,14 +360,9 @@ func (m *MemoryManager) Get(ctx context.Context, entityType, id string) (facade.
 
 // GetNoKeepAlive: 获取实体（不续期、不置脏、不触发 get 流程钩子）
 func (m *MemoryManager) GetNoKeepAlive(ctx context.Context, entityType, id string) (facade.Entity, error) {
-	m.mu.RLock()
-	if mm := m.entities[entityType]; mm != nil {
-		if e, ok := mm[id]; ok {
-			m.mu.RUnlock()
-			return e, nil
-		}
+	if rec, ok := m.lookup(entityType, id); ok {
+		return rec.entity, nil
 	}
-	m.mu.RUnlock()
 	// miss path same as Get but without get hook on hit, which we already avoided
 	return m.loadOrCreateWithHook(ctx, entityType, id)
 }
@@ -242,66 +377,63 @@ func (m *MemoryManager) GetOrCreate(ctx context.Context, entityType, id string,
 
 // Remove: 仅移出内存（不删除持久化数据）
 func (m *MemoryManager) Remove(ctx context.Context, entityType, id string) error {
-	removed, onRemoved := m.removeInternal(ctx, entityType, id)
+	removed := m.removeInternal(ctx, entityType, id)
 	if removed != nil {
 		// remove hooks (lock-free)
 		m.runRemove(ctx, removed)
-		// upper-layer cleanup (optional)
-		if onRemoved != nil {
-			onRemoved(entityType, id)
-		}
+		m.emit(ctx, facade.EventRemoved, entityType, id, removed)
 	}
 	return nil
 }
 
 // DestroyAllType: 同一 id 的不同 type 都销毁（仅内存）
 func (m *MemoryManager) DestroyAllType(ctx context.Context, id string) error {
-	var removedList []struct {
-		entityType string
-		entity     facade.Entity
+	for _, t := range m.loadedTypes(id) {
+		_ = m.Remove(ctx, t, id)
 	}
-	m.mu.Lock()
-	for t, mm := range m.entities {
-		if e, ok := mm[id]; ok {
-			removedList = append(removedList, struct {
-				entityType string
-				entity     facade.Entity
-			}{entityType: t, entity: e})
-			delete(mm, id)
-			m.entities[t] = mm
-			m.onRemovedLocked(t, id)
-		}
-	}
-	onRemoved := m.onEntityRemoved
-	m.mu.Unlock()
+	return nil
+}
 
-	for _, it := range removedList {
-		m.runRemove(ctx, it.entity)
-		if onRemoved != nil {
-			onRemoved(it.entityType, id)
+// loadedTypes 返回该 id 在内存中存在的全部 type（分片按 (type, id) 计算，需逐个分片收集）
+func (m *MemoryManager) loadedTypes(id string) []string {
+	var types []string
+	for _, sh := range m.shards {
+		sh.mu.RLock()
+		for t, mm := range sh.records {
+			if _, ok := mm[id]; ok {
+				types = append(types, t)
+			}
 		}
+		sh.mu.RUnlock()
 	}
-	return nil
+	return types
 }
 
 // ReleaseAll: 释放全部实体（等待落地与路由缓存过期）。当前清空内存并按默认仅回调策略清理
 func (m *MemoryManager) ReleaseAll(ctx context.Context) error {
+	type removedKey struct {
+		entityType, id string
+		e              facade.Entity
+	}
+	var removed []removedKey
+	for _, sh := range m.shards {
+		sh.mu.Lock()
+		for t, mm := range sh.records {
+			for id, rec := range mm {
+				removed = append(removed, removedKey{t, id, rec.entity})
+			}
+		}
+		sh.records = make(map[string]map[string]*entityRecord)
+		sh.mu.Unlock()
+	}
 	m.mu.Lock()
-	old := m.entities
-	m.entities = make(map[string]map[string]facade.Entity)
-	onRemoved := m.onEntityRemoved
-	// 清理所有 meta 与桶
-	m.meta = make(map[string]map[string]entityMeta)
+	// 清理时间轮
 	m.resetBucketsLocked()
 	m.mu.Unlock()
 
-	// 默认策略：不触发 remove hooks，不调用 Destroy，仅上层回调
-	if onRemoved != nil {
-		for t, mm := range old {
-			for id := range mm {
-				onRemoved(t, id)
-			}
-		}
+	// 默认策略：不触发 remove hooks，不调用 Destroy，仅发布移除事件
+	for _, k := range removed {
+		m.emit(ctx, facade.EventRemoved, k.entityType, k.id, k.e)
 	}
 	return nil
 }
@@ -309,20 +441,59 @@ func (m *MemoryManager) ReleaseAll(ctx context.Context) error {
 // IsAllLanded: 是否所有可存储实体已落地。
 // 当前遍历内存实体，若发现实现了 SaveAble 且 IsDirty() 为 true，则返回 false
 func (m *MemoryManager) IsAllLanded(ctx context.Context) (bool, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	for _, mm := range m.entities {
-		for _, e := range mm {
-			if s, ok := e.(facade.SaveAble); ok {
-				if s.IsDirty() {
-					return false, nil
+	for _, sh := range m.shards {
+		sh.mu.RLock()
+		for _, mm := range sh.records {
+			for _, rec := range mm {
+				if s, ok := rec.entity.(facade.SaveAble); ok {
+					if s.IsDirty() {
+						sh.mu.RUnlock()
+						return false, nil
+					}
 				}
 			}
 		}
+		sh.mu.RUnlock()
 	}
 	return true, nil
 }
 
+// Len 返回内存中的实体数量
+func (m *MemoryManager) Len() int {
+	n := 0
+	for _, sh := range m.shards {
+		sh.mu.RLock()
+		for _, mm := range sh.records {
+			n += len(mm)
+		}
+		sh.mu.RUnlock()
+	}
+	return n
+}
+
+// Range 遍历内存中的实体快照（锁外回调，fn 返回 false 时停止）
+func (m *MemoryManager) Range(fn func(entityType, id string, e facade.Entity) bool) {
+	type item struct {
+		entityType, id string
+		e              facade.Entity
+	}
+	var items []item
+	for _, sh := range m.shards {
+		sh.mu.RLock()
+		for t, mm := range sh.records {
+			for id, rec := range mm {
+				items = append(items, item{t, id, rec.entity})
+			}
+		}
+		sh.mu.RUnlock()
+	}
+	for _, it := range items {
+		if !fn(it.entityType, it.id, it.e) {
+			return
+		}
+	}
+}
+
 // RegisterNotFoundHook: miss 钩子
 func (m *MemoryManager) RegisterNotFoundHook(entityType string, fn func(ctx context.Context, id string) (facade.Entity, error)) {
 	m.mu.Lock()
@@ -335,6 +506,7 @@ func (m *MemoryManager) RegisterCreateProcess(abilityName string, consumer func(
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.createProcesses[abilityName] = append(m.createProcesses[abilityName], consumer)
+	m.rebuildHooksLocked()
 }
 
 // RegisterAddProcess: 注册按能力的添加流程钩子
@@ -342,6 +514,7 @@ func (m *MemoryManager) RegisterAddProcess(abilityName string, consumer func(ctx
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.addProcesses[abilityName] = append(m.addProcesses[abilityName], consumer)
+	m.rebuildHooksLocked()
 }
 
 // RegisterGetProcess: 注册按能力的获取流程钩子
@@ -349,6 +522,7 @@ func (m *MemoryManager) RegisterGetProcess(abilityName string, consumer func(ctx
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.getProcesses[abilityName] = append(m.getProcesses[abilityName], consumer)
+	m.rebuildHooksLocked()
 }
 
 // RegisterRemoveProcess: 注册按能力的移除流程钩子
@@ -356,6 +530,7 @@ func (m *MemoryManager) RegisterRemoveProcess(abilityName string, consumer func(
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.removeProcesses[abilityName] = append(m.removeProcesses[abilityName], consumer)
+	m.rebuildHooksLocked()
 }
 
 // RegisterBeforeAddProcess: 注册按能力的前置添加流程钩子
@@ -363,6 +538,31 @@ func (m *MemoryManager) RegisterBeforeAddProcess(abilityName string, fun func(ct
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.beforeAddHandlers[abilityName] = append(m.beforeAddHandlers[abilityName], fun)
+	m.rebuildHooksLocked()
+}
+
+// rebuildHooksLocked 按能力名排序重建钩子快照
+func (m *MemoryManager) rebuildHooksLocked() {
+	m.hooks.Store(&processHooks{
+		create:    flattenHooks(m.createProcesses),
+		add:       flattenHooks(m.addProcesses),
+		get:       flattenHooks(m.getProcesses),
+		remove:    flattenHooks(m.removeProcesses),
+		beforeAdd: flattenHooks(m.beforeAddHandlers),
+	})
+}
+
+func flattenHooks[F any](byAbility map[string][]F) []F {
+	names := make([]string, 0, len(byAbility))
+	for name := range byAbility {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var out []F
+	for _, name := range names {
+		out = append(out, byAbility[name]...)
+	}
+	return out
 }
 
 // internal helper: miss path loader with NotFoundHook under key lock
@@ -371,13 +571,10 @@ func (m *MemoryManager) loadOrCreateWithHook(ctx context.Context, entityType, id
 	defer m.unlockKey(lk)
 
 	// double-check after acquiring key lock
-	m.mu.RLock()
-	if mm := m.entities[entityType]; mm != nil {
-		if e, ok := mm[id]; ok {
-			m.mu.RUnlock()
-			return e, nil
-		}
+	if rec, ok := m.lookup(entityType, id); ok {
+		return rec.entity, nil
 	}
+	m.mu.RLock()
 	hook := m.notFoundHooks[entityType]
 	m.mu.RUnlock()
 	if hook == nil {
@@ -385,6 +582,9 @@ func (m *MemoryManager) loadOrCreateWithHook(ctx context.Context, entityType, id
 	}
 
 	inst, err := hook(ctx, id)
+	if (err == nil && inst == nil) || errors.Is(err, facade.ErrNotFound) {
+		inst, err = m.restoreAndLoad(ctx, hook, entityType, id)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -400,75 +600,79 @@ func (m *MemoryManager) loadOrCreateWithHook(ctx context.Context, entityType, id
 	// add hooks (lock-free)
 	m.runAdd(ctx, inst)
 
-	m.mu.Lock()
-	mm := m.entities[entityType]
-	if mm == nil {
-		mm = make(map[string]facade.Entity)
-		m.entities[entityType] = mm
-	}
-	mm[id] = inst
-	m.onCreatedLocked(entityType, id)
-	m.mu.Unlock()
+	m.insert(entityType, id, inst)
+	m.emit(ctx, facade.EventLoaded, entityType, id, inst)
 	return inst, nil
 }
 
-// internal hook runners
-func (m *MemoryManager) runCreate(ctx context.Context, e facade.Entity) {
+// restoreAndLoad 实体已被归档时透明恢复并重新加载；未归档返回 ErrNotFound
+func (m *MemoryManager) restoreAndLoad(ctx context.Context, hook func(ctx context.Context, id string) (facade.Entity, error), entityType, id string) (facade.Entity, error) {
 	m.mu.RLock()
-	var fns []func(ctx context.Context, e facade.Entity)
-	for _, list := range m.createProcesses {
-		fns = append(fns, list...)
+	restorer := m.restorer
+	m.mu.RUnlock()
+	if restorer == nil {
+		return nil, facade.ErrNotFound
 	}
+	restored, err := restorer(ctx, entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, facade.ErrNotFound
+	}
+	return hook(ctx, id)
+}
+
+// SetRestorer 设置归档恢复函数（如 DataLifecycle.Restore）；返回 true 表示已恢复到热存储
+func (m *MemoryManager) SetRestorer(fn func(ctx context.Context, entityType, id string) (bool, error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restorer = fn
+}
+
+// insert 写入分片并初始化元数据与定时器
+func (m *MemoryManager) insert(entityType, id string, inst facade.Entity) {
+	now := time.Now().UnixMilli()
+	rec := &entityRecord{entity: inst}
+	rec.lastAccessMs.Store(now)
+	sh := m.shardOf(entityType, id)
+	sh.mu.Lock()
+	sh.putLocked(entityType, id, rec)
+	sh.mu.Unlock()
+
+	m.mu.RLock()
+	m.rebucketTTLLocked(entityType, id, now)
+	m.rebucketSaveLocked(entityType, id, now)
 	m.mu.RUnlock()
-	for _, fn := range fns {
+}
+
+// internal hook runners
+func (m *MemoryManager) runCreate(ctx context.Context, e facade.Entity) {
+	for _, fn := range m.hooks.Load().create {
 		fn(ctx, e)
 	}
 }
 
 func (m *MemoryManager) runAdd(ctx context.Context, e facade.Entity) {
-	m.mu.RLock()
-	var fns []func(ctx context.Context, e facade.Entity)
-	for _, list := range m.addProcesses {
-		fns = append(fns, list...)
-	}
-	m.mu.RUnlock()
-	for _, fn := range fns {
+	for _, fn := range m.hooks.Load().add {
 		fn(ctx, e)
 	}
 }
 
 func (m *MemoryManager) runGet(ctx context.Context, e facade.Entity) {
-	m.mu.RLock()
-	var fns []func(ctx context.Context, e facade.Entity)
-	for _, list := range m.getProcesses {
-		fns = append(fns, list...)
-	}
-	m.mu.RUnlock()
-	for _, fn := range fns {
+	for _, fn := range m.hooks.Load().get {
 		fn(ctx, e)
 	}
 }
 
 func (m *MemoryManager) runRemove(ctx context.Context, e facade.Entity) {
-	m.mu.RLock()
-	var fns []func(ctx context.Context, e facade.Entity)
-	for _, list := range m.removeProcesses {
-		fns = append(fns, list...)
-	}
-	m.mu.RUnlock()
-	for _, fn := range fns {
+	for _, fn := range m.hooks.Load().remove {
 		fn(ctx, e)
 	}
 }
 
 func (m *MemoryManager) runBeforeAdd(ctx context.Context, e facade.Entity) {
-	m.mu.RLock()
-	var fns []func(ctx context.Context, e facade.Entity) (facade.Ability, error)
-	for _, list := range m.beforeAddHandlers {
-		fns = append(fns, list...)
-	}
-	m.mu.RUnlock()
-	for _, fn := range fns {
+	for _, fn := range m.hooks.Load().beforeAdd {
 		abi, err := fn(ctx, e)
 		if err != nil {
 			continue
@@ -481,144 +685,79 @@ func (m *MemoryManager) runBeforeAdd(ctx context.Context, e facade.Entity) {
 
 // --- 以下为 TTL/Save 内部实现 ---
 
-type entityMeta struct {
-	lastAccessMs int64
-	lastSaveMs   int64
-}
-
 func (m *MemoryManager) initBucketsLocked() {
 	if m.opts.BucketSlots <= 0 {
 		m.opts.BucketSlots = 60
 	}
-	m.ttlBuckets = make([]map[string]struct{}, m.opts.BucketSlots)
-	for i := range m.ttlBuckets {
-		m.ttlBuckets[i] = make(map[string]struct{})
+	if m.opts.WheelTickMillis <= 0 {
+		m.opts.WheelTickMillis = 10
+	}
+	if m.opts.WheelLevels <= 0 {
+		m.opts.WheelLevels = 4
 	}
-	m.saveBuckets = make([]map[string]struct{}, m.opts.BucketSlots)
-	for i := range m.saveBuckets {
-		m.saveBuckets[i] = make(map[string]struct{})
+	if m.opts.WheelShards <= 0 {
+		m.opts.WheelShards = 64
 	}
-	m.ttlIdx = 0
-	m.saveIdx = 0
-	m.ttlIndexByKey = make(map[string]int)
-	m.saveIndexByKey = make(map[string]int)
+	now := time.Now().UnixMilli()
+	m.ttlWheel = newTimingWheel(now, m.opts.WheelTickMillis, m.opts.BucketSlots, m.opts.WheelLevels, m.opts.WheelShards)
+	m.saveWheel = newTimingWheel(now, m.opts.WheelTickMillis, m.opts.BucketSlots, m.opts.WheelLevels, m.opts.WheelShards)
 }
 
 func (m *MemoryManager) resetBucketsLocked() {
-	m.ttlBuckets = nil
-	m.saveBuckets = nil
-	m.ttlIndexByKey = make(map[string]int)
-	m.saveIndexByKey = make(map[string]int)
 	m.initBucketsLocked()
 }
 
-// 在创建实体后初始化 meta 并入桶
-func (m *MemoryManager) onCreatedLocked(entityType, id string) {
-	if m.meta[entityType] == nil {
-		m.meta[entityType] = make(map[string]entityMeta)
+// touchOnGet 仅刷新最近访问时间；TTL 定时器到期时再按最近访问时间惰性续期
+func (m *MemoryManager) touchOnGet(rec *entityRecord) {
+	if !m.keepAlive.Load() {
+		return
 	}
-	now := time.Now().UnixMilli()
-	m.meta[entityType][id] = entityMeta{lastAccessMs: now, lastSaveMs: 0}
-	m.rebucketTTLLocked(entityType, id, now)
-	m.rebucketSaveLocked(entityType, id, now)
+	rec.lastAccessMs.Store(time.Now().UnixMilli())
 }
 
-// 在移除实体后清理 meta 与桶
-func (m *MemoryManager) onRemovedLocked(entityType, id string) {
-	if mp := m.meta[entityType]; mp != nil {
-		delete(mp, id)
-		if len(mp) == 0 {
-			delete(m.meta, entityType)
-		}
-	}
-	key := makeKey(entityType, id)
-	if idx, ok := m.ttlIndexByKey[key]; ok {
-		delete(m.ttlBuckets[idx], key)
-		delete(m.ttlIndexByKey, key)
-	}
-	if idx, ok := m.saveIndexByKey[key]; ok {
-		delete(m.saveBuckets[idx], key)
-		delete(m.saveIndexByKey, key)
+// rebucketTTLLocked 以 baseMs 为起点重新调度 TTL 到期（调用方持有 mu 读锁或写锁）
+func (m *MemoryManager) rebucketTTLLocked(entityType, id string, baseMs int64) {
+	ttl := m.opts.ttlFor(entityType)
+	if ttl <= 0 {
+		return
 	}
+	m.ttlWheel.schedule(makeKey(entityType, id), baseMs+ttl)
 }
 
-func (m *MemoryManager) touchOnGet(entityType, id string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	if !m.opts.KeepAliveOnGet || m.opts.CacheTTLMillis == 0 {
-		return
-	}
-	if _, ok := m.entities[entityType]; !ok {
+// rebucketSaveLocked 以 baseMs 为起点重新调度下一次保存（叠加随机抖动以打散保存峰值）
+func (m *MemoryManager) rebucketSaveLocked(entityType, id string, baseMs int64) {
+	period := m.opts.savePeriodFor(entityType)
+	if period <= 0 {
 		return
 	}
-	if _, ok := m.entities[entityType][id]; !ok {
-		return
-	}
-	now := time.Now().UnixMilli()
-	mp := m.meta[entityType]
-	if mp == nil {
-		mp = make(map[string]entityMeta)
-		m.meta[entityType] = mp
-	}
-	meta := mp[id]
-	meta.lastAccessMs = now
-	mp[id] = meta
-	m.rebucketTTLLocked(entityType, id, now)
+	m.saveWheel.schedule(makeKey(entityType, id), baseMs+period+m.saveJitterMillis(period))
 }
 
-func (m *MemoryManager) rebucketTTLLocked(entityType, id string, nowMs int64) {
-	if m.opts.CacheTTLMillis == 0 || len(m.ttlBuckets) == 0 {
-		return
+func (m *MemoryManager) saveJitterMillis(period int64) int64 {
+	if m.opts.SaveJitterRatio <= 0 {
+		return 0
 	}
-	key := makeKey(entityType, id)
-	// 移出旧槽
-	if old, ok := m.ttlIndexByKey[key]; ok {
-		delete(m.ttlBuckets[old], key)
-	}
-	// 计算到期时间（毫秒）
-	expireMs := nowMs + m.opts.CacheTTLMillis
-	// 计算到期时间对应的秒数
-	expireSec := expireMs / 1000
-	// 计算当前时间对应的秒数
-	nowSec := nowMs / 1000
-	// 计算目标槽位（相对于当前时间的偏移，以秒为单位）
-	offsetSec := expireSec - nowSec
-	targetSlot := (m.ttlIdx + int(offsetSec)) % len(m.ttlBuckets)
-	m.ttlBuckets[targetSlot][key] = struct{}{}
-	m.ttlIndexByKey[key] = targetSlot
-}
-
-func (m *MemoryManager) rebucketSaveLocked(entityType, id string, nowMs int64) {
-	if m.opts.SavePeriodMillis == 0 || len(m.saveBuckets) == 0 {
-		return
+	span := int64(float64(period) * m.opts.SaveJitterRatio)
+	if span <= 0 {
+		return 0
 	}
-	key := makeKey(entityType, id)
-	if old, ok := m.saveIndexByKey[key]; ok {
-		delete(m.saveBuckets[old], key)
-	}
-	// 计算下次保存时间（毫秒）
-	nextSaveMs := nowMs + m.opts.SavePeriodMillis
-	// 计算下次保存时间对应的秒数
-	nextSaveSec := nextSaveMs / 1000
-	// 计算当前时间对应的秒数
-	nowSec := nowMs / 1000
-	// 计算目标槽位（相对于当前时间的偏移，以秒为单位）
-	offsetSec := nextSaveSec - nowSec
-	targetSlot := (m.saveIdx + int(offsetSec)) % len(m.saveBuckets)
-	m.saveBuckets[targetSlot][key] = struct{}{}
-	m.saveIndexByKey[key] = targetSlot
+	return rand.Int63n(span + 1)
 }
 
 func (m *MemoryManager) startBackgroundLocked() {
-	// TTL 扫描
-	if m.opts.CacheTTLMillis > 0 && m.ttlTicker == nil {
-		m.ttlTicker = time.NewTicker(time.Second)
-		go m.runTTL()
+	if m.stopCh == nil {
+		m.stopCh = make(chan struct{})
 	}
-	// Save 扫描
-	if m.opts.SavePeriodMillis > 0 && m.saveTicker == nil {
-		m.saveTicker = time.NewTicker(time.Second)
-		go m.runSave()
+	interval := time.Duration(m.opts.WheelTickMillis) * time.Millisecond
+	// TTL 推进
+	if m.opts.ttlEnabled() && m.ttlTicker == nil {
+		m.ttlTicker = time.NewTicker(interval)
+		go m.runTTL(m.ttlTicker, m.stopCh)
+	}
+	// Save 推进
+	if m.opts.saveEnabled() && m.saveTicker == nil {
+		m.saveTicker = time.NewTicker(interval)
+		go m.runSave(m.saveTicker, m.stopCh)
 	}
 }
 
@@ -631,9 +770,9 @@ func (m *MemoryManager) stopBackgroundLocked() {
 		m.saveTicker.Stop()
 		m.saveTicker = nil
 	}
-	select {
-	case m.stopCh <- struct{}{}:
-	default:
+	if m.stopCh != nil {
+		close(m.stopCh)
+		m.stopCh = nil
 	}
 }
 
@@ -642,195 +781,218 @@ func (m *MemoryManager) restartBackgroundLocked() {
 	m.startBackgroundLocked()
 }
 
-func (m *MemoryManager) runTTL() {
+func (m *MemoryManager) runTTL(tk *time.Ticker, stop <-chan struct{}) {
 	for {
 		select {
-		case <-m.stopCh:
+		case <-stop:
 			return
-		case <-m.ttlTicker.C:
+		case <-tk.C:
 			m.scanTTLOnce()
 		}
 	}
 }
 
-func (m *MemoryManager) runSave() {
+func (m *MemoryManager) runSave(tk *time.Ticker, stop <-chan struct{}) {
 	for {
 		select {
-		case <-m.stopCh:
+		case <-stop:
 			return
-		case <-m.saveTicker.C:
+		case <-tk.C:
 			m.scanSaveOnce()
 		}
 	}
 }
 
+// scanTTLOnce 推进 TTL 时间轮并处理到期实体
+// 实体组成员以组内最近访问时间判定，到期时整组一起卸载
 func (m *MemoryManager) scanTTLOnce() {
 	now := time.Now().UnixMilli()
-	var keys []string
-	m.mu.Lock()
-	if len(m.ttlBuckets) == 0 {
-		m.mu.Unlock()
-		return
-	}
-	slot := m.ttlIdx % len(m.ttlBuckets)
-	for k := range m.ttlBuckets[slot] {
-		keys = append(keys, k)
-	}
-	// 清空当前槽
-	m.ttlBuckets[slot] = make(map[string]struct{})
-	for _, k := range keys {
-		delete(m.ttlIndexByKey, k)
-	}
-	m.ttlIdx = (m.ttlIdx + 1) % len(m.ttlBuckets)
-	m.mu.Unlock()
+	m.mu.RLock()
+	wheel := m.ttlWheel
+	opts := m.opts
+	groups := m.groups
+	destroy := m.opts.DestroyOnUnload
+	m.mu.RUnlock()
+	keys := wheel.advance(now)
 
 	for _, key := range keys {
-		// 解析 type/id
-		// key 格式: type/id
-		var entityType, id string
-		for i := 0; i < len(key); i++ {
-			if key[i] == '/' {
-				entityType = key[:i]
-				id = key[i+1:]
-				break
-			}
-		}
-		m.mu.RLock()
-		meta, okMeta := m.meta[entityType][id]
-		m.mu.RUnlock()
-		if !okMeta {
+		entityType, id := splitKey(key)
+		members := m.loadedMembers(groups[entityType], entityType, id)
+		if len(members) == 0 {
 			continue
 		}
-		if now-meta.lastAccessMs < m.opts.CacheTTLMillis {
-			// 未到期，重排
-			m.mu.Lock()
-			m.rebucketTTLLocked(entityType, id, now)
-			m.mu.Unlock()
+		var lastAccess int64
+		for _, mem := range members {
+			if la := mem.rec.lastAccessMs.Load(); la > lastAccess {
+				lastAccess = la
+			}
+		}
+		if now-lastAccess < opts.ttlFor(entityType) {
+			// 期间被访问过：按最近访问时间续期
+			m.mu.RLock()
+			m.rebucketTTLLocked(entityType, id, lastAccess)
+			m.mu.RUnlock()
 			continue
 		}
 		// 到期：卸载（复用 Remove 流程），可选 Destroy
 		ctx := context.Background()
-		removed, onRemoved := m.removeInternal(ctx, entityType, id)
-		if removed != nil {
-			m.runRemove(ctx, removed)
-			if onRemoved != nil {
-				onRemoved(entityType, id)
+		for _, mem := range members {
+			removed := m.removeInternal(ctx, mem.entityType, id)
+			if removed == nil {
+				continue
 			}
-			if m.opts.DestroyOnUnload {
+			m.runRemove(ctx, removed)
+			m.emit(ctx, facade.EventEvicted, mem.entityType, id, removed)
+			if destroy {
 				_ = removed.Destroy(ctx)
+				m.emit(ctx, facade.EventDestroyed, mem.entityType, id, removed)
 			}
 		}
 	}
 }
 
+// scanSaveOnce 推进保存时间轮并处理到期实体
+// 实体组任一成员到期时整组一起保存，并统一更新保存时间
 func (m *MemoryManager) scanSaveOnce() {
 	now := time.Now().UnixMilli()
-	var keys []string
-	m.mu.Lock()
-	if len(m.saveBuckets) == 0 {
-		m.mu.Unlock()
-		return
-	}
-	slot := m.saveIdx % len(m.saveBuckets)
-	for k := range m.saveBuckets[slot] {
-		keys = append(keys, k)
-	}
-	m.saveBuckets[slot] = make(map[string]struct{})
-	for _, k := range keys {
-		delete(m.saveIndexByKey, k)
-	}
-	m.saveIdx = (m.saveIdx + 1) % len(m.saveBuckets)
-	m.mu.Unlock()
+	m.mu.RLock()
+	wheel := m.saveWheel
+	opts := m.opts
+	sink := m.saveSink
+	groups := m.groups
+	m.mu.RUnlock()
+	keys := wheel.advance(now)
 
 	for _, key := range keys {
-		var entityType, id string
-		for i := 0; i < len(key); i++ {
-			if key[i] == '/' {
-				entityType = key[:i]
-				id = key[i+1:]
+		entityType, id := splitKey(key)
+		group := groups[entityType]
+		members := m.loadedMembers(group, entityType, id)
+		if len(members) == 0 {
+			continue
+		}
+		needSave := false
+		for _, mem := range members {
+			s, ok := mem.rec.entity.(facade.SaveAble)
+			if !ok {
+				continue
+			}
+			sh := m.shardOf(mem.entityType, id)
+			sh.mu.RLock()
+			lastSave := mem.rec.lastSaveMs
+			sh.mu.RUnlock()
+			period := opts.savePeriodFor(mem.entityType)
+			if s.IsDirty() || (period > 0 && now-lastSave >= period) {
+				needSave = true
 				break
 			}
 		}
+		// 失败：下一轮重试（重排即可）
+		if needSave && m.saveMembers(sink, group, id, members) {
+			for _, mem := range members {
+				sh := m.shardOf(mem.entityType, id)
+				sh.mu.Lock()
+				mem.rec.lastSaveMs = now
+				sh.mu.Unlock()
+			}
+		}
+		// 非 SaveAble / 无需保存 / 保存完成：重排
 		m.mu.RLock()
-		var ent facade.Entity
-		if mm := m.entities[entityType]; mm != nil {
-			ent = mm[id]
+		for _, mem := range members {
+			m.rebucketSaveLocked(mem.entityType, id, now)
 		}
-		meta := m.meta[entityType][id]
 		m.mu.RUnlock()
-		if ent == nil {
-			continue
+	}
+}
+
+// saveMembers 保存一个实体或一整组实体，返回是否全部成功
+// - 设置了保存管道：投递（组且管道支持 GroupSaveSink 时作为一个单元投递），快照与清脏由管道完成
+// - 否则：在后台协程内依次同步 Save，成功后清脏
+func (m *MemoryManager) saveMembers(sink SaveSink, group *entityGroup, id string, members []groupMember) bool {
+	ctx := context.Background()
+	if sink != nil {
+		if gs, ok := sink.(GroupSaveSink); ok && group != nil {
+			es := make([]facade.Entity, len(members))
+			for i, mem := range members {
+				es[i] = mem.rec.entity
+			}
+			return gs.EnqueueGroup(ctx, group.name, id, es) == nil
 		}
-		s, ok := ent.(facade.SaveAble)
+		for _, mem := range members {
+			if _, ok := mem.rec.entity.(facade.SaveAble); !ok {
+				continue
+			}
+			if err := sink.Enqueue(ctx, mem.rec.entity); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+	for _, mem := range members {
+		s, ok := mem.rec.entity.(facade.SaveAble)
 		if !ok {
-			// 非 SaveAble：仅重排
-			m.mu.Lock()
-			m.rebucketSaveLocked(entityType, id, now)
-			m.mu.Unlock()
 			continue
 		}
-		needSave := s.IsDirty() || (m.opts.SavePeriodMillis > 0 && now-meta.lastSaveMs >= m.opts.SavePeriodMillis)
-		if !needSave {
-			m.mu.Lock()
-			m.rebucketSaveLocked(entityType, id, now)
-			m.mu.Unlock()
-			continue
+		if err := s.Save(ctx); err != nil {
+			return false
+		}
+		// 索引失败：下一轮连同数据一起重试（写入需幂等）
+		if err := m.putIndex(ctx, mem.rec.entity); err != nil {
+			return false
 		}
-		if err := s.Save(context.Background()); err == nil {
-			// 成功：清脏并更新 lastSaveMs，再重排
+		// 成功：清脏；增量实体保存期间产生的新变更保留脏标
+		if d, ok := mem.rec.entity.(facade.DeltaSaveObject); !ok || len(d.DirtySections()) == 0 {
 			s.SetDirty(false)
-			m.mu.Lock()
-			mp := m.meta[entityType]
-			if mp == nil {
-				mp = make(map[string]entityMeta)
-				m.meta[entityType] = mp
-			}
-			md := mp[id]
-			md.lastSaveMs = now
-			mp[id] = md
-			m.rebucketSaveLocked(entityType, id, now)
-			m.mu.Unlock()
-		} else {
-			// 失败：下一轮重试（重排即可）
-			m.mu.Lock()
-			m.rebucketSaveLocked(entityType, id, now)
-			m.mu.Unlock()
 		}
+		m.emit(ctx, facade.EventSaved, mem.entityType, id, mem.rec.entity)
 	}
+	return true
 }
 
-// removeInternal: 在锁内删除并返回被移除实体与上层回调
-func (m *MemoryManager) removeInternal(ctx context.Context, entityType, id string) (facade.Entity, func(entityType, id string)) {
-	var removed facade.Entity
-	m.mu.Lock()
-	if mm := m.entities[entityType]; mm != nil {
-		if e, ok := mm[id]; ok {
-			removed = e
-		}
-		delete(mm, id)
-		m.entities[entityType] = mm
+// removeInternal: 在分片锁内删除并返回被移除实体
+func (m *MemoryManager) removeInternal(ctx context.Context, entityType, id string) facade.Entity {
+	sh := m.shardOf(entityType, id)
+	sh.mu.Lock()
+	rec := sh.deleteLocked(entityType, id)
+	sh.mu.Unlock()
+
+	m.mu.RLock()
+	key := makeKey(entityType, id)
+	m.ttlWheel.cancel(key)
+	m.saveWheel.cancel(key)
+	m.mu.RUnlock()
+	if rec == nil {
+		return nil
 	}
-	m.onRemovedLocked(entityType, id)
-	onRemoved := m.onEntityRemoved
-	m.mu.Unlock()
-	return removed, onRemoved
+	return rec.entity
 }
 
-// rebucketExistingEntitiesLocked 将已存在的实体重新入桶以应用新的TTL和保存配置
+// rebucketExistingEntitiesLocked 将已存在的实体重新入轮以应用新的TTL和保存配置
 func (m *MemoryManager) rebucketExistingEntitiesLocked() {
-	for entityType, mm := range m.entities {
-		for id := range mm {
-			if meta, ok := m.meta[entityType][id]; ok {
-				// 重新入TTL桶
-				if m.opts.CacheTTLMillis > 0 {
-					m.rebucketTTLLocked(entityType, id, meta.lastAccessMs)
+	now := time.Now().UnixMilli()
+	for _, sh := range m.shards {
+		sh.mu.RLock()
+		for entityType, mm := range sh.records {
+			for id, rec := range mm {
+				key := makeKey(entityType, id)
+				// 重新入TTL轮
+				if m.opts.ttlFor(entityType) > 0 {
+					m.rebucketTTLLocked(entityType, id, rec.lastAccessMs.Load())
+				} else {
+					m.ttlWheel.cancel(key)
 				}
-				// 重新入保存桶
-				if m.opts.SavePeriodMillis > 0 {
-					m.rebucketSaveLocked(entityType, id, meta.lastSaveMs)
+				// 重新入保存轮（从未保存过的以当前时间为起点）
+				if m.opts.savePeriodFor(entityType) > 0 {
+					base := rec.lastSaveMs
+					if base == 0 {
+						base = now
+					}
+					m.rebucketSaveLocked(entityType, id, base)
+				} else {
+					m.saveWheel.cancel(key)
 				}
 			}
 		}
+		sh.mu.RUnlock()
 	}
 }
 
@@ -0,0 +1,94 @@
+package base
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+const benchEntities = 10000
+
+func newBenchManager(b *testing.B, shards int) *MemoryManager {
+	b.Helper()
+	mgr := NewMemoryManager(WithShardCount(shards), WithCacheTTLMillis(60_000))
+	ctx := context.Background()
+	for i := 0; i < benchEntities; i++ {
+		if _, err := mgr.Create(ctx, "user", strconv.Itoa(i), func() facade.Entity { return &mmEntity{} }); err != nil {
+			b.Fatalf("create err: %v", err)
+		}
+	}
+	return mgr
+}
+
+func benchShardCounts(b *testing.B, fn func(b *testing.B, shards int)) {
+	for _, shards := range []int{1, 32} {
+		b.Run("shards="+strconv.Itoa(shards), func(b *testing.B) { fn(b, shards) })
+	}
+}
+
+func BenchmarkMemoryManager_Get(b *testing.B) {
+	benchShardCounts(b, func(b *testing.B, shards int) {
+		mgr := newBenchManager(b, shards)
+		ctx := context.Background()
+		var seq atomic.Int64
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := int(seq.Add(1)) * 7919
+			for pb.Next() {
+				i++
+				if _, err := mgr.Get(ctx, "user", strconv.Itoa(i%benchEntities)); err != nil {
+					b.Errorf("get err: %v", err)
+					return
+				}
+			}
+		})
+	})
+}
+
+func BenchmarkMemoryManager_CreateRemove(b *testing.B) {
+	benchShardCounts(b, func(b *testing.B, shards int) {
+		mgr := NewMemoryManager(WithShardCount(shards))
+		ctx := context.Background()
+		var seq atomic.Int64
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				id := strconv.FormatInt(seq.Add(1), 10)
+				if _, err := mgr.Create(ctx, "user", id, func() facade.Entity { return &mmEntity{} }); err != nil {
+					b.Errorf("create err: %v", err)
+					return
+				}
+				_ = mgr.Remove(ctx, "user", id)
+			}
+		})
+	})
+}
+
+func BenchmarkMemoryManager_Mixed(b *testing.B) {
+	benchShardCounts(b, func(b *testing.B, shards int) {
+		mgr := newBenchManager(b, shards)
+		ctx := context.Background()
+		var seq atomic.Int64
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			n := seq.Add(1) * 1_000_000
+			i := 0
+			for pb.Next() {
+				i++
+				switch i % 10 {
+				case 0:
+					id := "tmp" + strconv.FormatInt(n+int64(i), 10)
+					_, _ = mgr.Create(ctx, "user", id, func() facade.Entity { return &mmEntity{} })
+				case 1:
+					id := "tmp" + strconv.FormatInt(n+int64(i-1), 10)
+					_ = mgr.Remove(ctx, "user", id)
+				default:
+					_, _ = mgr.Get(ctx, "user", strconv.Itoa(i%benchEntities))
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,136 @@
+package base
+
+import (
+	"context"
+	"fmt"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// entityGroup 实体组：同一 id 的多个实体类型作为一个整体
+type entityGroup struct {
+	name  string
+	types []string
+}
+
+type groupMember struct {
+	entityType string
+	rec        *entityRecord
+}
+
+// GroupSaveSink 支持整组投递的保存管道（可选）；组内成员作为一个一致单元快照与写出
+type GroupSaveSink interface {
+	SaveSink
+	EnqueueGroup(ctx context.Context, group, id string, es []facade.Entity) error
+}
+
+// RegisterGroup 声明实体组（同一 id 的多个类型作为一个整体）
+// - 路由：链接按 id 维护，同一服务内组成员天然落在同一 Pod
+// - 加载：成员按需懒加载；GetGroup 一次加载全部成员
+// - 卸载：以组内最近访问时间判定 TTL，到期时全部已加载成员一起卸载
+// - 执行：CallSystemImpl 为组内成员共享同一执行器，跨类型操作在一次执行内原子完成
+// - 保存：任一成员到期时整组一起保存（保存管道实现 GroupSaveSink 时作为一个单元投递）
+// 一个类型至多属于一个组；应在实体加载前完成声明。
+func (m *MemoryManager) RegisterGroup(group string, types ...string) error {
+	if group == "" || len(types) == 0 {
+		return fmt.Errorf("%w: empty entity group", facade.ErrInvalidConfig)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	next := make(map[string]*entityGroup, len(m.groups)+len(types))
+	for t, g := range m.groups {
+		if g.name == group {
+			return fmt.Errorf("%w: entity group %q already registered", facade.ErrInvalidConfig, group)
+		}
+		next[t] = g
+	}
+	g := &entityGroup{name: group, types: append([]string(nil), types...)}
+	for _, t := range types {
+		if old, ok := next[t]; ok {
+			return fmt.Errorf("%w: type %q already in entity group %q", facade.ErrInvalidConfig, t, old.name)
+		}
+		next[t] = g
+	}
+	m.groups = next
+	return nil
+}
+
+// GroupOf 返回类型所属的组名（"" 表示不属于任何组）
+func (m *MemoryManager) GroupOf(entityType string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if g := m.groups[entityType]; g != nil {
+		return g.name
+	}
+	return ""
+}
+
+// GroupMembers 返回组内全部类型（声明顺序）
+func (m *MemoryManager) GroupMembers(group string) []string {
+	g := m.groupByName(group)
+	if g == nil {
+		return nil
+	}
+	return append([]string(nil), g.types...)
+}
+
+// GetGroup 加载并返回组内全部成员（type -> 实体）；任一成员加载失败返回错误
+func (m *MemoryManager) GetGroup(ctx context.Context, group, id string) (map[string]facade.Entity, error) {
+	g := m.groupByName(group)
+	if g == nil {
+		return nil, fmt.Errorf("%w: entity group %q", facade.ErrNotFound, group)
+	}
+	out := make(map[string]facade.Entity, len(g.types))
+	for _, t := range g.types {
+		e, err := m.Get(ctx, t, id)
+		if err != nil {
+			return nil, err
+		}
+		out[t] = e
+	}
+	return out, nil
+}
+
+// RemoveGroup 将组内全部已加载成员移出内存
+func (m *MemoryManager) RemoveGroup(ctx context.Context, group, id string) error {
+	g := m.groupByName(group)
+	if g == nil {
+		return fmt.Errorf("%w: entity group %q", facade.ErrNotFound, group)
+	}
+	for _, t := range g.types {
+		if err := m.Remove(ctx, t, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryManager) groupByName(group string) *entityGroup {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, g := range m.groups {
+		if g.name == group {
+			return g
+		}
+	}
+	return nil
+}
+
+// loadedMembers 返回实体（或其所在组）在内存中的全部成员；group 为 nil 时仅返回自身
+func (m *MemoryManager) loadedMembers(group *entityGroup, entityType, id string) []groupMember {
+	if group == nil {
+		if rec, ok := m.lookup(entityType, id); ok {
+			return []groupMember{{entityType, rec}}
+		}
+		return nil
+	}
+	members := make([]groupMember, 0, len(group.types))
+	for _, t := range group.types {
+		if rec, ok := m.lookup(t, id); ok {
+			members = append(members, groupMember{t, rec})
+		}
+	}
+	return members
+}
+
+var _ facade.GroupResolver = (*MemoryManager)(nil)
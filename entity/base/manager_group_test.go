@@ -0,0 +1,130 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+func TestMemoryManager_RegisterGroup(t *testing.T) {
+	mgr := NewMemoryManager()
+	if err := mgr.RegisterGroup("player", "bag", "mail"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.RegisterGroup("guild", "mail"); !errors.Is(err, facade.ErrInvalidConfig) {
+		t.Fatalf("type in two groups: err=%v", err)
+	}
+	if err := mgr.RegisterGroup("player", "profile"); !errors.Is(err, facade.ErrInvalidConfig) {
+		t.Fatalf("duplicate group: err=%v", err)
+	}
+	if g := mgr.GroupOf("mail"); g != "player" {
+		t.Fatalf("GroupOf=%q", g)
+	}
+	if g := mgr.GroupOf("profile"); g != "" {
+		t.Fatalf("GroupOf=%q for ungrouped type", g)
+	}
+
+	ctx := context.Background()
+	for _, typ := range []string{"bag", "mail"} {
+		mgr.RegisterNotFoundHook(typ, func(ctx context.Context, id string) (facade.Entity, error) { return &mmEntity{}, nil })
+	}
+	es, err := mgr.GetGroup(ctx, "player", "P1")
+	if err != nil || len(es) != 2 || es["bag"] == nil || es["mail"] == nil {
+		t.Fatalf("GetGroup=%v err=%v", es, err)
+	}
+	if err := mgr.RemoveGroup(ctx, "player", "P1"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := mgr.Exists(ctx, "bag", "P1"); ok {
+		t.Fatalf("bag still loaded after RemoveGroup")
+	}
+}
+
+func TestMemoryManager_GroupEvictTogether(t *testing.T) {
+	mgr := NewMemoryManager(WithCacheTTLMillis(60), WithWheelTickMillis(5), WithSavePeriodMillis(0))
+	if err := mgr.RegisterGroup("player", "bag", "mail"); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, typ := range []string{"bag", "mail"} {
+		if _, err := mgr.Create(ctx, typ, "P1", func() facade.Entity { return &mmEntity{} }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 仅访问 mail：bag 随组保持加载
+	for i := 0; i < 10; i++ {
+		time.Sleep(15 * time.Millisecond)
+		if _, err := mgr.Get(ctx, "mail", "P1"); err != nil {
+			t.Fatalf("mail evicted while accessed: %v", err)
+		}
+	}
+	if ok, _ := mgr.Exists(ctx, "bag", "P1"); !ok {
+		t.Fatalf("bag evicted while group member accessed")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		bag, _ := mgr.Exists(ctx, "bag", "P1")
+		mail, _ := mgr.Exists(ctx, "mail", "P1")
+		if !bag && !mail {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("group not evicted: bag=%v mail=%v", bag, mail)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMemoryManager_GroupSaveAsUnit(t *testing.T) {
+	store := newFakeBatchStore()
+	wb := newTestWriteBehind(t, store, WithWriteBehindInterval(5*time.Millisecond))
+	mgr := NewMemoryManager(WithSavePeriodMillis(20), WithWheelTickMillis(5), WithSaveJitterRatio(0))
+	mgr.SetSaveSink(wb)
+	if err := mgr.RegisterGroup("player", "bag", "mail"); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	var bag facade.Entity
+	for _, typ := range []string{"bag", "mail"} {
+		typ := typ
+		e, err := mgr.Create(ctx, typ, "P1", func() facade.Entity {
+			w := &wbEntity{value: typ}
+			w.SetTypeName(typ)
+			return w
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ == "bag" {
+			bag = e
+		}
+	}
+	// 仅 bag 变更：整组作为一个单元写出
+	bag.(*wbEntity).SetDirty(true)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for store.row("bag/P1") == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("group not saved, stats=%+v", wb.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := wb.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for _, b := range store.batches {
+		var hasBag, hasMail bool
+		for _, r := range b {
+			hasBag = hasBag || r.TypeName == "bag"
+			hasMail = hasMail || r.TypeName == "mail"
+		}
+		if hasBag != hasMail {
+			t.Fatalf("group members written in separate batches: %+v", b)
+		}
+	}
+}
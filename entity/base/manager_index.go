@@ -0,0 +1,75 @@
+package base
+
+import (
+	"context"
+	"fmt"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// RegisterIndex 声明实体类型的索引字段；Query 仅允许查询已声明的字段
+// 索引由存储驱动在保存时维护：同步保存路径写入 SetIndexStorage 设置的索引存储，
+// write-behind 管道随 SaveRecord.Index 写出（驱动实现 facade.IndexStorage 时）。
+func (m *MemoryManager) RegisterIndex(entityType string, fields ...facade.IndexField) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	next := make(map[string]map[string]facade.IndexKind, len(m.indexes)+1)
+	for t, fs := range m.indexes {
+		next[t] = fs
+	}
+	fs := make(map[string]facade.IndexKind, len(next[entityType])+len(fields))
+	for name, kind := range next[entityType] {
+		fs[name] = kind
+	}
+	for _, f := range fields {
+		if f.Name == "" || (f.Kind != facade.IndexExact && f.Kind != facade.IndexOrdered) {
+			return fmt.Errorf("%w: invalid index field %+v on %s", facade.ErrInvalidConfig, f, entityType)
+		}
+		if kind, ok := fs[f.Name]; ok && kind != f.Kind {
+			return fmt.Errorf("%w: index field %s/%s redeclared with another kind", facade.ErrInvalidConfig, entityType, f.Name)
+		}
+		fs[f.Name] = f.Kind
+	}
+	next[entityType] = fs
+	m.indexes = next
+	return nil
+}
+
+// SetIndexStorage 设置索引存储（同步保存路径写入索引、Query 从中读取）
+func (m *MemoryManager) SetIndexStorage(s facade.IndexStorage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.indexStore = s
+}
+
+// Query 按已声明的索引字段查询实体 id（分页）；结果基于已落地的数据
+func (m *MemoryManager) Query(ctx context.Context, q *facade.Query) (*facade.QueryResult, error) {
+	m.mu.RLock()
+	kind, ok := m.indexes[q.EntityType][q.Field]
+	store := m.indexStore
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: index %s/%s not declared", facade.ErrInvalidConfig, q.EntityType, q.Field)
+	}
+	if store == nil {
+		return nil, fmt.Errorf("%w: no index storage", facade.ErrInvalidConfig)
+	}
+	return store.QueryIndex(ctx, kind, q)
+}
+
+// putIndex 同步保存成功后写入实体索引
+func (m *MemoryManager) putIndex(ctx context.Context, e facade.Entity) error {
+	io, ok := e.(facade.IndexedObject)
+	if !ok {
+		return nil
+	}
+	m.mu.RLock()
+	store := m.indexStore
+	m.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+	return store.PutIndex(ctx, e.Type(), e.ID(), io.IndexValues())
+}
+
+var _ facade.EntityQuerier = (*MemoryManager)(nil)
@@ -0,0 +1,62 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/entity/index"
+)
+
+type indexedEntity struct {
+	ttlSaveEntity
+	guild string
+}
+
+func (e *indexedEntity) IndexValues() map[string]facade.IndexValue {
+	return map[string]facade.IndexValue{"guild": facade.ExactIndex(e.guild)}
+}
+
+func TestMemoryManager_Query(t *testing.T) {
+	mgr := NewMemoryManager(WithSavePeriodMillis(20), WithWheelTickMillis(5), WithSaveJitterRatio(0))
+	mgr.SetIndexStorage(index.NewMemoryIndex())
+	if err := mgr.RegisterIndex("user", facade.IndexField{Name: "guild", Kind: facade.IndexExact}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.RegisterIndex("user", facade.IndexField{Name: "guild", Kind: facade.IndexOrdered}); !errors.Is(err, facade.ErrInvalidConfig) {
+		t.Fatalf("redeclare err=%v", err)
+	}
+	ctx := context.Background()
+	if _, err := mgr.Query(ctx, &facade.Query{EntityType: "user", Field: "level"}); !errors.Is(err, facade.ErrInvalidConfig) {
+		t.Fatalf("undeclared field err=%v", err)
+	}
+
+	for _, id := range []string{"U2", "U1", "U3"} {
+		guild := "g1"
+		if id == "U3" {
+			guild = "g2"
+		}
+		e, err := mgr.Create(ctx, "user", id, func() facade.Entity { return &indexedEntity{guild: guild} })
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.(*indexedEntity).SetDirty(true)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		res, err := mgr.Query(ctx, &facade.Query{EntityType: "user", Field: "guild", Eq: "g1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reflect.DeepEqual(res.IDs, []string{"U1", "U2"}) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("index not maintained on save: %v", res.IDs)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,125 @@
+package base
+
+import (
+	"sync"
+	"sync/atomic"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// mmShard MemoryManager 的一个分片
+// 按 hash(type, id) 将实体索引、元数据与键级锁分散到多个分片，避免全局锁竞争。
+type mmShard struct {
+	mu      sync.RWMutex
+	records map[string]map[string]*entityRecord // type -> id -> record
+
+	// 键级锁：引用计数，空闲时即回收
+	klMu     sync.Mutex
+	keyLocks map[string]*keyLock
+}
+
+// entityRecord 实体及其运行时元数据
+// lastAccessMs 为原子字段，Get 命中时仅持分片读锁即可续期；其余字段受分片锁保护。
+type entityRecord struct {
+	entity       facade.Entity
+	lastAccessMs atomic.Int64
+	lastSaveMs   int64
+}
+
+// keyLock 引用计数的键级锁
+type keyLock struct {
+	mu    sync.Mutex
+	key   string
+	refs  int
+	shard *mmShard
+}
+
+func newMMShard() *mmShard {
+	return &mmShard{
+		records:  make(map[string]map[string]*entityRecord),
+		keyLocks: make(map[string]*keyLock),
+	}
+}
+
+// shardHash 计算 type/id 的 fnv32a（与 makeKey 结果的 hash 一致，但不分配内存）
+func shardHash(entityType, id string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(entityType); i++ {
+		h ^= uint32(entityType[i])
+		h *= 16777619
+	}
+	h ^= uint32('/')
+	h *= 16777619
+	for i := 0; i < len(id); i++ {
+		h ^= uint32(id[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// getLocked 查找记录（调用方持有分片读锁或写锁）
+func (s *mmShard) getLocked(entityType, id string) *entityRecord {
+	if mm := s.records[entityType]; mm != nil {
+		return mm[id]
+	}
+	return nil
+}
+
+// putLocked 写入记录（调用方持有分片写锁）
+func (s *mmShard) putLocked(entityType, id string, rec *entityRecord) {
+	mm := s.records[entityType]
+	if mm == nil {
+		mm = make(map[string]*entityRecord)
+		s.records[entityType] = mm
+	}
+	mm[id] = rec
+}
+
+// deleteLocked 删除并返回记录（调用方持有分片写锁）
+func (s *mmShard) deleteLocked(entityType, id string) *entityRecord {
+	mm := s.records[entityType]
+	if mm == nil {
+		return nil
+	}
+	rec, ok := mm[id]
+	if !ok {
+		return nil
+	}
+	delete(mm, id)
+	if len(mm) == 0 {
+		delete(s.records, entityType)
+	}
+	return rec
+}
+
+// acquire 获取键级锁（引用计数 +1 后加锁）
+func (s *mmShard) acquire(key string) *keyLock {
+	s.klMu.Lock()
+	lk := s.keyLocks[key]
+	if lk == nil {
+		lk = &keyLock{key: key, shard: s}
+		s.keyLocks[key] = lk
+	}
+	lk.refs++
+	s.klMu.Unlock()
+	lk.mu.Lock()
+	return lk
+}
+
+// release 释放键级锁；无人持有或等待时从分片中回收
+func (lk *keyLock) release() {
+	lk.mu.Unlock()
+	s := lk.shard
+	s.klMu.Lock()
+	lk.refs--
+	if lk.refs == 0 {
+		delete(s.keyLocks, lk.key)
+	}
+	s.klMu.Unlock()
+}
+
+func (s *mmShard) keyLockCount() int {
+	s.klMu.Lock()
+	defer s.klMu.Unlock()
+	return len(s.keyLocks)
+}
@@ -2,6 +2,8 @@ package base
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 
 	facade "github.com/go-kratos/kratos/v2/entity/facade"
@@ -86,3 +88,59 @@ func TestMemoryManager_ReleaseAll_IsAllLanded(t *testing.T) {
 		t.Fatalf("should landed after release all")
 	}
 }
+
+func TestMemoryManager_KeyLocksReleased(t *testing.T) {
+	mgr := NewMemoryManager(WithShardCount(4))
+	ctx := context.Background()
+	mgr.RegisterNotFoundHook("user", func(ctx context.Context, id string) (facade.Entity, error) {
+		return &mmEntity{}, nil
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("U%d", i%8)
+			_, _ = mgr.Get(ctx, "user", id)
+			_, _ = mgr.Create(ctx, "user", id+"c", func() facade.Entity { return &mmEntity{} })
+			_ = mgr.Remove(ctx, "user", id+"c")
+		}(i)
+	}
+	wg.Wait()
+	if n := mgr.Len(); n != 8 {
+		t.Fatalf("entities=%d want 8", n)
+	}
+	for i, sh := range mgr.shards {
+		if n := sh.keyLockCount(); n != 0 {
+			t.Fatalf("shard %d still holds %d key locks", i, n)
+		}
+	}
+}
+
+func TestMemoryManager_Range(t *testing.T) {
+	mgr := NewMemoryManager()
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		if _, err := mgr.Create(ctx, "user", fmt.Sprintf("R%d", i), func() facade.Entity { return &mmEntity{} }); err != nil {
+			t.Fatalf("create err: %v", err)
+		}
+	}
+	seen := map[string]bool{}
+	mgr.Range(func(entityType, id string, e facade.Entity) bool {
+		if entityType != "user" || e.ID() != id {
+			t.Fatalf("unexpected entry %s/%s", entityType, id)
+		}
+		// 回调在锁外执行，可安全调用管理器
+		_ = mgr.Remove(ctx, entityType, id)
+		seen[id] = true
+		return true
+	})
+	if len(seen) != 10 {
+		t.Fatalf("ranged %d entities, want 10", len(seen))
+	}
+	n := 0
+	mgr.Range(func(string, string, facade.Entity) bool { n++; return true })
+	if n != 0 {
+		t.Fatalf("ranged %d after remove, want 0", n)
+	}
+}
@@ -53,39 +53,27 @@ func TestMemoryManager_TTLUnload(t *testing.T) {
 	// 等待一小段时间让后台任务有机会处理
 	time.Sleep(100 * time.Millisecond)
 
-	// 检查分桶状态
-	t.Logf("TTL buckets count: %d, current index: %d", len(mgr.ttlBuckets), mgr.ttlIdx)
-	t.Logf("TTL index by key count: %d", len(mgr.ttlIndexByKey))
+	// 检查时间轮状态
+	t.Logf("TTL wheel timers: %d", mgr.ttlWheel.len())
 
-	// 检查实体的具体分桶位置
+	// 检查实体的到期时间
 	key := "user/U1"
-	if slot, ok := mgr.ttlIndexByKey[key]; ok {
-		t.Logf("Entity %s is in TTL bucket %d", key, slot)
-		// 检查该槽位是否有实体
-		bucket := mgr.ttlBuckets[slot]
-		t.Logf("Bucket %d contains %d entities", slot, len(bucket))
+	if expireMs, ok := mgr.ttlWheel.expireAt(key); ok {
+		t.Logf("Entity %s expires at %d", key, expireMs)
 	}
 
 	// 检查实体的元数据
-	if meta, ok := mgr.meta["user"]["U1"]; ok {
+	if rec, ok := mgr.lookup("user", "U1"); ok {
 		now := time.Now().UnixMilli()
+		lastAccess := rec.lastAccessMs.Load()
 		t.Logf("Entity meta: lastAccess=%d, now=%d, diff=%dms",
-			meta.lastAccessMs, now, now-meta.lastAccessMs)
+			lastAccess, now, now-lastAccess)
 	}
 
-	// 监控时间轮推进和实体状态
+	// 监控实体状态
 	deadline := time.Now().Add(3 * time.Second)
-	lastIdx := mgr.ttlIdx
 	for time.Now().Before(deadline) {
 		exists, _ := mgr.Exists(ctx, "user", "U1")
-		currentIdx := mgr.ttlIdx
-
-		// 如果时间轮推进了，记录日志
-		if currentIdx != lastIdx {
-			t.Logf("Time wheel advanced: %d -> %d at %v", lastIdx, currentIdx, time.Now())
-			lastIdx = currentIdx
-		}
-
 		if !exists {
 			t.Logf("Entity unloaded at: %v", time.Now())
 			return
@@ -179,11 +167,11 @@ func TestMemoryManager_BackgroundTasks(t *testing.T) {
 	}
 
 	// 检查时间轮是否正确初始化
-	if len(mgr.ttlBuckets) == 0 {
-		t.Error("TTL buckets not initialized")
+	if mgr.ttlWheel == nil {
+		t.Error("TTL wheel not initialized")
 	}
-	if len(mgr.saveBuckets) == 0 {
-		t.Error("Save buckets not initialized")
+	if mgr.saveWheel == nil {
+		t.Error("Save wheel not initialized")
 	}
 
 	// 检查ticker是否启动
@@ -197,3 +185,42 @@ func TestMemoryManager_BackgroundTasks(t *testing.T) {
 	t.Logf("MemoryManager background tasks status: TTL=%v, Save=%v",
 		mgr.ttlTicker != nil, mgr.saveTicker != nil)
 }
+
+func TestMemoryManager_ApplyConfig_TypeOverride(t *testing.T) {
+	mgr := NewMemoryManager()
+	ctx := context.Background()
+	if _, err := mgr.Create(ctx, "user", "U1", func() facade.Entity { return &ttlSaveEntity{} }); err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+
+	// 全局 TTL 关闭，仅 user 类型开启
+	ttl := int64(150)
+	cfg := facade.DefaultConfig()
+	cfg.Types = map[string]facade.TypeConfig{"user": {CacheTTLMillis: &ttl}}
+	mgr.ApplyConfig(cfg)
+	if _, ok := mgr.ttlWheel.expireAt("user/U1"); !ok {
+		t.Fatalf("user entity should be scheduled by type override")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if exists, _ := mgr.Exists(ctx, "user", "U1"); !exists {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if exists, _ := mgr.Exists(ctx, "user", "U1"); exists {
+		t.Fatalf("user entity should be unloaded by type TTL")
+	}
+
+	// 全局 TTL 开启，user 类型覆盖为 0（常驻）
+	zero := int64(0)
+	cfg.CacheTTLMillis = 100
+	cfg.Types = map[string]facade.TypeConfig{"user": {CacheTTLMillis: &zero}}
+	mgr.ApplyConfig(cfg)
+	if _, err := mgr.Create(ctx, "user", "U2", func() facade.Entity { return &ttlSaveEntity{} }); err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+	if _, ok := mgr.ttlWheel.expireAt("user/U2"); ok {
+		t.Fatalf("user entity should not be scheduled when type TTL is 0")
+	}
+}
@@ -0,0 +1,258 @@
+package base
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Executor 实体串行执行器契约（Actor 与 Mailbox 均实现）
+// 语义：同一执行器内任务严格按入队顺序串行执行；队列满返回 ErrQueueOverflow；
+// 关闭后入队返回 context.Canceled，Close 等待已入队任务执行完毕。
+type Executor interface {
+	Enqueue(ctx context.Context, f func()) error
+	QueueLen() int
+	Close()
+}
+
+// Scheduler 执行器工厂：决定实体 mailbox 的调度方式
+type Scheduler interface {
+	NewExecutor(queueSize int) Executor
+}
+
+// ActorScheduler 每个实体独占一个 goroutine（默认，适合活跃实体较少的场景）
+type ActorScheduler struct{}
+
+// NewExecutor 创建独占 goroutine 的 Actor
+func (ActorScheduler) NewExecutor(queueSize int) Executor { return NewActor(queueSize) }
+
+// PoolScheduler 有界 worker 池调度
+// 每个实体仅持有一个轻量队列；只有队列非空时才会被投递到就绪队列，由 worker 取出执行，
+// 同一 mailbox 同一时刻至多被一个 worker 运行，从而保持实体内串行。
+// 适合百万级且大多空闲的实体。
+type PoolScheduler struct {
+	ready   *readyQueue
+	batch   int
+	wg      sync.WaitGroup
+	stopped bool
+	mu      sync.Mutex
+}
+
+// NewPoolScheduler 创建 worker 池；workers 为并发 worker 数，batch 为单次调度最多连续执行的任务数（公平性）
+func NewPoolScheduler(workers, batch int) *PoolScheduler {
+	if workers <= 0 {
+		workers = 1
+	}
+	if batch <= 0 {
+		batch = 64
+	}
+	p := &PoolScheduler{ready: newReadyQueue(), batch: batch}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// NewExecutor 创建挂在该池上的 Mailbox
+func (p *PoolScheduler) NewExecutor(queueSize int) Executor { return p.NewMailbox(queueSize) }
+
+// NewMailbox 创建挂在该池上的 Mailbox
+func (p *PoolScheduler) NewMailbox(queueSize int) *Mailbox {
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+	return &Mailbox{pool: p, capacity: queueSize, drained: make(chan struct{})}
+}
+
+// Stop 停止调度：之后入队返回 context.Canceled；已入队的任务执行完毕后 worker 退出
+func (p *PoolScheduler) Stop() {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	p.mu.Unlock()
+	p.ready.close()
+	p.wg.Wait()
+}
+
+func (p *PoolScheduler) worker() {
+	defer p.wg.Done()
+	for {
+		mb, ok := p.ready.pop()
+		if !ok {
+			return
+		}
+		mb.run(p.batch)
+	}
+}
+
+// Mailbox 池化调度的实体队列
+type Mailbox struct {
+	pool      *PoolScheduler
+	mu        sync.Mutex
+	queue     []func()
+	capacity  int
+	scheduled bool // 已投递到就绪队列或正在被 worker 执行
+	closed    bool
+	drained   chan struct{}
+}
+
+// Enqueue 将任务入队；若队列已满则返回 ErrQueueOverflow，mailbox 已关闭或调度器已停止返回 context.Canceled
+func (mb *Mailbox) Enqueue(ctx context.Context, f func()) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	if mb.closed || mb.pool.ready.isClosed() {
+		return context.Canceled
+	}
+	if len(mb.queue) >= mb.capacity {
+		return facade.ErrQueueOverflow
+	}
+	// 持有 mb.mu 投递：与 Stop 竞争时投递失败即拒绝，不会留下无 worker 执行的任务
+	if !mb.scheduled {
+		if !mb.pool.ready.push(mb) {
+			return context.Canceled
+		}
+		mb.scheduled = true
+	}
+	mb.queue = append(mb.queue, f)
+	return nil
+}
+
+// QueueLen 返回当前队列长度（近似）
+func (mb *Mailbox) QueueLen() int {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	return len(mb.queue)
+}
+
+// Close 关闭队列并等待已入队任务执行完毕
+func (mb *Mailbox) Close() {
+	mb.mu.Lock()
+	if !mb.closed {
+		mb.closed = true
+		if !mb.scheduled {
+			close(mb.drained)
+		}
+	}
+	mb.mu.Unlock()
+	<-mb.drained
+}
+
+// run 由 worker 调用：最多连续执行 batch 个任务，仍有剩余则重新投递
+// 调度器停止后无法重新投递，由当前 worker 继续执行至队列为空。
+func (mb *Mailbox) run(batch int) {
+	for {
+		for i := 0; i < batch; i++ {
+			mb.mu.Lock()
+			if len(mb.queue) == 0 {
+				mb.mu.Unlock()
+				break
+			}
+			f := mb.queue[0]
+			mb.queue[0] = nil
+			mb.queue = mb.queue[1:]
+			mb.mu.Unlock()
+			runTask(f)
+		}
+		mb.mu.Lock()
+		if len(mb.queue) == 0 {
+			break
+		}
+		mb.mu.Unlock()
+		if mb.pool.ready.push(mb) {
+			return
+		}
+	}
+	mb.scheduled = false
+	// 释放底层数组，避免空闲实体长期持有内存
+	mb.queue = nil
+	if mb.closed {
+		close(mb.drained)
+	}
+	mb.mu.Unlock()
+}
+
+// runTask 执行任务，恢复并记录任务中的 panic，保持执行器继续运行
+func runTask(f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			buf := make([]byte, 64<<10) //nolint:mnd
+			n := runtime.Stack(buf, false)
+			log.Errorf("entity: executor task panic: %v\n%s", r, buf[:n])
+		}
+	}()
+	f()
+}
+
+// readyQueue 无界就绪队列（FIFO）；关闭后拒绝投递，已投递的 mailbox 仍会被取出
+type readyQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []*Mailbox
+	head   int
+	closed bool
+}
+
+func newReadyQueue() *readyQueue {
+	q := &readyQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push 投递 mailbox；队列已关闭时返回 false
+func (q *readyQueue) push(mb *Mailbox) bool {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return false
+	}
+	q.items = append(q.items, mb)
+	q.mu.Unlock()
+	q.cond.Signal()
+	return true
+}
+
+func (q *readyQueue) isClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+func (q *readyQueue) pop() (*Mailbox, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.head == len(q.items) && !q.closed {
+		q.cond.Wait()
+	}
+	if q.head == len(q.items) {
+		return nil, false
+	}
+	mb := q.items[q.head]
+	q.items[q.head] = nil
+	q.head++
+	if q.head == len(q.items) {
+		q.items = q.items[:0]
+		q.head = 0
+	}
+	return mb, true
+}
+
+func (q *readyQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+var (
+	_ Executor  = (*Actor)(nil)
+	_ Executor  = (*Mailbox)(nil)
+	_ Scheduler = ActorScheduler{}
+	_ Scheduler = (*PoolScheduler)(nil)
+)
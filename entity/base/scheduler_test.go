@@ -0,0 +1,143 @@
+package base
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMailbox_OrderPerEntity(t *testing.T) {
+	pool := NewPoolScheduler(4, 4)
+	defer pool.Stop()
+	const entities, tasks = 100, 200
+	boxes := make([]*Mailbox, entities)
+	seqs := make([]int64, entities)
+	for i := range boxes {
+		boxes[i] = pool.NewMailbox(tasks)
+	}
+	var wg sync.WaitGroup
+	wg.Add(entities * tasks)
+	for n := 0; n < tasks; n++ {
+		for i, mb := range boxes {
+			i, want := i, int64(n+1)
+			if err := mb.Enqueue(context.Background(), func() {
+				defer wg.Done()
+				if got := atomic.AddInt64(&seqs[i], 1); got != want {
+					t.Errorf("entity %d order broken: want %d got %d", i, want, got)
+				}
+			}); err != nil {
+				t.Fatalf("enqueue err: %v", err)
+			}
+		}
+	}
+	wg.Wait()
+}
+
+func TestMailbox_SerialWithinEntity(t *testing.T) {
+	pool := NewPoolScheduler(8, 1)
+	defer pool.Stop()
+	mb := pool.NewMailbox(1000)
+	var running, maxRunning atomic.Int32
+	var wg sync.WaitGroup
+	wg.Add(500)
+	for i := 0; i < 500; i++ {
+		_ = mb.Enqueue(context.Background(), func() {
+			defer wg.Done()
+			n := running.Add(1)
+			if n > maxRunning.Load() {
+				maxRunning.Store(n)
+			}
+			running.Add(-1)
+		})
+	}
+	wg.Wait()
+	if maxRunning.Load() != 1 {
+		t.Fatalf("mailbox ran concurrently: max=%d", maxRunning.Load())
+	}
+}
+
+func TestMailbox_Overflow(t *testing.T) {
+	pool := NewPoolScheduler(1, 1)
+	defer pool.Stop()
+	mb := pool.NewMailbox(1)
+	block := make(chan struct{})
+	started := make(chan struct{})
+	if err := mb.Enqueue(context.Background(), func() { close(started); <-block }); err != nil {
+		t.Fatalf("first enqueue err: %v", err)
+	}
+	<-started
+	if err := mb.Enqueue(context.Background(), func() {}); err != nil {
+		t.Fatalf("second enqueue err: %v", err)
+	}
+	// 容量 1 且已有 1 个排队任务，应当溢出
+	if err := mb.Enqueue(context.Background(), func() {}); err == nil {
+		t.Fatalf("expect overflow error")
+	}
+	close(block)
+}
+
+func TestMailbox_CloseDrains(t *testing.T) {
+	pool := NewPoolScheduler(2, 2)
+	defer pool.Stop()
+	mb := pool.NewMailbox(16)
+	var done atomic.Int32
+	for i := 0; i < 10; i++ {
+		_ = mb.Enqueue(context.Background(), func() {
+			time.Sleep(time.Millisecond)
+			done.Add(1)
+		})
+	}
+	mb.Close()
+	if done.Load() != 10 {
+		t.Fatalf("close returned before drain: done=%d", done.Load())
+	}
+	if err := mb.Enqueue(context.Background(), func() {}); err == nil {
+		t.Fatalf("enqueue after close should fail")
+	}
+	// 空闲 mailbox 关闭应立即返回
+	idle := pool.NewMailbox(1)
+	idle.Close()
+}
+
+func TestMailbox_StopRejectsAndDrains(t *testing.T) {
+	pool := NewPoolScheduler(1, 1)
+	mb := pool.NewMailbox(16)
+	var done atomic.Int32
+	for i := 0; i < 5; i++ {
+		_ = mb.Enqueue(context.Background(), func() {
+			time.Sleep(time.Millisecond)
+			done.Add(1)
+		})
+	}
+	pool.Stop()
+	// 已入队任务在 Stop 返回前执行完毕
+	if done.Load() != 5 {
+		t.Fatalf("stop returned before queued tasks ran: done=%d", done.Load())
+	}
+	if err := mb.Enqueue(context.Background(), func() {}); err != context.Canceled {
+		t.Fatalf("enqueue after stop: want context.Canceled, got %v", err)
+	}
+	closed := make(chan struct{})
+	go func() { mb.Close(); close(closed) }()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("close blocked after stop")
+	}
+}
+
+func TestMailbox_PanicKeepsRunning(t *testing.T) {
+	pool := NewPoolScheduler(1, 4)
+	defer pool.Stop()
+	mb := pool.NewMailbox(4)
+	ran := make(chan struct{})
+	_ = mb.Enqueue(context.Background(), func() { panic("boom") })
+	_ = mb.Enqueue(context.Background(), func() { close(ran) })
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatalf("task after panic did not run")
+	}
+}
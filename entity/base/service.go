@@ -2,7 +2,7 @@ package base
 
 import (
 	"context"
-	"fmt"
+	"errors"
 
 	"github.com/go-kratos/kratos/v2/api/entity"
 	facade "github.com/go-kratos/kratos/v2/entity/facade"
@@ -13,11 +13,19 @@ import (
 // 最小实现：
 // - OnEntityCall: Router.TrySetLocal -> CallSystem.Call -> EntityResponse
 // 说明：串行保障由 CallSystem 内部的 per-entity Actor 实现；此处不再重复排队
+// 限流：CallSystem 拒绝（ErrRateLimited）时返回 RespCode=RATE_LIMITED 而非错误，调用方可稍后重试
 
 type EntityServiceTemplate struct {
 	eMgr    facade.EntityMgr
 	callSys facade.CallSystem
 	Router  facade.Router
+	// Source 识别调用方（用于按调用方限流），nil 时统一为 "src"
+	Source func(ctx context.Context, req *entity.EntityRequest) string
+}
+
+// NewEntityServiceTemplate 创建服务端适配桩；router 可为 nil（不做路由绑定）
+func NewEntityServiceTemplate(eMgr facade.EntityMgr, callSys facade.CallSystem, router facade.Router) *EntityServiceTemplate {
+	return &EntityServiceTemplate{eMgr: eMgr, callSys: callSys, Router: router}
 }
 
 func (s *EntityServiceTemplate) OnEntityCall(ctx context.Context, req *entity.EntityRequest) (*entity.EntityResponse, error) {
@@ -28,13 +36,21 @@ func (s *EntityServiceTemplate) OnEntityCall(ctx context.Context, req *entity.En
 			return nil, err
 		}
 		if !ok {
-			return nil, fmt.Errorf("%w: targetPod=%d", facade.ErrAlreadyInOtherPod, prevPod)
+			// 携带链接 Pod 的拒绝错误，有状态客户端据此重新路由
+			return nil, facade.AlreadyInOtherPod(prevPod)
 		}
 	}
-	//这里可做鉴权，限流等
+	//这里可做鉴权等
+	src := "src"
+	if s.Source != nil {
+		src = s.Source(ctx, req)
+	}
 
-	// 分发到实体方法，由 CallSystem 保证串行
-	resp, err := s.callSys.Call(ctx, "src", req.FunName, req)
+	// 分发到实体方法，由 CallSystem 保证串行与限流
+	resp, err := s.callSys.Call(ctx, src, req.FunName, req)
+	if errors.Is(err, facade.ErrRateLimited) {
+		return &entity.EntityResponse{Session: req.Session, RespCode: entity.EntityResponse_RATE_LIMITED}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
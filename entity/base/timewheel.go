@@ -0,0 +1,241 @@
+package base
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// timingWheel 分层时间轮（毫秒精度）
+// 功能点：
+// - 多层级：第 L 层每槽覆盖 slots^L 个 tick，超出顶层范围的定时器在级联时重新入轮
+// - 分片锁：键索引按 hash 分片，每个槽位独立加锁；推进时独占，调度/取消仅持共享锁
+// - 惰性推进：由外部按墙钟调用 advance，一次处理期间所有 tick 并返回到期键
+//
+// 说明：到期键交由调用方处理（例如检查访问时间后决定卸载或重新调度）。
+type timingWheel struct {
+	tickMs int64
+	slots  int64
+	spans  []int64          // 第 L 层一个槽覆盖的 tick 数
+	levels [][]*wheelBucket // level -> slot -> bucket
+	shards []*wheelShard
+
+	mu      sync.RWMutex // advance 独占；schedule/cancel 共享
+	curTick int64        // 已处理到的绝对 tick（nowMs/tickMs）
+	count   atomic.Int64
+}
+
+type wheelTimer struct {
+	key      string
+	expireMs int64
+	bucket   *wheelBucket
+}
+
+type wheelBucket struct {
+	mu     sync.Mutex
+	timers map[string]*wheelTimer
+}
+
+type wheelShard struct {
+	mu     sync.Mutex
+	timers map[string]*wheelTimer
+}
+
+// newTimingWheel 创建时间轮；tickMs 为最小精度，slots 为每层槽位数，levels 为层数
+func newTimingWheel(nowMs, tickMs int64, slots, levels, shards int) *timingWheel {
+	if tickMs <= 0 {
+		tickMs = 1
+	}
+	if slots <= 1 {
+		slots = 60
+	}
+	if levels <= 0 {
+		levels = 1
+	}
+	if shards <= 0 {
+		shards = 1
+	}
+	w := &timingWheel{
+		tickMs:  tickMs,
+		slots:   int64(slots),
+		spans:   make([]int64, levels),
+		levels:  make([][]*wheelBucket, levels),
+		shards:  make([]*wheelShard, shards),
+		curTick: nowMs / tickMs,
+	}
+	span := int64(1)
+	for l := 0; l < levels; l++ {
+		w.spans[l] = span
+		w.levels[l] = make([]*wheelBucket, slots)
+		for i := range w.levels[l] {
+			w.levels[l][i] = &wheelBucket{timers: make(map[string]*wheelTimer)}
+		}
+		span *= int64(slots)
+	}
+	for i := range w.shards {
+		w.shards[i] = &wheelShard{timers: make(map[string]*wheelTimer)}
+	}
+	return w
+}
+
+// fnv32a 计算字符串 hash（用于分片）
+func fnv32a(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
+
+func (w *timingWheel) shard(key string) *wheelShard {
+	return w.shards[fnv32a(key)%uint32(len(w.shards))]
+}
+
+// schedule 设置（或替换）key 的到期时间（毫秒）
+func (w *timingWheel) schedule(key string, expireMs int64) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	sh := w.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if old := sh.timers[key]; old != nil {
+		old.bucket.remove(old)
+	} else {
+		w.count.Add(1)
+	}
+	t := &wheelTimer{key: key, expireMs: expireMs}
+	w.place(t, atomic.LoadInt64(&w.curTick)+1)
+	sh.timers[key] = t
+}
+
+// cancel 移除 key 的定时器（若存在）
+func (w *timingWheel) cancel(key string) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	sh := w.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if old := sh.timers[key]; old != nil {
+		old.bucket.remove(old)
+		delete(sh.timers, key)
+		w.count.Add(-1)
+	}
+}
+
+// expireAt 返回 key 当前的到期时间（毫秒）
+func (w *timingWheel) expireAt(key string) (int64, bool) {
+	sh := w.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if t := sh.timers[key]; t != nil {
+		return t.expireMs, true
+	}
+	return 0, false
+}
+
+// len 返回定时器数量
+func (w *timingWheel) len() int { return int(w.count.Load()) }
+
+// place 将定时器放入合适层级的槽位；minTick 为允许放入的最早 tick
+func (w *timingWheel) place(t *wheelTimer, minTick int64) {
+	cur := atomic.LoadInt64(&w.curTick)
+	expTick := t.expireMs / w.tickMs
+	if expTick < minTick {
+		expTick = minTick
+	}
+	delta := expTick - cur
+	top := len(w.levels) - 1
+	lvl := 0
+	for lvl < top && delta >= w.slots*w.spans[lvl] {
+		lvl++
+	}
+	if lvl == top && delta >= w.slots*w.spans[top] {
+		// 超出顶层范围：暂放顶层最远槽位，级联时按真实到期时间重新入轮
+		expTick = cur + (w.slots-1)*w.spans[top]
+	}
+	b := w.levels[lvl][(expTick/w.spans[lvl])%w.slots]
+	b.mu.Lock()
+	b.timers[t.key] = t
+	t.bucket = b
+	b.mu.Unlock()
+}
+
+// advance 推进到 nowMs，返回期间到期的 key
+func (w *timingWheel) advance(nowMs int64) []string {
+	target := nowMs / w.tickMs
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.count.Load() == 0 {
+		if target > w.curTick {
+			atomic.StoreInt64(&w.curTick, target)
+		}
+		return nil
+	}
+	var expired []string
+	for w.curTick < target {
+		tick := atomic.AddInt64(&w.curTick, 1)
+		// 自顶向下级联：高层槽位内的定时器下沉到低层
+		for l := len(w.levels) - 1; l >= 1; l-- {
+			if tick%w.spans[l] != 0 {
+				continue
+			}
+			for _, t := range w.levels[l][(tick/w.spans[l])%w.slots].drain() {
+				w.place(t, tick)
+			}
+		}
+		for _, t := range w.levels[0][tick%w.slots].drain() {
+			if t.expireMs/w.tickMs > tick {
+				w.place(t, tick+1)
+				continue
+			}
+			sh := w.shard(t.key)
+			sh.mu.Lock()
+			if sh.timers[t.key] == t {
+				delete(sh.timers, t.key)
+				w.count.Add(-1)
+			}
+			sh.mu.Unlock()
+			expired = append(expired, t.key)
+		}
+	}
+	return expired
+}
+
+// reset 清空全部定时器
+func (w *timingWheel) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, lv := range w.levels {
+		for _, b := range lv {
+			b.drain()
+		}
+	}
+	for _, sh := range w.shards {
+		sh.mu.Lock()
+		sh.timers = make(map[string]*wheelTimer)
+		sh.mu.Unlock()
+	}
+	w.count.Store(0)
+}
+
+func (b *wheelBucket) remove(t *wheelTimer) {
+	b.mu.Lock()
+	if b.timers[t.key] == t {
+		delete(b.timers, t.key)
+	}
+	b.mu.Unlock()
+}
+
+func (b *wheelBucket) drain() []*wheelTimer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.timers) == 0 {
+		return nil
+	}
+	out := make([]*wheelTimer, 0, len(b.timers))
+	for _, t := range b.timers {
+		out = append(out, t)
+	}
+	b.timers = make(map[string]*wheelTimer)
+	return out
+}
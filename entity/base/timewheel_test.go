@@ -0,0 +1,90 @@
+package base
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTimingWheel_FireAtMillis(t *testing.T) {
+	w := newTimingWheel(0, 1, 60, 4, 8)
+	w.schedule("a", 5)
+	w.schedule("b", 59)
+	if got := w.advance(4); len(got) != 0 {
+		t.Fatalf("fired too early: %v", got)
+	}
+	if got := w.advance(5); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("want [a] at 5ms, got %v", got)
+	}
+	if got := w.advance(58); len(got) != 0 {
+		t.Fatalf("fired too early: %v", got)
+	}
+	if got := w.advance(59); len(got) != 1 || got[0] != "b" {
+		t.Fatalf("want [b] at 59ms, got %v", got)
+	}
+	if w.len() != 0 {
+		t.Fatalf("wheel should be empty, len=%d", w.len())
+	}
+}
+
+func TestTimingWheel_CascadeBeyondOneLap(t *testing.T) {
+	// tick=10ms, 60 槽：第 0 层覆盖 600ms，第 1 层 36s，第 2 层 36min
+	w := newTimingWheel(0, 10, 60, 3, 8)
+	w.schedule("short", 900)
+	w.schedule("long", 90_000)
+	w.schedule("overflow", 3*3600*1000) // 超出顶层范围
+	fired := map[string]int64{}
+	for now := int64(10); now <= 3*3600*1000; now += 10 {
+		for _, k := range w.advance(now) {
+			fired[k] = now
+		}
+	}
+	for k, want := range map[string]int64{"short": 900, "long": 90_000, "overflow": 3 * 3600 * 1000} {
+		if fired[k] != want {
+			t.Fatalf("%s fired at %d, want %d", k, fired[k], want)
+		}
+	}
+}
+
+func TestTimingWheel_RescheduleAndCancel(t *testing.T) {
+	w := newTimingWheel(0, 1, 16, 3, 4)
+	w.schedule("k", 10)
+	w.schedule("k", 100)
+	w.schedule("c", 20)
+	w.cancel("c")
+	if w.len() != 1 {
+		t.Fatalf("len=%d want 1", w.len())
+	}
+	if got := w.advance(50); len(got) != 0 {
+		t.Fatalf("rescheduled/cancelled timers fired: %v", got)
+	}
+	if got := w.advance(100); len(got) != 1 || got[0] != "k" {
+		t.Fatalf("want [k], got %v", got)
+	}
+}
+
+func TestTimingWheel_ConcurrentSchedule(t *testing.T) {
+	w := newTimingWheel(0, 1, 60, 4, 16)
+	const n = 2000
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				w.schedule(fmt.Sprintf("%d/%d", g, i), int64(1+i%500))
+			}
+		}(g)
+	}
+	wg.Wait()
+	if w.len() != 8*n {
+		t.Fatalf("len=%d want %d", w.len(), 8*n)
+	}
+	total := 0
+	for now := int64(1); now <= 500; now++ {
+		total += len(w.advance(now))
+	}
+	if total != 8*n {
+		t.Fatalf("fired=%d want %d", total, 8*n)
+	}
+}
@@ -0,0 +1,419 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// TrackedSection 可独立序列化的实体分区
+type TrackedSection interface {
+	MarshalSection() ([]byte, error)
+	UnmarshalSection(data []byte) error
+}
+
+// Tracker 分区级变更追踪（字段级脏标）
+// 功能：
+// - 记录自上次保存以来发生变更的分区；变更时同步置实体整体脏标（Bind 的 DirtyOperator）
+// - 提供 DeltaSaveObject 中与分区相关的方法，实体嵌入后仅需实现 SaveObject 其余方法
+// 使用方式：
+//
+//	type Player struct {
+//		base.BaseEntity
+//		base.Tracker
+//		Bag  *base.TrackedMap[int32, int64]
+//		Name *base.TrackedValue[string]
+//	}
+//	func NewPlayer() *Player {
+//		p := &Player{}
+//		p.Tracker.Bind(p)
+//		p.Bag = base.NewTrackedMap[int32, int64](&p.Tracker, "bag")
+//		p.Name = base.NewTrackedValue(&p.Tracker, "name", "")
+//		return p
+//	}
+//
+// 说明：新注册的分区视为已变更（尚未落地）；从存储加载后由 UnmarshalSections 清除。
+// 保存并发安全：MarshalSections 记录快照代数，ClearSections 只清除快照之前的变更。
+type Tracker struct {
+	mu       sync.Mutex
+	owner    facade.DirtyOperator
+	sections map[string]TrackedSection
+	dirty    map[string]uint64 // section -> 最近一次变更的代数
+	gen      uint64
+	snapGen  uint64
+}
+
+// Bind 绑定实体脏标（建议在构造函数中调用）；分区变更时同步置脏
+func (t *Tracker) Bind(owner facade.DirtyOperator) {
+	t.mu.Lock()
+	t.owner = owner
+	t.mu.Unlock()
+}
+
+// Register 注册自定义分区；同名分区将被覆盖
+func (t *Tracker) Register(section string, s TrackedSection) {
+	t.mu.Lock()
+	if t.sections == nil {
+		t.sections = make(map[string]TrackedSection)
+	}
+	t.sections[section] = s
+	t.mu.Unlock()
+	t.Mark(section)
+}
+
+// Mark 标记分区已变更（原地修改指针类型值后需手动调用）
+func (t *Tracker) Mark(section string) {
+	t.mu.Lock()
+	if t.dirty == nil {
+		t.dirty = make(map[string]uint64)
+	}
+	t.gen++
+	t.dirty[section] = t.gen
+	owner := t.owner
+	t.mu.Unlock()
+	if owner != nil {
+		owner.SetDirty(true)
+	}
+}
+
+// Sections 返回全部分区名（有序）
+func (t *Tracker) Sections() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]string, 0, len(t.sections))
+	for s := range t.sections {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// DirtySections 返回已变更的分区名（有序）
+func (t *Tracker) DirtySections() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]string, 0, len(t.dirty))
+	for s := range t.dirty {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// HasChanges 是否存在未保存的变更
+func (t *Tracker) HasChanges() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.dirty) > 0
+}
+
+// MarshalSections 序列化指定分区并记录快照代数
+func (t *Tracker) MarshalSections(sections []string) (map[string][]byte, error) {
+	t.mu.Lock()
+	t.snapGen = t.gen
+	targets := make(map[string]TrackedSection, len(sections))
+	for _, name := range sections {
+		if s, ok := t.sections[name]; ok {
+			targets[name] = s
+		}
+	}
+	t.mu.Unlock()
+
+	out := make(map[string][]byte, len(targets))
+	for name, s := range targets {
+		data, err := s.MarshalSection()
+		if err != nil {
+			return nil, err
+		}
+		out[name] = data
+	}
+	return out, nil
+}
+
+// UnmarshalSections 从分区数据恢复；恢复的分区视为已落地
+func (t *Tracker) UnmarshalSections(fields map[string][]byte) error {
+	t.mu.Lock()
+	targets := make(map[string]TrackedSection, len(fields))
+	for name := range fields {
+		if s, ok := t.sections[name]; ok {
+			targets[name] = s
+		}
+	}
+	t.mu.Unlock()
+
+	for name, s := range targets {
+		if err := s.UnmarshalSection(fields[name]); err != nil {
+			return err
+		}
+	}
+	t.mu.Lock()
+	for name := range targets {
+		delete(t.dirty, name)
+	}
+	t.mu.Unlock()
+	return nil
+}
+
+// LoadSections 从按字段存储的驱动恢复增量保存的实体，返回存储中的 schema 版本
+// 存储中缺少的分区（如新增分区）保持变更标记，下次保存时写入；全部分区均已落地时清除实体脏标。
+// 实体不存在时返回 facade.ErrNotFound。
+func LoadSections(ctx context.Context, fs facade.FieldStorage, typeName, id string, e facade.DeltaSaveObject) (int, error) {
+	fields, schema, err := fs.GetFields(ctx, typeName, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.UnmarshalSections(fields); err != nil {
+		return 0, fmt.Errorf("%w: sections of %s/%s: %v", facade.ErrDecode, typeName, id, err)
+	}
+	if d, ok := e.(facade.DirtyOperator); ok && len(e.DirtySections()) == 0 {
+		d.SetDirty(false)
+	}
+	return schema, nil
+}
+
+// DeltaLoader 返回按字段加载实体的 NotFoundHook（RegisterNotFoundHook 使用）
+// ctor 构造新实例（需已注册全部分区）；存储中不存在时返回 facade.ErrNotFound。
+func DeltaLoader[T interface {
+	facade.Entity
+	facade.DeltaSaveObject
+}](fs facade.FieldStorage, typeName string, ctor func() T) func(ctx context.Context, id string) (facade.Entity, error) {
+	return func(ctx context.Context, id string) (facade.Entity, error) {
+		e := ctor()
+		if _, err := LoadSections(ctx, fs, typeName, id, e); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+}
+
+// ClearSections 清除指定分区在最近一次 MarshalSections 之前的变更标记
+func (t *Tracker) ClearSections(sections []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, name := range sections {
+		if g, ok := t.dirty[name]; ok && g <= t.snapGen {
+			delete(t.dirty, name)
+		}
+	}
+}
+
+// MarkSections 重新标记指定分区为已变更（快照数据写入失败时使用）
+func (t *Tracker) MarkSections(sections []string) {
+	for _, name := range sections {
+		t.Mark(name)
+	}
+}
+
+// TrackedValue 追踪单值分区
+type TrackedValue[T any] struct {
+	mu      sync.RWMutex
+	tracker *Tracker
+	section string
+	v       T
+}
+
+// NewTrackedValue 创建单值分区并注册到 tracker
+func NewTrackedValue[T any](t *Tracker, section string, v T) *TrackedValue[T] {
+	tv := &TrackedValue[T]{tracker: t, section: section, v: v}
+	t.Register(section, tv)
+	return tv
+}
+
+// Get 返回当前值
+func (v *TrackedValue[T]) Get() T {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.v
+}
+
+// Set 设置值并标记分区变更
+func (v *TrackedValue[T]) Set(val T) {
+	v.mu.Lock()
+	v.v = val
+	v.mu.Unlock()
+	v.tracker.Mark(v.section)
+}
+
+func (v *TrackedValue[T]) MarshalSection() ([]byte, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return json.Marshal(v.v)
+}
+
+func (v *TrackedValue[T]) UnmarshalSection(data []byte) error {
+	var val T
+	if err := json.Unmarshal(data, &val); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	v.v = val
+	v.mu.Unlock()
+	return nil
+}
+
+// TrackedMap 追踪 map 分区
+type TrackedMap[K comparable, V any] struct {
+	mu      sync.RWMutex
+	tracker *Tracker
+	section string
+	m       map[K]V
+}
+
+// NewTrackedMap 创建 map 分区并注册到 tracker
+func NewTrackedMap[K comparable, V any](t *Tracker, section string) *TrackedMap[K, V] {
+	tm := &TrackedMap[K, V]{tracker: t, section: section, m: make(map[K]V)}
+	t.Register(section, tm)
+	return tm
+}
+
+// Get 按 key 读取
+func (m *TrackedMap[K, V]) Get(k K) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.m[k]
+	return v, ok
+}
+
+// Set 写入并标记分区变更
+func (m *TrackedMap[K, V]) Set(k K, v V) {
+	m.mu.Lock()
+	m.m[k] = v
+	m.mu.Unlock()
+	m.tracker.Mark(m.section)
+}
+
+// Delete 删除 key；key 存在时标记分区变更
+func (m *TrackedMap[K, V]) Delete(k K) {
+	m.mu.Lock()
+	_, ok := m.m[k]
+	delete(m.m, k)
+	m.mu.Unlock()
+	if ok {
+		m.tracker.Mark(m.section)
+	}
+}
+
+// Len 返回元素个数
+func (m *TrackedMap[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.m)
+}
+
+// Range 遍历（持有读锁，回调内不可修改该 map）
+func (m *TrackedMap[K, V]) Range(fn func(k K, v V) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for k, v := range m.m {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+func (m *TrackedMap[K, V]) MarshalSection() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return json.Marshal(m.m)
+}
+
+func (m *TrackedMap[K, V]) UnmarshalSection(data []byte) error {
+	val := make(map[K]V)
+	if err := json.Unmarshal(data, &val); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.m = val
+	m.mu.Unlock()
+	return nil
+}
+
+// TrackedSlice 追踪切片分区
+type TrackedSlice[T any] struct {
+	mu      sync.RWMutex
+	tracker *Tracker
+	section string
+	s       []T
+}
+
+// NewTrackedSlice 创建切片分区并注册到 tracker
+func NewTrackedSlice[T any](t *Tracker, section string) *TrackedSlice[T] {
+	ts := &TrackedSlice[T]{tracker: t, section: section}
+	t.Register(section, ts)
+	return ts
+}
+
+// Get 按下标读取
+func (s *TrackedSlice[T]) Get(i int) T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s[i]
+}
+
+// Set 按下标写入并标记分区变更
+func (s *TrackedSlice[T]) Set(i int, v T) {
+	s.mu.Lock()
+	s.s[i] = v
+	s.mu.Unlock()
+	s.tracker.Mark(s.section)
+}
+
+// Append 追加并标记分区变更
+func (s *TrackedSlice[T]) Append(vs ...T) {
+	if len(vs) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.s = append(s.s, vs...)
+	s.mu.Unlock()
+	s.tracker.Mark(s.section)
+}
+
+// Reset 整体替换内容并标记分区变更
+func (s *TrackedSlice[T]) Reset(vs []T) {
+	s.mu.Lock()
+	s.s = append([]T(nil), vs...)
+	s.mu.Unlock()
+	s.tracker.Mark(s.section)
+}
+
+// Len 返回元素个数
+func (s *TrackedSlice[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.s)
+}
+
+// Values 返回内容副本
+func (s *TrackedSlice[T]) Values() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]T(nil), s.s...)
+}
+
+func (s *TrackedSlice[T]) MarshalSection() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return json.Marshal(s.s)
+}
+
+func (s *TrackedSlice[T]) UnmarshalSection(data []byte) error {
+	var val []T
+	if err := json.Unmarshal(data, &val); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.s = val
+	s.mu.Unlock()
+	return nil
+}
+
+var (
+	_ TrackedSection = (*TrackedValue[int])(nil)
+	_ TrackedSection = (*TrackedMap[string, int])(nil)
+	_ TrackedSection = (*TrackedSlice[int])(nil)
+)
@@ -0,0 +1,144 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/entity/store"
+)
+
+type trackedEntity struct {
+	BaseEntity
+	Tracker
+	Name *TrackedValue[string]
+	Bag  *TrackedMap[string, int]
+	Mail *TrackedSlice[string]
+}
+
+func newTrackedEntity() *trackedEntity {
+	e := &trackedEntity{}
+	e.SetTypeName("player")
+	e.Tracker.Bind(e)
+	e.Name = NewTrackedValue(&e.Tracker, "name", "")
+	e.Bag = NewTrackedMap[string, int](&e.Tracker, "bag")
+	e.Mail = NewTrackedSlice[string](&e.Tracker, "mail")
+	return e
+}
+
+func (e *trackedEntity) LogicType() facade.LType           { return 0 }
+func (e *trackedEntity) OwnerID() string                   { return e.ID() }
+func (e *trackedEntity) MarshalBinary() ([]byte, error)    { return nil, facade.ErrEncode }
+func (e *trackedEntity) UnmarshalBinary(data []byte) error { return facade.ErrDecode }
+func (e *trackedEntity) GetSchemaVersion() int             { return 1 }
+
+func TestTracker_DirtySectionsAndClear(t *testing.T) {
+	e := newTrackedEntity()
+	if got := e.DirtySections(); !reflect.DeepEqual(got, []string{"bag", "mail", "name"}) {
+		t.Fatalf("new sections should be dirty, got %v", got)
+	}
+	if !e.IsDirty() {
+		t.Fatalf("owner should be dirty")
+	}
+	fields, err := e.MarshalSections(e.DirtySections())
+	if err != nil || len(fields) != 3 {
+		t.Fatalf("marshal: %v %v", fields, err)
+	}
+	e.ClearSections(e.Sections())
+	if e.HasChanges() {
+		t.Fatalf("should be clean, got %v", e.DirtySections())
+	}
+
+	e.Bag.Set("sword", 1)
+	e.Bag.Delete("missing") // 不存在的 key 不产生变更
+	if got := e.DirtySections(); !reflect.DeepEqual(got, []string{"bag"}) {
+		t.Fatalf("dirty=%v, want [bag]", got)
+	}
+	fields, err = e.MarshalSections([]string{"bag"})
+	if err != nil || string(fields["bag"]) != `{"sword":1}` {
+		t.Fatalf("marshal bag: %q %v", fields["bag"], err)
+	}
+	// 序列化之后的变更不被清除
+	e.Bag.Set("shield", 1)
+	e.ClearSections([]string{"bag"})
+	if got := e.DirtySections(); !reflect.DeepEqual(got, []string{"bag"}) {
+		t.Fatalf("change after snapshot lost, dirty=%v", got)
+	}
+}
+
+func TestTracker_UnmarshalSections(t *testing.T) {
+	src := newTrackedEntity()
+	src.Name.Set("alice")
+	src.Bag.Set("gold", 10)
+	src.Mail.Append("hi", "bye")
+	fields, err := src.MarshalSections(src.Sections())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := newTrackedEntity()
+	if err := dst.UnmarshalSections(fields); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if dst.HasChanges() {
+		t.Fatalf("loaded sections should be clean, got %v", dst.DirtySections())
+	}
+	if dst.Name.Get() != "alice" {
+		t.Fatalf("name=%q", dst.Name.Get())
+	}
+	if v, ok := dst.Bag.Get("gold"); !ok || v != 10 {
+		t.Fatalf("bag gold=%d,%v", v, ok)
+	}
+	if got := dst.Mail.Values(); !reflect.DeepEqual(got, []string{"hi", "bye"}) {
+		t.Fatalf("mail=%v", got)
+	}
+}
+
+func TestDeltaLoader_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fs := store.NewMemoryStore()
+	mgr := NewMemoryManager()
+	mgr.RegisterNotFoundHook("player", DeltaLoader(fs, "player", newTrackedEntity))
+
+	if _, err := mgr.Get(ctx, "player", "p1"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("want ErrNotFound before save, got %v", err)
+	}
+
+	// 保存 name 与 bag 两个分区；mail 视为之后新增的分区，存储中不存在
+	src := newTrackedEntity()
+	src.Name.Set("alice")
+	src.Bag.Set("gold", 10)
+	fields, err := src.MarshalSections([]string{"name", "bag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.PutFields(ctx, "player", "p1", fields, src.GetSchemaVersion()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := mgr.Get(ctx, "player", "p1")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	e := got.(*trackedEntity)
+	if e.Name.Get() != "alice" {
+		t.Fatalf("name=%q", e.Name.Get())
+	}
+	if v, _ := e.Bag.Get("gold"); v != 10 {
+		t.Fatalf("bag gold=%d", v)
+	}
+	// 缺失的分区保持变更，下次保存时写入
+	if got := e.DirtySections(); !reflect.DeepEqual(got, []string{"mail"}) || !e.IsDirty() {
+		t.Fatalf("dirty=%v isDirty=%v, want [mail]", got, e.IsDirty())
+	}
+
+	// 全部分区已落地时实体不再为脏
+	fresh := newTrackedEntity()
+	all, _ := src.MarshalSections(src.Sections())
+	_ = fs.PutFields(ctx, "player", "p2", all, 1)
+	if _, err := LoadSections(ctx, fs, "player", "p2", fresh); err != nil || fresh.IsDirty() {
+		t.Fatalf("load p2: err=%v dirty=%v", err, fresh.IsDirty())
+	}
+}
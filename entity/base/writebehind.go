@@ -0,0 +1,702 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// SaveSink 保存管道：接收到期需保存的实体，负责快照与落地
+// MemoryManager 设置 SaveSink 后，scanSaveOnce 仅投递实体，不再在后台协程内同步调用 Save。
+type SaveSink interface {
+	Enqueue(ctx context.Context, e facade.Entity) error
+}
+
+// ActorRunner 在实体执行器内运行 fn（与业务调用串行），如 call.CallSystemImpl.RunInActor
+type ActorRunner func(ctx context.Context, entityType, id string, fn func()) error
+
+type writeBehindOptions struct {
+	BatchSize    int
+	Concurrency  int
+	Interval     time.Duration
+	FlushTimeout time.Duration
+	MaxRetries   int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	DeadLetter   func(rec *facade.SaveRecord, err error)
+	Events       *EventBus
+}
+
+// WriteBehindOption write-behind 管道选项
+type WriteBehindOption func(*writeBehindOptions)
+
+// WithWriteBehindBatchSize 单批最大记录数（默认 128）
+func WithWriteBehindBatchSize(n int) WriteBehindOption {
+	return func(o *writeBehindOptions) { o.BatchSize = n }
+}
+
+// WithWriteBehindConcurrency 同时进行的批量写入数（默认 4）
+func WithWriteBehindConcurrency(n int) WriteBehindOption {
+	return func(o *writeBehindOptions) { o.Concurrency = n }
+}
+
+// WithWriteBehindInterval 批量刷写间隔（默认 100ms；积压达到 BatchSize 时立即刷写）
+func WithWriteBehindInterval(d time.Duration) WriteBehindOption {
+	return func(o *writeBehindOptions) { o.Interval = d }
+}
+
+// WithWriteBehindFlushTimeout 单批写入超时（默认 5s）
+func WithWriteBehindFlushTimeout(d time.Duration) WriteBehindOption {
+	return func(o *writeBehindOptions) { o.FlushTimeout = d }
+}
+
+// WithWriteBehindRetry 最大重试次数与指数退避区间（默认 5 次，100ms ~ 5s）
+func WithWriteBehindRetry(maxRetries int, base, max time.Duration) WriteBehindOption {
+	return func(o *writeBehindOptions) { o.MaxRetries, o.BackoffBase, o.BackoffMax = maxRetries, base, max }
+}
+
+// WithWriteBehindDeadLetter 设置死信回调（重试耗尽的记录；默认打印错误日志）
+// 经 Enqueue/EnqueueGroup 提交的记录进入死信后，仍在内存中的实体会被重新置脏，下个保存周期再次快照。
+func WithWriteBehindDeadLetter(fn func(rec *facade.SaveRecord, err error)) WriteBehindOption {
+	return func(o *writeBehindOptions) { o.DeadLetter = fn }
+}
+
+// WithWriteBehindEvents 写出成功后向事件总线发布 EventSaved（如 MemoryManager.Events()）
+func WithWriteBehindEvents(bus *EventBus) WriteBehindOption {
+	return func(o *writeBehindOptions) { o.Events = bus }
+}
+
+// WriteBehindStats 管道积压与累计计数
+type WriteBehindStats struct {
+	Pending             int    // 待写入单元数（实体或实体组，已合并）
+	InFlight            int    // 写入中的单元数
+	OldestPendingMillis int64  // 最早一条待写入快照的等待时长
+	Flushed             uint64 // 写入成功的记录数
+	Coalesced           uint64 // 被合并（覆盖）的快照数
+	Retried             uint64 // 写入失败后重试的记录数
+	DeadLettered        uint64 // 进入死信的记录数
+	SnapshotErrors      uint64 // 快照失败次数（实体保持脏标，下个周期重试）
+}
+
+// wbItem 一个写出单元：单个实体一条记录，实体组为组内各成员的记录（总在同一批内写出）
+type wbItem struct {
+	key        string
+	recs       []*facade.SaveRecord
+	owners     map[string]facade.Entity // type/id -> 快照来源实体（Submit 提交的记录没有）
+	attempts   int
+	notBefore  time.Time
+	enqueuedAt time.Time
+}
+
+// has 单元是否包含指定实体的记录
+func (it *wbItem) has(typeName, id string) bool {
+	for _, rec := range it.recs {
+		if rec.TypeName == typeName && rec.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteBehind 写后（write-behind）批量保存管道
+// 流程：
+// - Enqueue：在实体执行器内快照（SaveObject 全量 / DeltaSaveObject 变更分区），随后清除脏标
+// - 合并：同一实体尚未写出的快照以最新为准（增量分区按字段合并）
+// - 实体组：EnqueueGroup 在组共享执行器内一次快照全部成员，作为一个单元合并、重试并在同一批内写出
+// - 刷写：按批调用 BatchStorage.BatchPut，并发受限；同一实体同一时刻至多一个写入在途，保证顺序
+// - 失败：指数退避重试，耗尽后进入死信，并在实体执行器内重新置脏（增量实体重新标记已快照的分区）
+// 说明：快照后数据由管道负责落地，实体侧脏标已清除；进程退出前需调用 Close 刷写积压。
+type WriteBehind struct {
+	store  facade.BatchStorage
+	runner ActorRunner
+	opts   writeBehindOptions
+
+	mu       sync.Mutex
+	pending  map[string]*wbItem
+	order    []string // 待写入键的 FIFO（可能包含已被取走的键，取批时清理）
+	inflight map[string]*wbItem
+	// discarded 在途期间被丢弃的实体键：在途批次失败时不再重试或置脏
+	discarded map[string]struct{}
+
+	kick   chan struct{}
+	sem    chan struct{}
+	wg     sync.WaitGroup
+	stopCh chan struct{}
+	doneCh chan struct{}
+	closed atomic.Bool
+
+	flushed, coalesced, retried, deadLettered, snapshotErrors atomic.Uint64
+}
+
+// NewWriteBehind 创建并启动 write-behind 管道
+// runner 在实体执行器内运行快照与死信后的重新置脏（如 call.CallSystemImpl.RunInActor），
+// 以免与实体业务调用并发访问实体状态；store 或 runner 为 nil 时返回 ErrInvalidConfig。
+func NewWriteBehind(store facade.BatchStorage, runner ActorRunner, opts ...WriteBehindOption) (*WriteBehind, error) {
+	if store == nil || runner == nil {
+		return nil, fmt.Errorf("%w: write-behind requires batch storage and actor runner", facade.ErrInvalidConfig)
+	}
+	o := writeBehindOptions{
+		BatchSize:    128,
+		Concurrency:  4,
+		Interval:     100 * time.Millisecond,
+		FlushTimeout: 5 * time.Second,
+		MaxRetries:   5,
+		BackoffBase:  100 * time.Millisecond,
+		BackoffMax:   5 * time.Second,
+	}
+	for _, fn := range opts {
+		fn(&o)
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 1
+	}
+	if o.Interval <= 0 {
+		o.Interval = 100 * time.Millisecond
+	}
+	if o.DeadLetter == nil {
+		o.DeadLetter = func(rec *facade.SaveRecord, err error) {
+			log.Errorf("entity: write-behind dead letter %s/%s: %v", rec.TypeName, rec.ID, err)
+		}
+	}
+	w := &WriteBehind{
+		store:     store,
+		runner:    runner,
+		opts:      o,
+		pending:   make(map[string]*wbItem),
+		inflight:  make(map[string]*wbItem),
+		discarded: make(map[string]struct{}),
+		kick:      make(chan struct{}, 1),
+		sem:       make(chan struct{}, o.Concurrency),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+	go w.loop()
+	return w, nil
+}
+
+// Enqueue 在实体执行器内快照实体并放入管道（实现 SaveSink）
+func (w *WriteBehind) Enqueue(ctx context.Context, e facade.Entity) error {
+	if w.closed.Load() {
+		return context.Canceled
+	}
+	fn := func() {
+		rec, err := snapshotEntity(ctx, e)
+		if err != nil {
+			w.snapshotErrors.Add(1)
+			log.Errorf("entity: write-behind snapshot %s/%s: %v", e.Type(), e.ID(), err)
+			return
+		}
+		if rec != nil {
+			key := makeKey(rec.TypeName, rec.ID)
+			w.submit(key, []*facade.SaveRecord{rec}, map[string]facade.Entity{key: e})
+		}
+	}
+	return w.runner(ctx, e.Type(), e.ID(), fn)
+}
+
+// EnqueueGroup 在实体组共享执行器内一次快照全部成员，作为一个单元放入管道（实现 GroupSaveSink）
+// 个别成员快照失败时其余成员照常提交，失败成员保持脏标于下个周期重试
+func (w *WriteBehind) EnqueueGroup(ctx context.Context, group, id string, es []facade.Entity) error {
+	if w.closed.Load() {
+		return context.Canceled
+	}
+	if len(es) == 0 {
+		return nil
+	}
+	fn := func() {
+		recs := make([]*facade.SaveRecord, 0, len(es))
+		owners := make(map[string]facade.Entity, len(es))
+		for _, e := range es {
+			if _, ok := e.(facade.SaveAble); !ok {
+				continue
+			}
+			rec, err := snapshotEntity(ctx, e)
+			if err != nil {
+				w.snapshotErrors.Add(1)
+				log.Errorf("entity: write-behind snapshot %s/%s in group %s: %v", e.Type(), e.ID(), group, err)
+				continue
+			}
+			if rec != nil {
+				recs = append(recs, rec)
+				owners[makeKey(rec.TypeName, rec.ID)] = e
+			}
+		}
+		if len(recs) > 0 {
+			w.submit(groupKey(group, id), recs, owners)
+		}
+	}
+	return w.runner(ctx, es[0].Type(), id, fn)
+}
+
+// Submit 直接提交一条快照（与同一实体未写出的快照合并）
+func (w *WriteBehind) Submit(rec *facade.SaveRecord) {
+	w.submit(makeKey(rec.TypeName, rec.ID), []*facade.SaveRecord{rec}, nil)
+}
+
+func (w *WriteBehind) submit(key string, recs []*facade.SaveRecord, owners map[string]facade.Entity) {
+	w.mu.Lock()
+	for _, rec := range recs {
+		delete(w.discarded, makeKey(rec.TypeName, rec.ID))
+	}
+	if it, ok := w.pending[key]; ok {
+		it.recs = mergeSaveRecords(it.recs, recs)
+		it.owners = mergeOwners(it.owners, owners)
+		it.attempts = 0
+		it.notBefore = time.Time{}
+		w.coalesced.Add(1)
+	} else {
+		w.pending[key] = &wbItem{key: key, recs: recs, owners: owners, enqueuedAt: time.Now()}
+		w.order = append(w.order, key)
+	}
+	full := len(w.pending) >= w.opts.BatchSize
+	w.mu.Unlock()
+	if full {
+		w.signal()
+	}
+}
+
+// Flush 同步写出全部积压（忽略退避），直到清空或 ctx 结束
+func (w *WriteBehind) Flush(ctx context.Context) error {
+	for {
+		batch := w.takeBatch(time.Time{})
+		if len(batch) > 0 {
+			w.flushBatch(batch)
+			continue
+		}
+		w.mu.Lock()
+		idle := len(w.pending) == 0 && len(w.inflight) == 0
+		w.mu.Unlock()
+		if idle {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+// Close 停止后台刷写并同步写出剩余积压
+func (w *WriteBehind) Close(ctx context.Context) error {
+	if !w.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	close(w.stopCh)
+	<-w.doneCh
+	w.wg.Wait()
+	return w.Flush(ctx)
+}
+
+// Discard 丢弃实体尚未写出的快照（删除实体时调用，避免删除后被积压的快照写回）
+// 已在途的写入无法撤回，但会留下墓碑使其失败后不再重试；返回是否丢弃了快照。
+func (w *WriteBehind) Discard(typeName, id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	key := makeKey(typeName, id)
+	found := false
+	for _, it := range w.inflight {
+		if it.has(typeName, id) {
+			w.discarded[key] = struct{}{}
+			found = true
+			break
+		}
+	}
+	if _, ok := w.pending[key]; ok {
+		delete(w.pending, key)
+		return true
+	}
+	// 实体组单元：仅移除该成员的记录
+	for gk, it := range w.pending {
+		if !strings.HasPrefix(gk, "#") {
+			continue
+		}
+		for i, rec := range it.recs {
+			if rec.TypeName != typeName || rec.ID != id {
+				continue
+			}
+			it.recs = append(it.recs[:i:i], it.recs[i+1:]...)
+			if len(it.recs) == 0 {
+				delete(w.pending, gk)
+			}
+			return true
+		}
+	}
+	return found
+}
+
+// Stats 返回积压与累计计数快照
+func (w *WriteBehind) Stats() WriteBehindStats {
+	now := time.Now()
+	w.mu.Lock()
+	s := WriteBehindStats{Pending: len(w.pending), InFlight: len(w.inflight)}
+	for _, it := range w.pending {
+		if d := now.Sub(it.enqueuedAt).Milliseconds(); d > s.OldestPendingMillis {
+			s.OldestPendingMillis = d
+		}
+	}
+	w.mu.Unlock()
+	s.Flushed = w.flushed.Load()
+	s.Coalesced = w.coalesced.Load()
+	s.Retried = w.retried.Load()
+	s.DeadLettered = w.deadLettered.Load()
+	s.SnapshotErrors = w.snapshotErrors.Load()
+	return s
+}
+
+func (w *WriteBehind) signal() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (w *WriteBehind) loop() {
+	defer close(w.doneCh)
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+		case <-w.kick:
+		}
+		w.dispatch()
+	}
+}
+
+// dispatch 取出可写批次并发刷写；并发已满时阻塞等待（期间新快照继续合并）
+func (w *WriteBehind) dispatch() {
+	for {
+		batch := w.takeBatch(time.Now())
+		if len(batch) == 0 {
+			return
+		}
+		select {
+		case w.sem <- struct{}{}:
+		case <-w.stopCh:
+			w.requeue(batch)
+			return
+		}
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			defer func() { <-w.sem }()
+			w.flushBatch(batch)
+		}()
+	}
+}
+
+// takeBatch 按 FIFO 取出至多 BatchSize 条可写记录并标记在途；now 为零值时忽略退避
+func (w *WriteBehind) takeBatch(now time.Time) []*wbItem {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var batch []*wbItem
+	rest := w.order[:0]
+	for _, key := range w.order {
+		it, ok := w.pending[key]
+		if !ok {
+			continue
+		}
+		_, busy := w.inflight[key]
+		if busy || len(batch) >= w.opts.BatchSize || (!now.IsZero() && now.Before(it.notBefore)) {
+			rest = append(rest, key)
+			continue
+		}
+		delete(w.pending, key)
+		w.inflight[key] = it
+		batch = append(batch, it)
+	}
+	w.order = rest
+	return batch
+}
+
+// requeue 将未写出的批次放回（不计入重试）
+func (w *WriteBehind) requeue(batch []*wbItem) {
+	w.mu.Lock()
+	for _, it := range batch {
+		w.putBackLocked(it)
+	}
+	w.mu.Unlock()
+}
+
+// putBackLocked 释放在途标记并放回待写；若已有更新的快照，则以旧快照为底合并
+// 在途期间已被丢弃的记录不再放回
+func (w *WriteBehind) putBackLocked(it *wbItem) {
+	delete(w.inflight, it.key)
+	w.dropDiscardedLocked(it)
+	if len(it.recs) == 0 {
+		return
+	}
+	if newer, ok := w.pending[it.key]; ok {
+		newer.recs = mergeSaveRecords(it.recs, newer.recs)
+		newer.owners = mergeOwners(it.owners, newer.owners)
+		newer.enqueuedAt = it.enqueuedAt
+		return
+	}
+	w.pending[it.key] = it
+	w.order = append(w.order, it.key)
+}
+
+func (w *WriteBehind) flushBatch(batch []*wbItem) {
+	var recs []*facade.SaveRecord
+	for _, it := range batch {
+		recs = append(recs, it.recs...)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), w.opts.FlushTimeout)
+	errs := w.store.BatchPut(ctx, recs)
+	// 驱动支持二级索引：数据写入成功的记录随后写入索引，失败按写入失败重试
+	if is, ok := w.store.(facade.IndexStorage); ok {
+		for i, rec := range recs {
+			if rec.Index == nil || (errs != nil && i < len(errs) && errs[i] != nil) {
+				continue
+			}
+			if err := is.PutIndex(ctx, rec.TypeName, rec.ID, rec.Index); err != nil {
+				if errs == nil {
+					errs = make([]error, len(recs))
+				}
+				errs[i] = err
+			}
+		}
+	}
+	cancel()
+
+	now := time.Now()
+	var saved []*facade.SaveRecord
+	var dead []*wbItem
+	var deadErrs []error
+	w.mu.Lock()
+	off := 0
+	for _, it := range batch {
+		// 单元内任一记录失败即整体重试（写入需幂等）
+		var err error
+		for i := off; i < off+len(it.recs) && errs != nil; i++ {
+			if i < len(errs) && errs[i] != nil {
+				err = errs[i]
+				break
+			}
+		}
+		off += len(it.recs)
+		if err == nil {
+			delete(w.inflight, it.key)
+			w.flushed.Add(uint64(len(it.recs)))
+			saved = append(saved, it.recs...)
+			w.dropDiscardedLocked(it)
+			continue
+		}
+		it.attempts++
+		if it.attempts > w.opts.MaxRetries {
+			delete(w.inflight, it.key)
+			w.dropDiscardedLocked(it)
+			if len(it.recs) == 0 {
+				continue
+			}
+			dead = append(dead, it)
+			deadErrs = append(deadErrs, err)
+			continue
+		}
+		it.notBefore = now.Add(w.backoff(it.attempts))
+		w.retried.Add(1)
+		w.putBackLocked(it)
+	}
+	w.mu.Unlock()
+
+	for i, it := range dead {
+		for _, rec := range it.recs {
+			w.deadLettered.Add(1)
+			w.opts.DeadLetter(rec, deadErrs[i])
+			if e := it.owners[makeKey(rec.TypeName, rec.ID)]; e != nil {
+				w.markUnsaved(e, rec)
+			}
+		}
+	}
+	if bus := w.opts.Events; bus != nil && bus.Enabled(facade.EventSaved) {
+		ms := now.UnixMilli()
+		for _, rec := range saved {
+			bus.Publish(context.Background(), facade.EntityEvent{Kind: facade.EventSaved, EntityType: rec.TypeName, ID: rec.ID, TimeMs: ms})
+		}
+	}
+}
+
+// markUnsaved 在实体执行器内撤销快照时清除的变更标记，使实体于下个保存周期重新快照
+func (w *WriteBehind) markUnsaved(e facade.Entity, rec *facade.SaveRecord) {
+	err := w.runner(context.Background(), e.Type(), e.ID(), func() {
+		if so, ok := e.(facade.DeltaSaveObject); ok && len(rec.Fields) > 0 {
+			sections := make([]string, 0, len(rec.Fields))
+			for name := range rec.Fields {
+				sections = append(sections, name)
+			}
+			so.MarkSections(sections)
+		}
+		if d, ok := e.(facade.DirtyOperator); ok {
+			d.SetDirty(true)
+		}
+	})
+	if err != nil {
+		log.Errorf("entity: write-behind re-mark %s/%s after dead letter: %v", e.Type(), e.ID(), err)
+	}
+}
+
+// dropDiscardedLocked 移除在途期间被丢弃的记录并清除其墓碑
+func (w *WriteBehind) dropDiscardedLocked(it *wbItem) {
+	if len(w.discarded) == 0 {
+		return
+	}
+	kept := it.recs[:0:0]
+	for _, rec := range it.recs {
+		key := makeKey(rec.TypeName, rec.ID)
+		if _, ok := w.discarded[key]; ok {
+			delete(w.discarded, key)
+			continue
+		}
+		kept = append(kept, rec)
+	}
+	it.recs = kept
+}
+
+// backoff 第 n 次失败后的退避时长
+func (w *WriteBehind) backoff(n int) time.Duration {
+	d := w.opts.BackoffBase
+	for i := 1; i < n && d < w.opts.BackoffMax; i++ {
+		d *= 2
+	}
+	if w.opts.BackoffMax > 0 && d > w.opts.BackoffMax {
+		d = w.opts.BackoffMax
+	}
+	return d
+}
+
+// groupKey 实体组写出单元的键（与实体键区分）
+func groupKey(group, id string) string { return "#" + group + "/" + id }
+
+// mergeOwners 合并快照来源实体
+func mergeOwners(older, newer map[string]facade.Entity) map[string]facade.Entity {
+	if len(older) == 0 {
+		return newer
+	}
+	if len(newer) == 0 {
+		return older
+	}
+	out := make(map[string]facade.Entity, len(older)+len(newer))
+	for k, e := range older {
+		out[k] = e
+	}
+	for k, e := range newer {
+		out[k] = e
+	}
+	return out
+}
+
+// mergeSaveRecords 按实体类型合并两次快照集合；仅出现在旧集合中的成员保留
+func mergeSaveRecords(older, newer []*facade.SaveRecord) []*facade.SaveRecord {
+	out := make([]*facade.SaveRecord, 0, len(older)+len(newer))
+	used := make([]bool, len(newer))
+	for _, o := range older {
+		merged := o
+		for j, n := range newer {
+			if n.TypeName == o.TypeName && n.ID == o.ID {
+				merged, used[j] = mergeSaveRecord(o, n), true
+				break
+			}
+		}
+		out = append(out, merged)
+	}
+	for j, n := range newer {
+		if !used[j] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// mergeSaveRecord 合并同一实体的两次快照：全量以新为准，增量分区按字段覆盖
+func mergeSaveRecord(older, newer *facade.SaveRecord) *facade.SaveRecord {
+	out := &facade.SaveRecord{TypeName: newer.TypeName, ID: newer.ID, Schema: newer.Schema, Payload: newer.Payload, Index: newer.Index}
+	if out.Payload == nil {
+		out.Payload = older.Payload
+	}
+	if out.Index == nil {
+		out.Index = older.Index
+	}
+	if len(older.Fields) > 0 || len(newer.Fields) > 0 {
+		out.Fields = make(map[string][]byte, len(older.Fields)+len(newer.Fields))
+		if newer.Payload == nil {
+			for k, v := range older.Fields {
+				out.Fields[k] = v
+			}
+		}
+		for k, v := range newer.Fields {
+			out.Fields[k] = v
+		}
+	}
+	return out
+}
+
+// snapshotEntity 生成保存快照（需在实体执行器内调用）并清除脏标
+// 非 SaveObject 的 SaveAble 实体退化为同步 Save，返回 nil 记录
+func snapshotEntity(ctx context.Context, e facade.Entity) (*facade.SaveRecord, error) {
+	rec := &facade.SaveRecord{TypeName: e.Type(), ID: e.ID()}
+	if _, ok := e.(facade.EventSourced); ok {
+		// 事件溯源实体：数据已在日志中，同步写快照即可
+		return nil, saveSync(ctx, e)
+	}
+	if io, ok := e.(facade.IndexedObject); ok {
+		// 非 nil：空取值表示移除该实体的全部索引项
+		if rec.Index = io.IndexValues(); rec.Index == nil {
+			rec.Index = map[string]facade.IndexValue{}
+		}
+	}
+	switch so := e.(type) {
+	case facade.DeltaSaveObject:
+		sections := so.DirtySections()
+		if len(sections) == 0 {
+			return nil, nil
+		}
+		fields, err := so.MarshalSections(sections)
+		if err != nil {
+			return nil, err
+		}
+		so.ClearSections(sections)
+		rec.Fields, rec.Schema = fields, so.GetSchemaVersion()
+		if d, ok := e.(facade.DirtyOperator); ok && len(so.DirtySections()) == 0 {
+			d.SetDirty(false)
+		}
+		return rec, nil
+	case facade.SaveObject:
+		payload, err := so.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		rec.Payload, rec.Schema = payload, so.GetSchemaVersion()
+		if d, ok := e.(facade.DirtyOperator); ok {
+			d.SetDirty(false)
+		}
+		return rec, nil
+	}
+	return nil, saveSync(ctx, e)
+}
+
+// saveSync 不支持快照的实体走其自身的同步 Save
+func saveSync(ctx context.Context, e facade.Entity) error {
+	s, ok := e.(facade.SaveAble)
+	if !ok {
+		return facade.ErrEncode
+	}
+	if err := s.Save(ctx); err != nil {
+		return err
+	}
+	s.SetDirty(false)
+	return nil
+}
+
+var _ GroupSaveSink = (*WriteBehind)(nil)
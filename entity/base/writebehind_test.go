@@ -0,0 +1,265 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+type fakeBatchStore struct {
+	mu      sync.Mutex
+	batches [][]*facade.SaveRecord
+	fail    map[string]int // key -> 剩余失败次数（<0 表示永远失败）
+	rows    map[string]*facade.SaveRecord
+}
+
+func newFakeBatchStore() *fakeBatchStore {
+	return &fakeBatchStore{fail: map[string]int{}, rows: map[string]*facade.SaveRecord{}}
+}
+
+func (s *fakeBatchStore) BatchPut(ctx context.Context, records []*facade.SaveRecord) []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, records)
+	var errs []error
+	for i, r := range records {
+		key := makeKey(r.TypeName, r.ID)
+		if n := s.fail[key]; n != 0 {
+			if n > 0 {
+				s.fail[key] = n - 1
+			}
+			if errs == nil {
+				errs = make([]error, len(records))
+			}
+			errs[i] = errors.New("backend down")
+			continue
+		}
+		s.rows[key] = r
+	}
+	return errs
+}
+
+// serialRunner 测试用 ActorRunner：在调用方协程内串行执行
+func serialRunner() ActorRunner {
+	var mu sync.Mutex
+	return func(ctx context.Context, entityType, id string, fn func()) error {
+		mu.Lock()
+		defer mu.Unlock()
+		fn()
+		return nil
+	}
+}
+
+func newTestWriteBehind(t *testing.T, store facade.BatchStorage, opts ...WriteBehindOption) *WriteBehind {
+	t.Helper()
+	wb, err := NewWriteBehind(store, serialRunner(), opts...)
+	if err != nil {
+		t.Fatalf("new write-behind: %v", err)
+	}
+	return wb
+}
+
+func (s *fakeBatchStore) row(key string) *facade.SaveRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rows[key]
+}
+
+func TestWriteBehind_CoalesceAndBatch(t *testing.T) {
+	store := newFakeBatchStore()
+	wb := newTestWriteBehind(t, store, WithWriteBehindInterval(time.Hour), WithWriteBehindBatchSize(2))
+	defer wb.Close(context.Background())
+
+	wb.Submit(&facade.SaveRecord{TypeName: "user", ID: "1", Fields: map[string][]byte{"a": []byte("1"), "b": []byte("1")}})
+	wb.Submit(&facade.SaveRecord{TypeName: "user", ID: "1", Fields: map[string][]byte{"b": []byte("2")}})
+	if st := wb.Stats(); st.Pending != 1 || st.Coalesced != 1 {
+		t.Fatalf("stats=%+v, want 1 pending / 1 coalesced", st)
+	}
+	wb.Submit(&facade.SaveRecord{TypeName: "user", ID: "2", Payload: []byte("p")})
+	wb.Submit(&facade.SaveRecord{TypeName: "user", ID: "3", Payload: []byte("p")})
+
+	if err := wb.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	r := store.row("user/1")
+	if r == nil || string(r.Fields["a"]) != "1" || string(r.Fields["b"]) != "2" {
+		t.Fatalf("coalesced row=%+v", r)
+	}
+	store.mu.Lock()
+	for _, b := range store.batches {
+		if len(b) > 2 {
+			t.Fatalf("batch size %d exceeds limit", len(b))
+		}
+	}
+	store.mu.Unlock()
+	if st := wb.Stats(); st.Pending != 0 || st.InFlight != 0 || st.Flushed != 3 {
+		t.Fatalf("stats=%+v", st)
+	}
+}
+
+func TestWriteBehind_RetryAndDeadLetter(t *testing.T) {
+	store := newFakeBatchStore()
+	store.fail["user/1"] = 2
+	store.fail["user/2"] = -1
+	var mu sync.Mutex
+	var dead []string
+	wb := newTestWriteBehind(t, store,
+		WithWriteBehindInterval(5*time.Millisecond),
+		WithWriteBehindRetry(3, time.Millisecond, 4*time.Millisecond),
+		WithWriteBehindDeadLetter(func(rec *facade.SaveRecord, err error) {
+			mu.Lock()
+			dead = append(dead, rec.ID)
+			mu.Unlock()
+		}),
+	)
+	wb.Submit(&facade.SaveRecord{TypeName: "user", ID: "1", Payload: []byte("v1")})
+	wb.Submit(&facade.SaveRecord{TypeName: "user", ID: "2", Payload: []byte("v1")})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		st := wb.Stats()
+		if st.Pending == 0 && st.InFlight == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pipeline not drained: %+v", st)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := wb.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if r := store.row("user/1"); r == nil || string(r.Payload) != "v1" {
+		t.Fatalf("user/1 not saved after retries: %+v", r)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dead) != 1 || dead[0] != "2" {
+		t.Fatalf("dead=%v, want [2]", dead)
+	}
+	if st := wb.Stats(); st.DeadLettered != 1 || st.Retried < 2 {
+		t.Fatalf("stats=%+v", st)
+	}
+}
+
+func TestWriteBehind_DeadLetterRemarksEntity(t *testing.T) {
+	store := newFakeBatchStore()
+	store.fail["player/P1"] = -1
+	store.fail["user/U1"] = -1
+	wb := newTestWriteBehind(t, store,
+		WithWriteBehindInterval(5*time.Millisecond),
+		WithWriteBehindRetry(1, time.Millisecond, time.Millisecond),
+		WithWriteBehindDeadLetter(func(*facade.SaveRecord, error) {}),
+	)
+	ctx := context.Background()
+	delta := newTrackedEntity()
+	delta.SetID("P1")
+	full := &wbEntity{value: "v1"}
+	full.SetTypeName("user")
+	full.SetID("U1")
+	full.SetDirty(true)
+	if err := wb.Enqueue(ctx, delta); err != nil {
+		t.Fatal(err)
+	}
+	if err := wb.Enqueue(ctx, full); err != nil {
+		t.Fatal(err)
+	}
+	if delta.HasChanges() || full.IsDirty() {
+		t.Fatalf("snapshot should clear dirty state")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for wb.Stats().DeadLettered < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("records not dead-lettered: %+v", wb.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// 死信记录的数据仍在内存中：实体重新置脏，已快照的分区重新标记
+	if got := delta.DirtySections(); !reflect.DeepEqual(got, []string{"bag", "mail", "name"}) {
+		t.Fatalf("dirty sections=%v", got)
+	}
+	if !delta.IsDirty() || !full.IsDirty() {
+		t.Fatalf("entities should be dirty again")
+	}
+
+	// 存储恢复后，下个保存周期重新快照写出
+	store.mu.Lock()
+	store.fail = map[string]int{}
+	store.mu.Unlock()
+	_ = wb.Enqueue(ctx, delta)
+	_ = wb.Enqueue(ctx, full)
+	if err := wb.Close(ctx); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if r := store.row("player/P1"); r == nil || len(r.Fields) != 3 {
+		t.Fatalf("delta entity not saved: %+v", r)
+	}
+	if r := store.row("user/U1"); r == nil || string(r.Payload) != "v1" {
+		t.Fatalf("full entity not saved: %+v", r)
+	}
+}
+
+func TestNewWriteBehind_RequiresRunner(t *testing.T) {
+	if _, err := NewWriteBehind(newFakeBatchStore(), nil); !errors.Is(err, facade.ErrInvalidConfig) {
+		t.Fatalf("want ErrInvalidConfig, got %v", err)
+	}
+}
+
+type wbEntity struct {
+	BaseEntity
+	mu    sync.Mutex
+	value string
+}
+
+func (e *wbEntity) LogicType() facade.LType { return 0 }
+func (e *wbEntity) OwnerID() string         { return e.ID() }
+func (e *wbEntity) MarshalBinary() ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return []byte(e.value), nil
+}
+func (e *wbEntity) UnmarshalBinary(b []byte) error { e.value = string(b); return nil }
+func (e *wbEntity) GetSchemaVersion() int          { return 1 }
+func (e *wbEntity) Save(ctx context.Context) error { return errors.New("sync save must not be used") }
+func (e *wbEntity) AutoSetDirty() bool             { return false }
+
+func TestMemoryManager_SaveSink(t *testing.T) {
+	store := newFakeBatchStore()
+	wb := newTestWriteBehind(t, store, WithWriteBehindInterval(5*time.Millisecond))
+	mgr := NewMemoryManager(WithSavePeriodMillis(20), WithWheelTickMillis(5), WithSaveJitterRatio(0))
+	mgr.SetSaveSink(wb)
+
+	ctx := context.Background()
+	e, err := mgr.Create(ctx, "user", "W1", func() facade.Entity {
+		w := &wbEntity{value: "v1"}
+		w.SetTypeName("user")
+		return w
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	e.(*wbEntity).SetDirty(true)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for store.row("user/W1") == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("entity not saved through sink, stats=%+v", wb.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if e.(*wbEntity).IsDirty() {
+		t.Fatalf("dirty flag should be cleared by snapshot")
+	}
+	if err := wb.Close(ctx); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if r := store.row("user/W1"); string(r.Payload) != "v1" || r.Schema != 1 {
+		t.Fatalf("row=%+v", r)
+	}
+}
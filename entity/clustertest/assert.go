@@ -0,0 +1,35 @@
+package clustertest
+
+import (
+	"testing"
+)
+
+// AssertOwner 断言实体仅加载在 pod 上，且存储中的持久链接指向 pod
+func (c *Cluster) AssertOwner(t testing.TB, entityType, id string, pod int) {
+	t.Helper()
+	owners := c.Owners(entityType, id)
+	if len(owners) != 1 || owners[0] != pod {
+		t.Fatalf("%s/%s loaded on pods %v, want only pod %d", entityType, id, owners, pod)
+	}
+	if linked := c.LinkedPod(id); linked != pod {
+		t.Fatalf("%s/%s linked to pod %d, want pod %d", entityType, id, linked, pod)
+	}
+}
+
+// AssertSingleOwner 断言实体恰好加载在一个 Pod 上（无脑裂），返回该 Pod
+func (c *Cluster) AssertSingleOwner(t testing.TB, entityType, id string) int {
+	t.Helper()
+	owners := c.Owners(entityType, id)
+	if len(owners) != 1 {
+		t.Fatalf("%s/%s loaded on pods %v, want exactly one owner", entityType, id, owners)
+	}
+	return owners[0]
+}
+
+// AssertNotLoaded 断言实体未加载在任何存活 Pod 上
+func (c *Cluster) AssertNotLoaded(t testing.TB, entityType, id string) {
+	t.Helper()
+	if owners := c.Owners(entityType, id); len(owners) != 0 {
+		t.Fatalf("%s/%s still loaded on pods %v", entityType, id, owners)
+	}
+}
@@ -0,0 +1,437 @@
+// Package clustertest 单进程多 Pod 集群模拟器
+//
+// 在一个进程内启动 N 个虚拟 Pod，每个 Pod 拥有独立的 MemoryManager、CallSystemImpl、
+// 路由服务端/客户端驱动与 gRPC 服务（bufconn），共享一个内存链接存储
+// （executor.MemoryStatefulExecutor，语义与 Redis 版本一致）。
+// 用于测试实体归属切换、下线排空、网络分区与脑裂，无需 Redis 与多进程。
+//
+// 故障注入：
+//   - Kill：模拟进程崩溃，Pod 状态从存储中消失，链接保留（成为悬挂链接）
+//   - Reap：清理指向某 Pod 的悬挂链接（模拟运维/探活清理）
+//   - Drain：优雅下线，停止接收新路由并卸载、解绑本地实体
+//   - Partition/Heal：Pod 之间网络分区
+//   - IsolateStore/ReconnectStore：Pod 与链接存储之间网络分区
+package clustertest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/api/entity"
+	"github.com/go-kratos/kratos/v2/entity/base"
+	"github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/route/executor"
+	"github.com/go-kratos/kratos/v2/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// entityCallMsgID EntityServiceTemplate.OnEntityCall 在 rpc 分发表中的 msgId
+const entityCallMsgID = "OnEntityCall"
+
+var (
+	// ErrPodNotFound Pod 序号不存在
+	ErrPodNotFound = errors.New("clustertest: pod not found")
+	// ErrPodDown Pod 已被 Kill
+	ErrPodDown = errors.New("clustertest: pod is down")
+	// ErrPartitioned 两个 Pod 之间处于网络分区
+	ErrPartitioned = errors.New("clustertest: pods partitioned")
+)
+
+type options struct {
+	pods      int
+	namespace string
+	service   string
+	entities  map[string]func(ctx context.Context, id string) (facade.Entity, error)
+	mgrOpts   []base.Option
+	queueSize int
+	logger    log.Logger
+}
+
+// Option 集群选项
+type Option func(*options)
+
+// WithPods 设置 Pod 数量（默认 3；Pod 序号从 1 开始，0 在路由驱动中表示“无可用 Pod”）
+func WithPods(n int) Option { return func(o *options) { o.pods = n } }
+
+// WithService 设置命名空间与服务名
+func WithService(namespace, service string) Option {
+	return func(o *options) { o.namespace, o.service = namespace, service }
+}
+
+// WithEntityType 注册实体类型的加载钩子（每个 Pod 都会注册）
+func WithEntityType(entityType string, hook func(ctx context.Context, id string) (facade.Entity, error)) Option {
+	return func(o *options) { o.entities[entityType] = hook }
+}
+
+// WithManagerOptions 设置每个 Pod 的 MemoryManager 选项
+func WithManagerOptions(opts ...base.Option) Option {
+	return func(o *options) { o.mgrOpts = append(o.mgrOpts, opts...) }
+}
+
+// WithQueueSize 设置每个 Pod 的实体执行队列容量
+func WithQueueSize(n int) Option { return func(o *options) { o.queueSize = n } }
+
+// WithLogger 设置路由驱动使用的 logger（默认丢弃）
+func WithLogger(l log.Logger) Option { return func(o *options) { o.logger = l } }
+
+// Cluster 单进程多 Pod 集群
+type Cluster struct {
+	opts  options
+	store *executor.MemoryStatefulExecutor
+
+	mu         sync.Mutex
+	pods       map[int]*Pod
+	partitions map[[2]int]bool
+	proxies    map[[2]int]rpc.RpcClientDriver
+}
+
+// NewCluster 创建并启动集群
+func NewCluster(opts ...Option) (*Cluster, error) {
+	o := options{
+		pods:      3,
+		namespace: "default",
+		service:   "entity",
+		entities:  make(map[string]func(ctx context.Context, id string) (facade.Entity, error)),
+		logger:    log.NewStdLogger(io.Discard),
+	}
+	for _, fn := range opts {
+		fn(&o)
+	}
+	c := &Cluster{
+		opts:       o,
+		store:      executor.NewMemoryStatefulExecutor(),
+		pods:       make(map[int]*Pod, o.pods),
+		partitions: make(map[[2]int]bool),
+		proxies:    make(map[[2]int]rpc.RpcClientDriver),
+	}
+	for i := 1; i <= o.pods; i++ {
+		p, err := newPod(i, c.store, &c.opts)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("clustertest: start pod %d: %w", i, err)
+		}
+		c.pods[i] = p
+	}
+	return c, nil
+}
+
+// Store 返回共享的内存链接存储
+func (c *Cluster) Store() *executor.MemoryStatefulExecutor { return c.store }
+
+// Pod 返回指定序号的 Pod
+func (c *Cluster) Pod(index int) *Pod {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pods[index]
+}
+
+// PodIndexes 返回全部 Pod 序号（有序）
+func (c *Cluster) PodIndexes() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	idx := make([]int, 0, len(c.pods))
+	for i := range c.pods {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
+}
+
+// Call 从 from Pod 发起实体调用：经 from 的路由客户端解析归属 Pod 后通过 gRPC 投递
+// 返回响应与实际处理请求的 Pod 序号
+func (c *Cluster) Call(ctx context.Context, from int, req *entity.EntityRequest) (*entity.EntityResponse, int, error) {
+	src, err := c.alivePod(from)
+	if err != nil {
+		return nil, 0, err
+	}
+	to, err := src.Router.ResolvePod(ctx, req.Id)
+	if err != nil {
+		return nil, 0, err
+	}
+	rsp, err := c.CallPod(ctx, from, to, req)
+	return rsp, to, err
+}
+
+// CallPod 从 from Pod 直接向 to Pod 投递实体调用（绕过路由解析，用于构造脑裂等场景）
+func (c *Cluster) CallPod(ctx context.Context, from, to int, req *entity.EntityRequest) (*entity.EntityResponse, error) {
+	proxy, err := c.proxy(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	// OnEntityCall 的参数与返回均为具名结构体，rpc 层按 JSON 打包
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := proxy.Call(ctx, entityCallMsgID, &rpc.Content[string]{CType: rpc.RpcContentJson, Dt: string(data)})
+	if err != nil {
+		return nil, err
+	}
+	out, ok := ret.Data().(string)
+	if !ok {
+		return nil, facade.ErrDecode
+	}
+	rsp := &entity.EntityResponse{}
+	if err := json.Unmarshal([]byte(out), rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// Kill 模拟 Pod 崩溃：停止服务、丢弃内存实体；存储中的 Pod 状态消失但链接保留
+func (c *Cluster) Kill(index int) error {
+	p := c.Pod(index)
+	if p == nil {
+		return ErrPodNotFound
+	}
+	p.stop()
+	c.dropProxies(index)
+	return c.store.RemoveServiceState(context.Background(), c.opts.namespace, c.opts.service, index)
+}
+
+// Restart 以相同序号重新启动一个全新的 Pod（内存为空）
+func (c *Cluster) Restart(index int) error {
+	c.mu.Lock()
+	old := c.pods[index]
+	c.mu.Unlock()
+	if old == nil {
+		return ErrPodNotFound
+	}
+	old.stop()
+	c.dropProxies(index)
+	p, err := newPod(index, c.store, &c.opts)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.pods[index] = p
+	c.mu.Unlock()
+	return nil
+}
+
+// Reap 清理指向 index 的全部链接（模拟探活发现 Pod 死亡后的清理），返回被清理的 id
+func (c *Cluster) Reap(index int) []string {
+	ctx := context.Background()
+	ids := c.store.LinkedUIDs(c.opts.namespace, c.opts.service, index)
+	for _, id := range ids {
+		_, _ = c.store.RemoveLinkedPodWithId(ctx, c.opts.namespace, id, c.opts.service, -1, index)
+	}
+	return ids
+}
+
+// Drain 优雅下线：标记为不可路由，卸载本地实体并解除其链接，使后续调用切换到其他 Pod
+func (c *Cluster) Drain(ctx context.Context, index int) error {
+	p, err := c.alivePod(index)
+	if err != nil {
+		return err
+	}
+	if err := p.exec.SetServiceState(ctx, c.opts.namespace, c.opts.service, index, podStateNotReady); err != nil {
+		return err
+	}
+	type key struct{ t, id string }
+	var keys []key
+	p.Mgr.Range(func(entityType, id string, _ facade.Entity) bool {
+		keys = append(keys, key{entityType, id})
+		return true
+	})
+	for _, k := range keys {
+		if err := p.Mgr.Remove(ctx, k.t, k.id); err != nil {
+			return err
+		}
+		if err := p.Router.UnlinkLocal(ctx, k.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Undrain 重新将 Pod 标记为可路由
+func (c *Cluster) Undrain(ctx context.Context, index int) error {
+	p, err := c.alivePod(index)
+	if err != nil {
+		return err
+	}
+	return p.exec.SetServiceState(ctx, c.opts.namespace, c.opts.service, index, podStateReady)
+}
+
+// Partition 切断两个 Pod 之间的网络（双向）
+func (c *Cluster) Partition(a, b int) {
+	c.mu.Lock()
+	c.partitions[pairKey(a, b)] = true
+	c.mu.Unlock()
+	c.dropProxy(a, b)
+	c.dropProxy(b, a)
+}
+
+// Heal 恢复两个 Pod 之间的网络
+func (c *Cluster) Heal(a, b int) {
+	c.mu.Lock()
+	delete(c.partitions, pairKey(a, b))
+	c.mu.Unlock()
+}
+
+// HealAll 恢复全部 Pod 间网络与存储连接
+func (c *Cluster) HealAll() {
+	c.mu.Lock()
+	c.partitions = make(map[[2]int]bool)
+	pods := make([]*Pod, 0, len(c.pods))
+	for _, p := range c.pods {
+		pods = append(pods, p)
+	}
+	c.mu.Unlock()
+	for _, p := range pods {
+		p.exec.isolated.Store(false)
+	}
+}
+
+// IsolateStore 切断 Pod 与链接存储之间的网络（Pod 仍可服务内存中的实体）
+func (c *Cluster) IsolateStore(index int) error {
+	p := c.Pod(index)
+	if p == nil {
+		return ErrPodNotFound
+	}
+	p.exec.isolated.Store(true)
+	return nil
+}
+
+// ReconnectStore 恢复 Pod 与链接存储之间的网络
+func (c *Cluster) ReconnectStore(index int) error {
+	p := c.Pod(index)
+	if p == nil {
+		return ErrPodNotFound
+	}
+	p.exec.isolated.Store(false)
+	return nil
+}
+
+// Owners 返回内存中持有该实体的存活 Pod（有序；多于一个即脑裂）
+func (c *Cluster) Owners(entityType, id string) []int {
+	var owners []int
+	for _, i := range c.PodIndexes() {
+		if p := c.Pod(i); p != nil && p.Has(entityType, id) {
+			owners = append(owners, i)
+		}
+	}
+	return owners
+}
+
+// LinkedPod 返回存储中该 id 的持久链接 Pod（不存在为 -1）
+func (c *Cluster) LinkedPod(id string) int {
+	pod, _ := c.store.GetLinkedPodIfPersist(context.Background(), c.opts.namespace, id, c.opts.service)
+	return pod
+}
+
+// Close 停止全部 Pod 并关闭连接
+func (c *Cluster) Close() {
+	c.mu.Lock()
+	pods := make([]*Pod, 0, len(c.pods))
+	for _, p := range c.pods {
+		pods = append(pods, p)
+	}
+	proxies := c.proxies
+	c.proxies = make(map[[2]int]rpc.RpcClientDriver)
+	c.mu.Unlock()
+	for _, px := range proxies {
+		closeProxy(px)
+	}
+	for _, p := range pods {
+		p.stop()
+	}
+}
+
+func (c *Cluster) alivePod(index int) (*Pod, error) {
+	p := c.Pod(index)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %d", ErrPodNotFound, index)
+	}
+	if !p.Alive() {
+		return nil, fmt.Errorf("%w: %d", ErrPodDown, index)
+	}
+	return p, nil
+}
+
+func (c *Cluster) partitioned(a, b int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.partitions[pairKey(a, b)]
+}
+
+// proxy 返回 from -> to 的 rpc 客户端（按需建立，经分区检查）
+func (c *Cluster) proxy(ctx context.Context, from, to int) (rpc.RpcClientDriver, error) {
+	if _, err := c.alivePod(from); err != nil {
+		return nil, err
+	}
+	dst, err := c.alivePod(to)
+	if err != nil {
+		return nil, err
+	}
+	if c.partitioned(from, to) {
+		return nil, fmt.Errorf("%w: %d -> %d", ErrPartitioned, from, to)
+	}
+	k := [2]int{from, to}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if px := c.proxies[k]; px != nil {
+		return px, nil
+	}
+	lis := dst.lis
+	px, err := rpc.NewRpcProxyDirect(ctx, fmt.Sprintf("passthrough:///pod-%d", to), fmt.Sprintf("pod-%d", from), false,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			if c.partitioned(from, to) {
+				return nil, fmt.Errorf("%w: %d -> %d", ErrPartitioned, from, to)
+			}
+			return lis.DialContext(ctx)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.proxies[k] = px
+	return px, nil
+}
+
+func (c *Cluster) dropProxy(from, to int) {
+	c.mu.Lock()
+	px := c.proxies[[2]int{from, to}]
+	delete(c.proxies, [2]int{from, to})
+	c.mu.Unlock()
+	closeProxy(px)
+}
+
+// dropProxies 关闭与 index 相关的全部连接
+func (c *Cluster) dropProxies(index int) {
+	c.mu.Lock()
+	var drop []rpc.RpcClientDriver
+	for k, px := range c.proxies {
+		if k[0] == index || k[1] == index {
+			drop = append(drop, px)
+			delete(c.proxies, k)
+		}
+	}
+	c.mu.Unlock()
+	for _, px := range drop {
+		closeProxy(px)
+	}
+}
+
+func closeProxy(px rpc.RpcClientDriver) {
+	if cl, ok := px.(io.Closer); ok {
+		_ = cl.Close()
+	}
+}
+
+func pairKey(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
@@ -0,0 +1,216 @@
+package clustertest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/api/entity"
+	"github.com/go-kratos/kratos/v2/entity/ability/call"
+	"github.com/go-kratos/kratos/v2/entity/base"
+	"github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+const counterType = "counter"
+
+type counterEntity struct {
+	base.BaseEntity
+}
+
+type incrReq struct {
+	N int64 `json:"n"`
+}
+
+type incrResp struct {
+	Value int64 `json:"value"`
+}
+
+type counterAbility struct {
+	owner facade.Entity
+	value atomic.Int64
+}
+
+func (a *counterAbility) Name() string { return "counter" }
+func (a *counterAbility) Attach(ctx context.Context, owner facade.Entity) error {
+	a.owner = owner
+	owner.AddAbility(a)
+	return nil
+}
+func (a *counterAbility) Detach(ctx context.Context) error { a.owner = nil; return nil }
+func (a *counterAbility) Incr(ctx context.Context, req *incrReq) (*incrResp, error) {
+	return &incrResp{Value: a.value.Add(req.N)}, nil
+}
+
+func newCounter(ctx context.Context, id string) (facade.Entity, error) {
+	e := &counterEntity{}
+	e.SetTypeName(counterType)
+	e.SetID(id)
+	if err := (&counterAbility{}).Attach(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func newTestCluster(t *testing.T, opts ...Option) *Cluster {
+	t.Helper()
+	call.RegisterType(reflect.TypeOf((*counterAbility)(nil)))
+	c, err := NewCluster(append([]Option{WithEntityType(counterType, newCounter)}, opts...)...)
+	if err != nil {
+		t.Fatalf("new cluster: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func incr(t *testing.T, c *Cluster, from int, id string, n int64) (int64, int) {
+	t.Helper()
+	rsp, pod, err := c.Call(context.Background(), from, incrRequest(t, id, n))
+	if err != nil {
+		t.Fatalf("incr %s from pod %d: %v", id, from, err)
+	}
+	return decodeIncr(t, rsp), pod
+}
+
+func incrRequest(t *testing.T, id string, n int64) *entity.EntityRequest {
+	t.Helper()
+	b, err := json.Marshal(&incrReq{N: n})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &entity.EntityRequest{Type: counterType, Id: id, FunName: "Incr", Content: [][]byte{b}}
+}
+
+func decodeIncr(t *testing.T, rsp *entity.EntityResponse) int64 {
+	t.Helper()
+	if len(rsp.Content) != 1 {
+		t.Fatalf("unexpected content len: %d", len(rsp.Content))
+	}
+	var out incrResp
+	if err := json.Unmarshal(rsp.Content[0], &out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return out.Value
+}
+
+func TestCluster_RoutesToSingleOwner(t *testing.T) {
+	c := newTestCluster(t)
+
+	v, owner := incr(t, c, 1, "c1", 1)
+	if v != 1 {
+		t.Fatalf("value=%d, want 1", v)
+	}
+	c.AssertOwner(t, counterType, "c1", owner)
+
+	// 其他 Pod 发起的调用解析到同一归属
+	for _, from := range c.PodIndexes() {
+		_, pod := incr(t, c, from, "c1", 1)
+		if pod != owner {
+			t.Fatalf("call from pod %d routed to %d, want %d", from, pod, owner)
+		}
+	}
+	c.AssertOwner(t, counterType, "c1", owner)
+
+	// 直接投递到非归属 Pod 被拒绝
+	other := owner%3 + 1
+	if _, err := c.CallPod(context.Background(), other, other, incrRequest(t, "c1", 1)); err == nil {
+		t.Fatalf("expected call on non-owner pod %d to fail", other)
+	}
+	c.AssertOwner(t, counterType, "c1", owner)
+}
+
+func TestCluster_DrainHandsOff(t *testing.T) {
+	c := newTestCluster(t)
+	ctx := context.Background()
+
+	_, owner := incr(t, c, 1, "c2", 5)
+	if err := c.Drain(ctx, owner); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	c.AssertNotLoaded(t, counterType, "c2")
+
+	from := owner%3 + 1
+	v, pod := incr(t, c, from, "c2", 1)
+	if pod == owner {
+		t.Fatalf("drained pod %d still receives calls", owner)
+	}
+	if v != 1 {
+		t.Fatalf("value=%d, want 1 (state reloaded on new owner)", v)
+	}
+	c.AssertOwner(t, counterType, "c2", pod)
+}
+
+func TestCluster_KillAndReap(t *testing.T) {
+	c := newTestCluster(t)
+	ctx := context.Background()
+
+	_, owner := incr(t, c, 1, "c3", 1)
+	if err := c.Kill(owner); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+	from := owner%3 + 1
+
+	// 悬挂链接仍指向死亡 Pod，路由无法解析到可用 Pod
+	if _, _, err := c.Call(ctx, from, incrRequest(t, "c3", 1)); err == nil {
+		t.Fatalf("expected call to fail while link points to dead pod %d", owner)
+	}
+	if _, err := c.CallPod(ctx, from, owner, incrRequest(t, "c3", 1)); !errors.Is(err, ErrPodDown) {
+		t.Fatalf("err=%v, want ErrPodDown", err)
+	}
+	if ids := c.Reap(owner); len(ids) != 1 || ids[0] != "c3" {
+		t.Fatalf("reaped %v, want [c3]", ids)
+	}
+	_, pod := incr(t, c, from, "c3", 1)
+	if pod == owner {
+		t.Fatalf("routed to dead pod %d", owner)
+	}
+	c.AssertOwner(t, counterType, "c3", pod)
+
+	if err := c.Restart(owner); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	c.AssertOwner(t, counterType, "c3", pod)
+}
+
+func TestCluster_PartitionAndSplitBrain(t *testing.T) {
+	c := newTestCluster(t)
+	ctx := context.Background()
+
+	_, owner := incr(t, c, 1, "c4", 1)
+	other := owner%3 + 1
+
+	c.Partition(other, owner)
+	if _, err := c.CallPod(ctx, other, owner, incrRequest(t, "c4", 1)); !errors.Is(err, ErrPartitioned) {
+		t.Fatalf("err=%v, want ErrPartitioned", err)
+	}
+	c.Heal(other, owner)
+	if _, err := c.CallPod(ctx, other, owner, incrRequest(t, "c4", 1)); err != nil {
+		t.Fatalf("call after heal: %v", err)
+	}
+
+	// 归属 Pod 与存储隔离，期间运维清理了链接：其他 Pod 接管后出现脑裂
+	if err := c.IsolateStore(owner); err != nil {
+		t.Fatal(err)
+	}
+	c.Reap(owner)
+	if _, err := c.CallPod(ctx, other, other, incrRequest(t, "c4", 1)); err != nil {
+		t.Fatalf("takeover: %v", err)
+	}
+	if owners := c.Owners(counterType, "c4"); len(owners) != 2 {
+		t.Fatalf("owners=%v, want split brain on 2 pods", owners)
+	}
+	// 隔离中的旧归属无法再确认链接
+	if _, err := c.CallPod(ctx, other, owner, incrRequest(t, "c4", 1)); err == nil {
+		t.Fatalf("expected isolated pod %d to reject calls", owner)
+	}
+
+	c.HealAll()
+	if _, err := c.CallPod(ctx, other, owner, incrRequest(t, "c4", 1)); err == nil {
+		t.Fatalf("expected stale owner %d to be rejected after heal", owner)
+	}
+	if linked := c.LinkedPod("c4"); linked != other {
+		t.Fatalf("linked=%d, want %d", linked, other)
+	}
+}
@@ -0,0 +1,108 @@
+package clustertest
+
+import (
+	"context"
+	"sync/atomic"
+
+	apirpc "github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/entity/ability/call"
+	"github.com/go-kratos/kratos/v2/entity/base"
+	"github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/route"
+	"github.com/go-kratos/kratos/v2/route/driver"
+	"github.com/go-kratos/kratos/v2/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// 模拟 Pod 在链接存储中的服务状态（与 RouteInfoDriverImpl 的解析保持一致）
+const (
+	podStateReady    = "READY"
+	podStateNotReady = "NOT_READY"
+)
+
+const bufconnSize = 1 << 20
+
+// Pod 一个虚拟 Pod：独立的实体管理器、调用系统、路由驱动与 gRPC 服务
+type Pod struct {
+	Index   int
+	Mgr     *base.MemoryManager
+	CallSys *call.CallSystemImpl
+	Router  facade.Router
+	Server  *driver.StatefulRouteForServerDriverImpl
+	Client  *driver.StatefulRouteForClientDriverImpl
+
+	exec  *podExecutor
+	lis   *bufconn.Listener
+	srv   *grpc.Server
+	alive atomic.Bool
+}
+
+// newPod 组装 Pod 并在 bufconn 上启动 gRPC 服务
+func newPod(index int, store route.StatefulExecutor, o *options) (*Pod, error) {
+	ctx := context.Background()
+	p := &Pod{Index: index, exec: &podExecutor{store: store}}
+	info := &route.ServerInfo{Namespace: o.namespace, ServiceName: o.service, PodIndex: index}
+
+	infoDriver := driver.NewRouteInfoDriverImpl(&route.StatefulBaseConfig{}, nil, p.exec, o.logger)
+	p.Server = driver.NewStatefulRouteForServerDriverImpl(route.DefaultBaseConfig(), p.exec, infoDriver, info, o.logger)
+	p.Client = driver.NewStatefulRouteForClientDriverImpl(p.exec, infoDriver, info, o.logger)
+	if err := p.Client.Init(); err != nil {
+		return nil, err
+	}
+	p.Router = &podRouter{pod: index, namespace: o.namespace, service: o.service, server: p.Server, client: p.Client}
+
+	p.Mgr = base.NewMemoryManager(o.mgrOpts...)
+	for t, hook := range o.entities {
+		p.Mgr.RegisterNotFoundHook(t, hook)
+	}
+	p.CallSys = &call.CallSystemImpl{}
+	if o.queueSize > 0 {
+		p.CallSys.SetQueueSize(o.queueSize)
+	}
+	p.CallSys.Init(ctx, p.Mgr)
+
+	rpcServer := rpc.NewRpcServer()
+	if err := rpcServer.Register(base.NewEntityServiceTemplate(p.Mgr, p.CallSys, p.Router)); err != nil {
+		return nil, err
+	}
+	p.lis = bufconn.Listen(bufconnSize)
+	p.srv = grpc.NewServer()
+	apirpc.RegisterRouterServiceServer(p.srv, rpc.NewRouterServiceServer(rpcServer))
+	go func() {
+		if err := p.srv.Serve(p.lis); err != nil {
+			log.Errorf("clustertest: pod %d serve: %v", index, err)
+		}
+	}()
+
+	if err := store.SetServiceState(ctx, o.namespace, o.service, index, podStateReady); err != nil {
+		p.srv.Stop()
+		return nil, err
+	}
+	p.alive.Store(true)
+	return p, nil
+}
+
+// Alive Pod 是否存活
+func (p *Pod) Alive() bool { return p.alive.Load() }
+
+// Has 实体是否加载在该 Pod 内存中
+func (p *Pod) Has(entityType, id string) bool {
+	if !p.Alive() {
+		return false
+	}
+	ok, _ := p.Mgr.Exists(context.Background(), entityType, id)
+	return ok
+}
+
+// stop 停止 gRPC 服务并关闭全部实体执行器（不做任何清理，模拟进程崩溃）
+func (p *Pod) stop() {
+	if !p.alive.CompareAndSwap(true, false) {
+		return
+	}
+	p.srv.Stop()
+	_ = p.lis.Close()
+	p.CallSys.CloseAll()
+	_ = p.Client.Close()
+}
@@ -0,0 +1,155 @@
+package clustertest
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/route"
+)
+
+// ErrStoreUnreachable 模拟 Pod 与链接存储（Redis）之间网络中断
+var ErrStoreUnreachable = errors.New("clustertest: link store unreachable")
+
+// podRouter 基于路由服务端/客户端驱动实现 facade.Router
+// - TrySetLocal/UnlinkLocal 走服务端驱动（持久链接）
+// - ResolvePod 走客户端驱动（缓存 + 最佳 Pod + 临时链接）
+type podRouter struct {
+	pod       int
+	namespace string
+	service   string
+	server    route.StatefulRouteForServerDriver
+	client    route.StatefulRouteForClientDriver
+}
+
+func (r *podRouter) TrySetLocal(ctx context.Context, id string) (bool, int, error) {
+	return r.server.TrySetLinkedPod(ctx, r.namespace, id, r.service, r.pod)
+}
+
+func (r *podRouter) UnlinkLocal(ctx context.Context, id string) error {
+	_, err := r.server.RemoveLinkedPodWithId(ctx, r.namespace, id, r.service, r.pod, -1)
+	return err
+}
+
+func (r *podRouter) CurrentPod() int { return r.pod }
+
+func (r *podRouter) ResolvePod(ctx context.Context, id string) (int, error) {
+	return r.client.ComputeLinkedPod(ctx, r.namespace, id, r.service)
+}
+
+// podExecutor 单个 Pod 视角的链接存储：可被隔离以模拟与存储之间的分区
+type podExecutor struct {
+	store    route.StatefulExecutor
+	isolated atomic.Bool
+}
+
+func (e *podExecutor) check() error {
+	if e.isolated.Load() {
+		return ErrStoreUnreachable
+	}
+	return nil
+}
+
+func (e *podExecutor) SetServiceState(ctx context.Context, namespace, serviceName string, podID int, state string) error {
+	if err := e.check(); err != nil {
+		return err
+	}
+	return e.store.SetServiceState(ctx, namespace, serviceName, podID, state)
+}
+
+func (e *podExecutor) GetServiceState(ctx context.Context, namespace, serviceName string) (map[int]string, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return e.store.GetServiceState(ctx, namespace, serviceName)
+}
+
+func (e *podExecutor) SetWorkloadState(ctx context.Context, namespace, serviceName, state string) error {
+	if err := e.check(); err != nil {
+		return err
+	}
+	return e.store.SetWorkloadState(ctx, namespace, serviceName, state)
+}
+
+func (e *podExecutor) GetWorkloadState(ctx context.Context, namespace, serviceName string) (string, error) {
+	if err := e.check(); err != nil {
+		return "", err
+	}
+	return e.store.GetWorkloadState(ctx, namespace, serviceName)
+}
+
+func (e *podExecutor) GetWorkloadStateBatch(ctx context.Context, namespace string, serviceNames []string) (map[string]string, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return e.store.GetWorkloadStateBatch(ctx, namespace, serviceNames)
+}
+
+func (e *podExecutor) SetLinkedPod(ctx context.Context, namespace, uid, serviceName string, podID, persistSeconds int) (int, error) {
+	if err := e.check(); err != nil {
+		return -1, err
+	}
+	return e.store.SetLinkedPod(ctx, namespace, uid, serviceName, podID, persistSeconds)
+}
+
+func (e *podExecutor) TrySetLinkedPod(ctx context.Context, namespace, uid, serviceName string, podID, persistSeconds int) (bool, int, error) {
+	if err := e.check(); err != nil {
+		return false, -1, err
+	}
+	return e.store.TrySetLinkedPod(ctx, namespace, uid, serviceName, podID, persistSeconds)
+}
+
+func (e *podExecutor) SetLinkedPodIfAbsent(ctx context.Context, namespace, uid, serviceName string, podID, persistSeconds int) (int, error) {
+	if err := e.check(); err != nil {
+		return -1, err
+	}
+	return e.store.SetLinkedPodIfAbsent(ctx, namespace, uid, serviceName, podID, persistSeconds)
+}
+
+func (e *podExecutor) GetLinkedPod(ctx context.Context, namespace, uid, serviceName string) (int, error) {
+	if err := e.check(); err != nil {
+		return -1, err
+	}
+	return e.store.GetLinkedPod(ctx, namespace, uid, serviceName)
+}
+
+func (e *podExecutor) GetLinkedPodIfPersist(ctx context.Context, namespace, uid, serviceName string) (int, error) {
+	if err := e.check(); err != nil {
+		return -1, err
+	}
+	return e.store.GetLinkedPodIfPersist(ctx, namespace, uid, serviceName)
+}
+
+func (e *podExecutor) BatchGetLinkedPod(ctx context.Context, namespace string, keys []string, serviceName string) (map[int][]string, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return e.store.BatchGetLinkedPod(ctx, namespace, keys, serviceName)
+}
+
+func (e *podExecutor) RemoveLinkedPod(ctx context.Context, namespace, uid, serviceName string, persistSeconds int) (bool, error) {
+	if err := e.check(); err != nil {
+		return false, err
+	}
+	return e.store.RemoveLinkedPod(ctx, namespace, uid, serviceName, persistSeconds)
+}
+
+func (e *podExecutor) RemoveLinkedPodWithId(ctx context.Context, namespace, uid, serviceName string, persistSeconds, podID int) (bool, error) {
+	if err := e.check(); err != nil {
+		return false, err
+	}
+	return e.store.RemoveLinkedPodWithId(ctx, namespace, uid, serviceName, persistSeconds, podID)
+}
+
+func (e *podExecutor) GetLinkService(ctx context.Context, namespace, uid string) (map[string]int, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return e.store.GetLinkService(ctx, namespace, uid)
+}
+
+var (
+	_ facade.Router          = (*podRouter)(nil)
+	_ route.StatefulExecutor = (*podExecutor)(nil)
+)
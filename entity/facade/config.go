@@ -1,13 +1,29 @@
 package facade
 
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
 // Config 运行时配置（示例：与文档配置建议对应最小集）
 type Config struct {
 	CacheTTLMillis   int64 // entity.cache.ttl_ms
 	SavePeriodMillis int64 // entity.save.period_ms
 	QueueSize        int   // entity.queue.size
 	KeepAliveOnGet   bool  // entity.keepalive.on_get
-	RouteLinkTTLSec  int   // route.link.cache_ttl_s
-	ClientMaxRetry   int   // caller.retry.max
+	RouteLinkTTLSec  int   // route.link.cache_ttl_s，应用到路由客户端的链接缓存（SetLinkCacheTTL）
+	ClientMaxRetry   int   // caller.retry.max，应用到有状态调用方的重新路由次数（SetMaxReroutes）
+
+	// Types 按实体类型覆盖（entity.types.<type>.*），未设置的字段沿用全局值
+	Types map[string]TypeConfig
+}
+
+// TypeConfig 单个实体类型的覆盖项（nil 表示沿用全局配置）
+type TypeConfig struct {
+	CacheTTLMillis   *int64 // entity.types.<type>.cache.ttl_ms
+	SavePeriodMillis *int64 // entity.types.<type>.save.period_ms
+	QueueSize        *int   // entity.types.<type>.queue.size
 }
 
 // DefaultConfig 返回默认配置（便于测试）
@@ -21,3 +37,100 @@ func DefaultConfig() Config {
 		ClientMaxRetry:   1,
 	}
 }
+
+// Validate 校验配置取值范围；失败返回包装 ErrInvalidConfig 的错误
+func (c Config) Validate() error {
+	if c.CacheTTLMillis < 0 {
+		return fmt.Errorf("%w: entity.cache.ttl_ms=%d", ErrInvalidConfig, c.CacheTTLMillis)
+	}
+	if c.SavePeriodMillis < 0 {
+		return fmt.Errorf("%w: entity.save.period_ms=%d", ErrInvalidConfig, c.SavePeriodMillis)
+	}
+	if c.QueueSize <= 0 {
+		return fmt.Errorf("%w: entity.queue.size=%d", ErrInvalidConfig, c.QueueSize)
+	}
+	if c.RouteLinkTTLSec < 0 {
+		return fmt.Errorf("%w: route.link.cache_ttl_s=%d", ErrInvalidConfig, c.RouteLinkTTLSec)
+	}
+	if c.ClientMaxRetry < 0 {
+		return fmt.Errorf("%w: caller.retry.max=%d", ErrInvalidConfig, c.ClientMaxRetry)
+	}
+	for _, t := range c.TypeNames() {
+		tc := c.Types[t]
+		if t == "" {
+			return fmt.Errorf("%w: entity.types: empty type name", ErrInvalidConfig)
+		}
+		if tc.CacheTTLMillis != nil && *tc.CacheTTLMillis < 0 {
+			return fmt.Errorf("%w: entity.types.%s.cache.ttl_ms=%d", ErrInvalidConfig, t, *tc.CacheTTLMillis)
+		}
+		if tc.SavePeriodMillis != nil && *tc.SavePeriodMillis < 0 {
+			return fmt.Errorf("%w: entity.types.%s.save.period_ms=%d", ErrInvalidConfig, t, *tc.SavePeriodMillis)
+		}
+		if tc.QueueSize != nil && *tc.QueueSize <= 0 {
+			return fmt.Errorf("%w: entity.types.%s.queue.size=%d", ErrInvalidConfig, t, *tc.QueueSize)
+		}
+	}
+	return nil
+}
+
+// TypeNames 返回存在覆盖项的实体类型（有序，便于稳定遍历）
+func (c Config) TypeNames() []string {
+	names := make([]string, 0, len(c.Types))
+	for t := range c.Types {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CacheTTLFor 返回某类型生效的 TTL（毫秒）
+func (c Config) CacheTTLFor(entityType string) int64 {
+	if tc, ok := c.Types[entityType]; ok && tc.CacheTTLMillis != nil {
+		return *tc.CacheTTLMillis
+	}
+	return c.CacheTTLMillis
+}
+
+// SavePeriodFor 返回某类型生效的保存周期（毫秒）
+func (c Config) SavePeriodFor(entityType string) int64 {
+	if tc, ok := c.Types[entityType]; ok && tc.SavePeriodMillis != nil {
+		return *tc.SavePeriodMillis
+	}
+	return c.SavePeriodMillis
+}
+
+// RouteLinkTTL 返回链接缓存时长；0 表示沿用路由客户端的默认值
+func (c Config) RouteLinkTTL() time.Duration {
+	return time.Duration(c.RouteLinkTTLSec) * time.Second
+}
+
+// LinkCacheSetter 可调整链接缓存时长的路由客户端（如 route/driver.StatefulRouteForClientDriverImpl）
+type LinkCacheSetter interface {
+	SetLinkCacheTTL(ttl time.Duration)
+}
+
+// RerouteSetter 可调整重新路由次数的有状态调用方（如 rpc.NewStatefulRpcProxy 返回的客户端）
+type RerouteSetter interface {
+	SetMaxReroutes(n int)
+}
+
+// ApplyCaller 将 route.link 与 caller.retry 配置应用到路由客户端与有状态调用方（nil 跳过）
+// 与 MemoryManager/CallSystemImpl 的 ApplyConfig 一同在 WatchConfig 的回调中调用即可热更新。
+func (c Config) ApplyCaller(link LinkCacheSetter, callers ...RerouteSetter) {
+	if link != nil {
+		link.SetLinkCacheTTL(c.RouteLinkTTL())
+	}
+	for _, caller := range callers {
+		if caller != nil {
+			caller.SetMaxReroutes(c.ClientMaxRetry)
+		}
+	}
+}
+
+// QueueSizeFor 返回某类型生效的执行队列容量
+func (c Config) QueueSizeFor(entityType string) int {
+	if tc, ok := c.Types[entityType]; ok && tc.QueueSize != nil {
+		return *tc.QueueSize
+	}
+	return c.QueueSize
+}
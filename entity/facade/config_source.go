@@ -0,0 +1,167 @@
+package facade
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 配置分段：按顶层键整体解析，避免监听回调时子键缓存尚未刷新导致读到旧值
+const (
+	sectionEntity = "entity"
+	sectionRoute  = "route"
+	sectionCaller = "caller"
+)
+
+var configSections = []string{sectionEntity, sectionRoute, sectionCaller}
+
+type entitySection struct {
+	Cache struct {
+		TTLMs *int64 `json:"ttl_ms"`
+	} `json:"cache"`
+	Save struct {
+		PeriodMs *int64 `json:"period_ms"`
+	} `json:"save"`
+	Queue struct {
+		Size *int `json:"size"`
+	} `json:"queue"`
+	KeepAlive struct {
+		OnGet *bool `json:"on_get"`
+	} `json:"keepalive"`
+	Types map[string]typeSection `json:"types"`
+}
+
+type typeSection struct {
+	Cache struct {
+		TTLMs *int64 `json:"ttl_ms"`
+	} `json:"cache"`
+	Save struct {
+		PeriodMs *int64 `json:"period_ms"`
+	} `json:"save"`
+	Queue struct {
+		Size *int `json:"size"`
+	} `json:"queue"`
+}
+
+type routeSection struct {
+	Link struct {
+		CacheTTLSec *int `json:"cache_ttl_s"`
+	} `json:"link"`
+}
+
+type callerSection struct {
+	Retry struct {
+		Max *int `json:"max"`
+	} `json:"retry"`
+}
+
+// LoadConfig 从 kratos config 读取实体运行时配置（缺失的键使用 DefaultConfig）并校验
+func LoadConfig(src config.Config) (Config, error) {
+	values := make(map[string]config.Value, len(configSections))
+	for _, key := range configSections {
+		values[key] = src.Value(key)
+	}
+	return buildConfig(values)
+}
+
+// WatchConfig 读取配置并立即 apply 一次，此后配置源变更时重新解析、校验并再次 apply。
+// 校验失败的变更只记录日志，保持上一份生效配置。
+// 说明：kratos config 只能监听启动时已存在的键，因此仅监听已出现的顶层分段。
+func WatchConfig(src config.Config, apply func(Config)) (Config, error) {
+	w := &configWatcher{apply: apply, values: make(map[string]config.Value, len(configSections))}
+	for _, key := range configSections {
+		w.values[key] = src.Value(key)
+	}
+	cfg, err := buildConfig(w.values)
+	if err != nil {
+		return Config{}, err
+	}
+	apply(cfg)
+	for _, key := range configSections {
+		if err := src.Watch(key, w.onChange); err != nil && !errors.Is(err, config.ErrNotFound) {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+type configWatcher struct {
+	mu     sync.Mutex
+	values map[string]config.Value
+	apply  func(Config)
+}
+
+func (w *configWatcher) onChange(key string, v config.Value) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.values[key] = v
+	cfg, err := buildConfig(w.values)
+	if err != nil {
+		log.Errorf("entity: reject config change on %q: %v", key, err)
+		return
+	}
+	w.apply(cfg)
+}
+
+// buildConfig 以默认值为底，叠加各分段的取值
+func buildConfig(values map[string]config.Value) (Config, error) {
+	cfg := DefaultConfig()
+	var es entitySection
+	if err := scanSection(values[sectionEntity], &es); err != nil {
+		return Config{}, err
+	}
+	if es.Cache.TTLMs != nil {
+		cfg.CacheTTLMillis = *es.Cache.TTLMs
+	}
+	if es.Save.PeriodMs != nil {
+		cfg.SavePeriodMillis = *es.Save.PeriodMs
+	}
+	if es.Queue.Size != nil {
+		cfg.QueueSize = *es.Queue.Size
+	}
+	if es.KeepAlive.OnGet != nil {
+		cfg.KeepAliveOnGet = *es.KeepAlive.OnGet
+	}
+	if len(es.Types) > 0 {
+		cfg.Types = make(map[string]TypeConfig, len(es.Types))
+		for t, ts := range es.Types {
+			cfg.Types[t] = TypeConfig{
+				CacheTTLMillis:   ts.Cache.TTLMs,
+				SavePeriodMillis: ts.Save.PeriodMs,
+				QueueSize:        ts.Queue.Size,
+			}
+		}
+	}
+	var rs routeSection
+	if err := scanSection(values[sectionRoute], &rs); err != nil {
+		return Config{}, err
+	}
+	if rs.Link.CacheTTLSec != nil {
+		cfg.RouteLinkTTLSec = *rs.Link.CacheTTLSec
+	}
+	var cs callerSection
+	if err := scanSection(values[sectionCaller], &cs); err != nil {
+		return Config{}, err
+	}
+	if cs.Retry.Max != nil {
+		cfg.ClientMaxRetry = *cs.Retry.Max
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// scanSection 解析一个顶层分段；分段不存在时保持零值
+func scanSection(v config.Value, out any) error {
+	if v == nil || v.Load() == nil {
+		return nil
+	}
+	if err := v.Scan(out); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return nil
+}
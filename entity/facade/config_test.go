@@ -0,0 +1,151 @@
+package facade
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+type memSource struct {
+	data chan []byte
+	init []byte
+}
+
+func newMemSource(data string) *memSource {
+	return &memSource{data: make(chan []byte, 1), init: []byte(data)}
+}
+
+func (s *memSource) Load() ([]*config.KeyValue, error) {
+	return []*config.KeyValue{{Key: "mem", Value: s.init, Format: "json"}}, nil
+}
+
+func (s *memSource) Watch() (config.Watcher, error) {
+	return &memWatcher{src: s, exit: make(chan struct{})}, nil
+}
+
+type memWatcher struct {
+	src  *memSource
+	exit chan struct{}
+}
+
+func (w *memWatcher) Next() ([]*config.KeyValue, error) {
+	select {
+	case b := <-w.src.data:
+		return []*config.KeyValue{{Key: "mem", Value: b, Format: "json"}}, nil
+	case <-w.exit:
+		return nil, errors.New("stopped")
+	}
+}
+
+func (w *memWatcher) Stop() error {
+	close(w.exit)
+	return nil
+}
+
+func TestLoadConfig_DefaultsAndOverrides(t *testing.T) {
+	src := newMemSource(`{
+		"entity": {
+			"cache": {"ttl_ms": 1000},
+			"queue": {"size": 64},
+			"types": {"player": {"cache": {"ttl_ms": 5000}, "queue": {"size": 8}}}
+		},
+		"caller": {"retry": {"max": 3}}
+	}`)
+	c := config.New(config.WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	cfg, err := LoadConfig(c)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	def := DefaultConfig()
+	if cfg.CacheTTLMillis != 1000 || cfg.QueueSize != 64 || cfg.ClientMaxRetry != 3 {
+		t.Fatalf("unexpected cfg: %+v", cfg)
+	}
+	if cfg.KeepAliveOnGet != def.KeepAliveOnGet || cfg.RouteLinkTTLSec != def.RouteLinkTTLSec {
+		t.Fatalf("missing keys should keep defaults: %+v", cfg)
+	}
+	if cfg.CacheTTLFor("player") != 5000 || cfg.QueueSizeFor("player") != 8 {
+		t.Fatalf("player override not applied: ttl=%d queue=%d", cfg.CacheTTLFor("player"), cfg.QueueSizeFor("player"))
+	}
+	if cfg.SavePeriodFor("player") != cfg.SavePeriodMillis || cfg.CacheTTLFor("npc") != 1000 {
+		t.Fatalf("unset fields should inherit global values")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	neg := int64(-1)
+	cases := []Config{
+		{QueueSize: 0},
+		{QueueSize: 1, CacheTTLMillis: -1},
+		{QueueSize: 1, ClientMaxRetry: -1},
+		{QueueSize: 1, Types: map[string]TypeConfig{"player": {CacheTTLMillis: &neg}}},
+	}
+	for i, c := range cases {
+		if err := c.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("case %d: want ErrInvalidConfig, got %v", i, err)
+		}
+	}
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("default config should be valid: %v", err)
+	}
+}
+
+func TestWatchConfig_HotReload(t *testing.T) {
+	src := newMemSource(`{"entity": {"cache": {"ttl_ms": 100}}}`)
+	c := config.New(config.WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	applied := make(chan Config, 4)
+	cfg, err := WatchConfig(c, func(cfg Config) { applied <- cfg })
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if cfg.CacheTTLMillis != 100 || (<-applied).CacheTTLMillis != 100 {
+		t.Fatalf("initial apply mismatch: %+v", cfg)
+	}
+
+	// 非法变更被拒绝，不触发 apply
+	src.data <- []byte(`{"entity": {"cache": {"ttl_ms": -5}}}`)
+	select {
+	case got := <-applied:
+		t.Fatalf("invalid config applied: %+v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	src.data <- []byte(`{"entity": {"cache": {"ttl_ms": 250}}}`)
+	select {
+	case got := <-applied:
+		if got.CacheTTLMillis != 250 {
+			t.Fatalf("reload ttl=%d want 250", got.CacheTTLMillis)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("reload not applied")
+	}
+}
+
+type callerSettings struct {
+	ttl         time.Duration
+	maxReroutes int
+}
+
+func (s *callerSettings) SetLinkCacheTTL(ttl time.Duration) { s.ttl = ttl }
+func (s *callerSettings) SetMaxReroutes(n int)              { s.maxReroutes = n }
+
+func TestConfig_ApplyCaller(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RouteLinkTTLSec, cfg.ClientMaxRetry = 30, 4
+	s := &callerSettings{}
+	cfg.ApplyCaller(s, s, nil)
+	if s.ttl != 30*time.Second || s.maxReroutes != 4 {
+		t.Fatalf("applied ttl=%v maxReroutes=%d", s.ttl, s.maxReroutes)
+	}
+}
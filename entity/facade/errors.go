@@ -1,6 +1,12 @@
 package facade
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+)
 
 // 标准错误集合（与文档对齐）
 var (
@@ -13,4 +19,25 @@ var (
 	ErrEncode            = errors.New("entity: encode error")
 	ErrTimeout           = errors.New("entity: timeout")
 	ErrQueueOverflow     = errors.New("entity: queue overflow")
+	ErrCallCycle         = errors.New("entity: call cycle detected")
+	ErrCallDepthExceeded = errors.New("entity: call depth exceeded")
+	ErrInvalidConfig     = errors.New("entity: invalid config")
+	ErrJournalConflict   = errors.New("entity: journal conflict")
+	ErrRateLimited       = errors.New("entity: rate limited")
+	ErrEntityStale       = errors.New("entity: stale state, reload required")
 )
+
+const (
+	// ReasonAlreadyInOtherPod 调用被拒绝：实体已链接到其他 Pod，未执行
+	ReasonAlreadyInOtherPod = "ALREADY_IN_OTHER_POD"
+	// PodMetadataKey 拒绝错误中链接 Pod 的元数据键
+	PodMetadataKey = "pod"
+)
+
+// AlreadyInOtherPod 返回跨进程传递的拒绝错误；pod 为实体当前链接的 Pod（未知时为 0）
+// 错误携带原因与 Pod 元数据，调用方据此重新路由；仍可用 errors.Is 匹配 ErrAlreadyInOtherPod。
+func AlreadyInOtherPod(pod int) *kratoserrors.Error {
+	return kratoserrors.New(409, ReasonAlreadyInOtherPod, fmt.Sprintf("already linked to pod %d", pod)).
+		WithMetadata(map[string]string{PodMetadataKey: strconv.Itoa(pod)}).
+		WithCause(fmt.Errorf("%w: targetPod=%d", ErrAlreadyInOtherPod, pod))
+}
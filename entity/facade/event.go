@@ -0,0 +1,55 @@
+package facade
+
+import "context"
+
+// EventKind 实体生命周期事件类型
+type EventKind uint32
+
+const (
+	// EventCreated Create 新建实体并加入内存
+	EventCreated EventKind = iota + 1
+	// EventLoaded 内存未命中，经 NotFoundHook 加载进内存
+	EventLoaded
+	// EventAccessed Get 命中内存
+	EventAccessed
+	// EventSaved 保存成功（write-behind 模式下为批量写出成功，Entity 为 nil）
+	EventSaved
+	// EventEvicted TTL 到期卸载
+	EventEvicted
+	// EventRemoved 主动移出内存（Remove/DestroyAllType/ReleaseAll）
+	EventRemoved
+	// EventDestroyed 卸载后调用了 Destroy
+	EventDestroyed
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventCreated:
+		return "created"
+	case EventLoaded:
+		return "loaded"
+	case EventAccessed:
+		return "accessed"
+	case EventSaved:
+		return "saved"
+	case EventEvicted:
+		return "evicted"
+	case EventRemoved:
+		return "removed"
+	case EventDestroyed:
+		return "destroyed"
+	}
+	return "unknown"
+}
+
+// EntityEvent 实体生命周期事件
+type EntityEvent struct {
+	Kind       EventKind
+	EntityType string
+	ID         string
+	Entity     Entity // 可能为 nil
+	TimeMs     int64
+}
+
+// EventHandler 事件处理函数
+type EventHandler func(ctx context.Context, ev EntityEvent)
@@ -0,0 +1,39 @@
+package facade
+
+import "context"
+
+// JournalEvent 领域事件：事件溯源日志中的一条记录
+type JournalEvent struct {
+	Seq    uint64 // 实体内单调递增，从 1 开始
+	Kind   string
+	Data   []byte
+	TimeMs int64
+}
+
+// JournalSnapshot 状态快照：Seq 及之前全部事件作用后的实体状态
+type JournalSnapshot struct {
+	Seq     uint64
+	Schema  int
+	Payload []byte
+	TimeMs  int64
+}
+
+// JournalStorage 事件日志存储驱动（可选能力，供事件溯源实体使用）
+type JournalStorage interface {
+	// AppendEvents 追加事件；日志当前末尾序号与 expectSeq 不一致时返回 ErrJournalConflict
+	AppendEvents(ctx context.Context, typeName, id string, expectSeq uint64, events []*JournalEvent) error
+	// ReadEvents 按序返回 Seq 位于 (afterSeq, toSeq] 的事件；toSeq 为 0 表示直到末尾
+	ReadEvents(ctx context.Context, typeName, id string, afterSeq, toSeq uint64) ([]*JournalEvent, error)
+	// PutSnapshot 写入快照（旧快照可保留以支持按时间点重放）
+	PutSnapshot(ctx context.Context, typeName, id string, snap *JournalSnapshot) error
+	// LatestSnapshot 返回 Seq<=maxSeq 且 TimeMs<=maxTimeMs 的最新快照（0 表示不限）；不存在时返回 nil, nil
+	LatestSnapshot(ctx context.Context, typeName, id string, maxSeq uint64, maxTimeMs int64) (*JournalSnapshot, error)
+}
+
+// EventSourced 事件溯源契约（由实体实现）
+// 状态只通过事件变更：能力方法产生事件并追加到日志，加载时从快照开始按序重放。
+type EventSourced interface {
+	SaveObject // 快照序列化
+	// ApplyEvent 将事件作用于内存状态；需确定性，重放时不可产生外部副作用
+	ApplyEvent(ev *JournalEvent) error
+}
@@ -0,0 +1,29 @@
+package facade
+
+import "context"
+
+// RecordStorage 按记录读写的存储驱动（可选能力；删除与冷热归档时使用）
+type RecordStorage interface {
+	// GetRecord 读取实体的完整记录；不存在时返回 ErrNotFound
+	GetRecord(ctx context.Context, typeName, id string) (*SaveRecord, error)
+	// PutRecord 整体写入记录（覆盖）
+	PutRecord(ctx context.Context, rec *SaveRecord) error
+	// DeleteRecord 物理删除记录；不存在时返回 nil
+	DeleteRecord(ctx context.Context, typeName, id string) error
+}
+
+// SoftDeleteStorage 支持软删除的存储驱动（可选能力）
+// 标记删除后 Load/GetRecord 视为不存在，数据保留至 purgeAtMs，期间可恢复。
+type SoftDeleteStorage interface {
+	MarkDeleted(ctx context.Context, typeName, id string, purgeAtMs int64) error
+	// Undelete 撤销软删除；不存在软删除标记时返回 ErrNotFound
+	Undelete(ctx context.Context, typeName, id string) error
+	// PurgeDeleted 物理删除保留期已过（purgeAt <= nowMs）的软删除数据，返回删除数量
+	PurgeDeleted(ctx context.Context, nowMs int64, limit int) (int, error)
+}
+
+// ActivityStorage 记录最后写入时间的存储驱动（可选能力；归档扫描使用）
+type ActivityStorage interface {
+	// ListInactive 返回最后写入早于 beforeMs 的实体 id（至多 limit 个）
+	ListInactive(ctx context.Context, typeName string, beforeMs int64, limit int) ([]string, error)
+}
@@ -41,3 +41,12 @@ type EntityMgr interface {
 	// fun: 前置处理，可返回要添加的 Ability；返回 error 表示失败
 	RegisterBeforeAddProcess(abilityName string, fun func(ctx context.Context, e Entity) (Ability, error))
 }
+
+// GroupResolver 实体组查询（可选，由 EntityMgr 实现）
+// 同一 id 下声明为一组的多个实体类型共享执行器、一起卸载与保存。
+type GroupResolver interface {
+	// GroupOf 返回类型所属的组名（"" 表示不属于任何组）
+	GroupOf(entityType string) string
+	// GroupMembers 返回组内全部类型
+	GroupMembers(group string) []string
+}
@@ -0,0 +1,71 @@
+package facade
+
+import "context"
+
+// IndexKind 二级索引类型
+type IndexKind int
+
+const (
+	// IndexExact 精确索引：按字符串值等值查找（如公会成员 guild_id）
+	IndexExact IndexKind = iota + 1
+	// IndexOrdered 有序索引：按数值范围查找并排序（如排行榜 score）
+	IndexOrdered
+)
+
+// IndexField 实体类型声明的索引字段
+type IndexField struct {
+	Name string
+	Kind IndexKind
+}
+
+// IndexValue 索引字段的取值；Kind 决定使用 Str 还是 Num
+type IndexValue struct {
+	Kind IndexKind
+	Str  string
+	Num  float64
+}
+
+// ExactIndex 精确索引取值
+func ExactIndex(v string) IndexValue { return IndexValue{Kind: IndexExact, Str: v} }
+
+// OrderedIndex 有序索引取值
+func OrderedIndex(v float64) IndexValue { return IndexValue{Kind: IndexOrdered, Num: v} }
+
+// IndexedObject 提供索引字段当前取值（可选，由实体实现）
+// 保存时随实体数据一并写入索引；未出现在返回值中的字段视为无取值（从索引中移除）。
+type IndexedObject interface {
+	IndexValues() map[string]IndexValue
+}
+
+// Query 按索引字段查询实体 id
+type Query struct {
+	EntityType string
+	Field      string
+	Eq         string   // 精确索引：等值
+	Min, Max   *float64 // 有序索引：闭区间，nil 表示不限
+	Desc       bool     // 有序索引：按值降序（排行榜）
+	Cursor     string   // 上一页返回的 Next；空表示第一页
+	Limit      int      // 每页数量，<=0 取 100
+}
+
+// QueryResult 一页查询结果
+type QueryResult struct {
+	IDs  []string
+	Next string // 下一页游标；空表示没有更多
+}
+
+// IndexStorage 支持二级索引的存储驱动（可选能力）
+// 查询基于已落地的数据：内存中尚未保存的变更不可见。
+type IndexStorage interface {
+	// PutIndex 以 values 整体替换实体的索引项
+	PutIndex(ctx context.Context, typeName, id string, values map[string]IndexValue) error
+	// DeleteIndex 删除实体的全部索引项
+	DeleteIndex(ctx context.Context, typeName, id string) error
+	// QueryIndex 按索引查询；kind 为字段声明的索引类型
+	QueryIndex(ctx context.Context, kind IndexKind, q *Query) (*QueryResult, error)
+}
+
+// EntityQuerier 实体查询（EntityMgr 的可选能力）
+type EntityQuerier interface {
+	Query(ctx context.Context, q *Query) (*QueryResult, error)
+}
@@ -22,3 +22,47 @@ type SaveObject interface {
 	UnmarshalBinary([]byte) error
 	GetSchemaVersion() int
 }
+
+// DeltaSaveObject 增量序列化契约（可选，由实体实现）
+// 实体被划分为若干分区（section），仅序列化自上次保存以来发生变更的分区。
+// 分区名即存储中的字段名（hash-field 风格），需配合 FieldStorage 使用。
+type DeltaSaveObject interface {
+	SaveObject
+	// Sections 返回全部分区名
+	Sections() []string
+	// DirtySections 返回自上次保存以来变更的分区
+	DirtySections() []string
+	// MarshalSections 仅序列化指定分区：section -> payload
+	MarshalSections(sections []string) (map[string][]byte, error)
+	// UnmarshalSections 从分区数据恢复（不产生变更标记）
+	UnmarshalSections(fields map[string][]byte) error
+	// ClearSections 保存成功后清除已保存分区的变更标记
+	// 序列化之后再次发生的变更不会被清除
+	ClearSections(sections []string)
+	// MarkSections 重新标记分区为已变更（已快照的数据最终未能写入时使用）
+	MarkSections(sections []string)
+}
+
+// FieldStorage 支持按字段部分读写的存储驱动（可选能力，hash-field 风格）
+type FieldStorage interface {
+	// PutFields 仅写入给定字段，其余字段保持不变
+	PutFields(ctx context.Context, typeName, id string, fields map[string][]byte, schema int) error
+	// GetFields 读取全部字段与 schema 版本
+	GetFields(ctx context.Context, typeName, id string) (map[string][]byte, int, error)
+}
+
+// SaveRecord 一次保存的快照：全量 Payload 与增量 Fields 至少其一
+type SaveRecord struct {
+	TypeName string
+	ID       string
+	Payload  []byte            // 全量序列化结果（SaveObject）
+	Fields   map[string][]byte // 变更分区（DeltaSaveObject）
+	Schema   int
+	Index    map[string]IndexValue // 索引取值（IndexedObject）；nil 表示不维护索引
+}
+
+// BatchStorage 支持批量写入的存储驱动（可选能力，供 write-behind 保存管道使用）
+type BatchStorage interface {
+	// BatchPut 批量写入；返回与 records 等长的逐条错误，整体返回 nil 表示全部成功
+	BatchPut(ctx context.Context, records []*SaveRecord) []error
+}
@@ -0,0 +1,39 @@
+// Package index 提供实体二级索引存储（facade.IndexStorage）的内存与 Redis 实现。
+// - 精确索引：按字符串值等值查找，结果按 id 字典序
+// - 有序索引：按数值闭区间查找，结果按值（相同值按 id）升序或降序
+// 分页游标为结果偏移量，对调用方不透明。
+package index
+
+import (
+	"fmt"
+	"strconv"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// DefaultLimit 未指定 Limit 时的每页数量
+const DefaultLimit = 100
+
+// pageOf 解析游标与每页数量
+func pageOf(q *facade.Query) (offset, limit int, err error) {
+	limit = q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if q.Cursor == "" {
+		return 0, limit, nil
+	}
+	offset, err = strconv.Atoi(q.Cursor)
+	if err != nil || offset < 0 {
+		return 0, 0, fmt.Errorf("%w: invalid cursor %q", facade.ErrDecode, q.Cursor)
+	}
+	return offset, limit, nil
+}
+
+// nextCursor 取到 limit+1 条时说明还有下一页
+func nextCursor(offset, limit, got int) string {
+	if got <= limit {
+		return ""
+	}
+	return strconv.Itoa(offset + limit)
+}
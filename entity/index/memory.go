@@ -0,0 +1,156 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// MemoryIndex 内存二级索引（单进程、测试与内存存储驱动使用）
+type MemoryIndex struct {
+	mu      sync.RWMutex
+	values  map[string]map[string]facade.IndexValue   // type/id -> field -> 取值
+	exact   map[string]map[string]map[string]struct{} // type/field -> value -> ids
+	ordered map[string]map[string]float64             // type/field -> id -> 值
+}
+
+// NewMemoryIndex 创建内存索引
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		values:  make(map[string]map[string]facade.IndexValue),
+		exact:   make(map[string]map[string]map[string]struct{}),
+		ordered: make(map[string]map[string]float64),
+	}
+}
+
+func fieldKey(typeName, field string) string { return typeName + "/" + field }
+
+// PutIndex 以 values 整体替换实体的索引项
+func (m *MemoryIndex) PutIndex(ctx context.Context, typeName, id string, values map[string]facade.IndexValue) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeLocked(typeName, id)
+	if len(values) == 0 {
+		return nil
+	}
+	saved := make(map[string]facade.IndexValue, len(values))
+	for field, v := range values {
+		fk := fieldKey(typeName, field)
+		switch v.Kind {
+		case facade.IndexExact:
+			byVal := m.exact[fk]
+			if byVal == nil {
+				byVal = make(map[string]map[string]struct{})
+				m.exact[fk] = byVal
+			}
+			ids := byVal[v.Str]
+			if ids == nil {
+				ids = make(map[string]struct{})
+				byVal[v.Str] = ids
+			}
+			ids[id] = struct{}{}
+		case facade.IndexOrdered:
+			scores := m.ordered[fk]
+			if scores == nil {
+				scores = make(map[string]float64)
+				m.ordered[fk] = scores
+			}
+			scores[id] = v.Num
+		default:
+			return fmt.Errorf("%w: index %s has unknown kind %d", facade.ErrInvalidConfig, fk, v.Kind)
+		}
+		saved[field] = v
+	}
+	m.values[fieldKey(typeName, id)] = saved
+	return nil
+}
+
+// DeleteIndex 删除实体的全部索引项
+func (m *MemoryIndex) DeleteIndex(ctx context.Context, typeName, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeLocked(typeName, id)
+	return nil
+}
+
+func (m *MemoryIndex) removeLocked(typeName, id string) {
+	key := fieldKey(typeName, id)
+	for field, v := range m.values[key] {
+		fk := fieldKey(typeName, field)
+		switch v.Kind {
+		case facade.IndexExact:
+			if ids := m.exact[fk][v.Str]; ids != nil {
+				delete(ids, id)
+				if len(ids) == 0 {
+					delete(m.exact[fk], v.Str)
+				}
+			}
+		case facade.IndexOrdered:
+			delete(m.ordered[fk], id)
+		}
+	}
+	delete(m.values, key)
+}
+
+// QueryIndex 按索引查询一页 id
+func (m *MemoryIndex) QueryIndex(ctx context.Context, kind facade.IndexKind, q *facade.Query) (*facade.QueryResult, error) {
+	offset, limit, err := pageOf(q)
+	if err != nil {
+		return nil, err
+	}
+	fk := fieldKey(q.EntityType, q.Field)
+	var ids []string
+	m.mu.RLock()
+	switch kind {
+	case facade.IndexExact:
+		for id := range m.exact[fk][q.Eq] {
+			ids = append(ids, id)
+		}
+		m.mu.RUnlock()
+		sort.Strings(ids)
+	case facade.IndexOrdered:
+		type scored struct {
+			id    string
+			score float64
+		}
+		var hits []scored
+		for id, s := range m.ordered[fk] {
+			if (q.Min == nil || s >= *q.Min) && (q.Max == nil || s <= *q.Max) {
+				hits = append(hits, scored{id, s})
+			}
+		}
+		m.mu.RUnlock()
+		sort.Slice(hits, func(i, j int) bool {
+			if hits[i].score != hits[j].score {
+				return (hits[i].score < hits[j].score) != q.Desc
+			}
+			return (hits[i].id < hits[j].id) != q.Desc
+		})
+		ids = make([]string, len(hits))
+		for i, h := range hits {
+			ids[i] = h.id
+		}
+	default:
+		m.mu.RUnlock()
+		return nil, fmt.Errorf("%w: unknown index kind %d", facade.ErrInvalidConfig, kind)
+	}
+	if offset >= len(ids) {
+		return &facade.QueryResult{}, nil
+	}
+	end := offset + limit + 1
+	if end > len(ids) {
+		end = len(ids)
+	}
+	page := ids[offset:end]
+	res := &facade.QueryResult{Next: nextCursor(offset, limit, len(page))}
+	if len(page) > limit {
+		page = page[:limit]
+	}
+	res.IDs = append([]string(nil), page...)
+	return res, nil
+}
+
+var _ facade.IndexStorage = (*MemoryIndex)(nil)
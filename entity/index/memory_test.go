@@ -0,0 +1,67 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+func TestMemoryIndex_ExactPagination(t *testing.T) {
+	ctx := context.Background()
+	idx := NewMemoryIndex()
+	for _, id := range []string{"p3", "p1", "p2", "p4"} {
+		if err := idx.PutIndex(ctx, "player", id, map[string]facade.IndexValue{"guild": facade.ExactIndex("g1")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 换公会：旧索引项被替换
+	_ = idx.PutIndex(ctx, "player", "p4", map[string]facade.IndexValue{"guild": facade.ExactIndex("g2")})
+
+	q := &facade.Query{EntityType: "player", Field: "guild", Eq: "g1", Limit: 2}
+	var got []string
+	for {
+		res, err := idx.QueryIndex(ctx, facade.IndexExact, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, res.IDs...)
+		if res.Next == "" {
+			break
+		}
+		q.Cursor = res.Next
+	}
+	if !reflect.DeepEqual(got, []string{"p1", "p2", "p3"}) {
+		t.Fatalf("got %v", got)
+	}
+
+	_, err := idx.QueryIndex(ctx, facade.IndexExact, &facade.Query{EntityType: "player", Field: "guild", Cursor: "x"})
+	if !errors.Is(err, facade.ErrDecode) {
+		t.Fatalf("bad cursor err=%v", err)
+	}
+}
+
+func TestMemoryIndex_OrderedRange(t *testing.T) {
+	ctx := context.Background()
+	idx := NewMemoryIndex()
+	scores := map[string]float64{"a": 10, "b": 30, "c": 20, "d": 5}
+	for id, s := range scores {
+		_ = idx.PutIndex(ctx, "player", id, map[string]facade.IndexValue{"score": facade.OrderedIndex(s)})
+	}
+	_ = idx.DeleteIndex(ctx, "player", "d")
+
+	min := 10.0
+	res, err := idx.QueryIndex(ctx, facade.IndexOrdered, &facade.Query{EntityType: "player", Field: "score", Min: &min, Desc: true, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.IDs, []string{"b", "c"}) || res.Next == "" {
+		t.Fatalf("page1=%+v", res)
+	}
+	res, _ = idx.QueryIndex(ctx, facade.IndexOrdered, &facade.Query{EntityType: "player", Field: "score", Min: &min, Desc: true, Limit: 2, Cursor: res.Next})
+	if !reflect.DeepEqual(res.IDs, []string{"a"}) || res.Next != "" {
+		t.Fatalf("page2=%+v", res)
+	}
+}
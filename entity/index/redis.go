@@ -0,0 +1,140 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisIndex Redis 二级索引
+// 键布局（prefix 默认 "entity:idx"）：
+// - 精确索引：{prefix}:{type}:{field}:eq:{value}  ZSET，score 恒为 0，成员为 id（按字典序分页）
+// - 有序索引：{prefix}:{type}:{field}             ZSET，score 为取值，成员为 id
+// - 反向表：  {prefix}:{type}:rev:{id}             HASH，field -> 已写入的取值（替换/删除时清理旧索引项）
+// 单个实体的索引更新在一个 MULTI 事务内完成；集群模式下需保证同一类型的键位于同一 slot（可在 prefix 中使用 hash tag）。
+type RedisIndex struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisIndex 创建 Redis 索引；prefix 为空时使用 "entity:idx"
+func NewRedisIndex(client redis.Cmdable, prefix string) *RedisIndex {
+	if prefix == "" {
+		prefix = "entity:idx"
+	}
+	return &RedisIndex{client: client, prefix: prefix}
+}
+
+func (r *RedisIndex) exactKey(typeName, field, value string) string {
+	return r.prefix + ":" + typeName + ":" + field + ":eq:" + value
+}
+
+func (r *RedisIndex) orderedKey(typeName, field string) string {
+	return r.prefix + ":" + typeName + ":" + field
+}
+
+func (r *RedisIndex) revKey(typeName, id string) string {
+	return r.prefix + ":" + typeName + ":rev:" + id
+}
+
+// 反向表取值编码：精确索引 "e:"+value，有序索引 "o:"
+func encodeRev(v facade.IndexValue) string {
+	if v.Kind == facade.IndexExact {
+		return "e:" + v.Str
+	}
+	return "o:"
+}
+
+// PutIndex 以 values 整体替换实体的索引项
+func (r *RedisIndex) PutIndex(ctx context.Context, typeName, id string, values map[string]facade.IndexValue) error {
+	for field, v := range values {
+		if v.Kind != facade.IndexExact && v.Kind != facade.IndexOrdered {
+			return fmt.Errorf("%w: index %s/%s has unknown kind %d", facade.ErrInvalidConfig, typeName, field, v.Kind)
+		}
+	}
+	revKey := r.revKey(typeName, id)
+	old, err := r.client.HGetAll(ctx, revKey).Result()
+	if err != nil {
+		return err
+	}
+	_, err = r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		for field, enc := range old {
+			if v, ok := values[field]; ok && encodeRev(v) == enc {
+				continue
+			}
+			if value, ok := strings.CutPrefix(enc, "e:"); ok {
+				p.ZRem(ctx, r.exactKey(typeName, field, value), id)
+			} else if v, ok := values[field]; !ok || v.Kind != facade.IndexOrdered {
+				p.ZRem(ctx, r.orderedKey(typeName, field), id)
+			}
+		}
+		p.Del(ctx, revKey)
+		if len(values) == 0 {
+			return nil
+		}
+		rev := make(map[string]any, len(values))
+		for field, v := range values {
+			if v.Kind == facade.IndexExact {
+				p.ZAdd(ctx, r.exactKey(typeName, field, v.Str), redis.Z{Member: id})
+			} else {
+				p.ZAdd(ctx, r.orderedKey(typeName, field), redis.Z{Score: v.Num, Member: id})
+			}
+			rev[field] = encodeRev(v)
+		}
+		p.HSet(ctx, revKey, rev)
+		return nil
+	})
+	return err
+}
+
+// DeleteIndex 删除实体的全部索引项
+func (r *RedisIndex) DeleteIndex(ctx context.Context, typeName, id string) error {
+	return r.PutIndex(ctx, typeName, id, nil)
+}
+
+// QueryIndex 按索引查询一页 id
+func (r *RedisIndex) QueryIndex(ctx context.Context, kind facade.IndexKind, q *facade.Query) (*facade.QueryResult, error) {
+	offset, limit, err := pageOf(q)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	switch kind {
+	case facade.IndexExact:
+		ids, err = r.client.ZRange(ctx, r.exactKey(q.EntityType, q.Field, q.Eq), int64(offset), int64(offset+limit)).Result()
+	case facade.IndexOrdered:
+		ids, err = r.client.ZRangeArgs(ctx, redis.ZRangeArgs{
+			Key:     r.orderedKey(q.EntityType, q.Field),
+			Start:   scoreBound(q.Min, "-inf"),
+			Stop:    scoreBound(q.Max, "+inf"),
+			ByScore: true,
+			Rev:     q.Desc,
+			Offset:  int64(offset),
+			Count:   int64(limit + 1),
+		}).Result()
+	default:
+		return nil, fmt.Errorf("%w: unknown index kind %d", facade.ErrInvalidConfig, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := &facade.QueryResult{Next: nextCursor(offset, limit, len(ids))}
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	res.IDs = ids
+	return res, nil
+}
+
+func scoreBound(v *float64, unbounded string) string {
+	if v == nil {
+		return unbounded
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+var _ facade.IndexStorage = (*RedisIndex)(nil)
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// AppendEvents 追加事件；日志末尾序号与 expectSeq 不一致或事件序号不连续时返回 facade.ErrJournalConflict
+func (s *MemoryStore) AppendEvents(ctx context.Context, typeName, id string, expectSeq uint64, events []*facade.JournalEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := recordKey(typeName, id)
+	journal := s.events[key]
+	if last := uint64(len(journal)); last != expectSeq {
+		return fmt.Errorf("%w: %s at %d, expect %d", facade.ErrJournalConflict, key, last, expectSeq)
+	}
+	for i, ev := range events {
+		if ev.Seq != expectSeq+uint64(i)+1 {
+			return fmt.Errorf("%w: %s event seq %d out of order", facade.ErrJournalConflict, key, ev.Seq)
+		}
+	}
+	for _, ev := range events {
+		cp := *ev
+		cp.Data = append([]byte(nil), ev.Data...)
+		journal = append(journal, &cp)
+	}
+	s.events[key] = journal
+	return nil
+}
+
+// ReadEvents 按序返回 Seq 位于 (afterSeq, toSeq] 的事件；toSeq 为 0 表示直到末尾
+func (s *MemoryStore) ReadEvents(ctx context.Context, typeName, id string, afterSeq, toSeq uint64) ([]*facade.JournalEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	journal := s.events[recordKey(typeName, id)]
+	// 日志序号从 1 连续递增，第 i 个事件的序号为 i+1
+	end := uint64(len(journal))
+	if toSeq > 0 && toSeq < end {
+		end = toSeq
+	}
+	if afterSeq >= end {
+		return nil, nil
+	}
+	out := make([]*facade.JournalEvent, 0, end-afterSeq)
+	for _, ev := range journal[afterSeq:end] {
+		cp := *ev
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+// PutSnapshot 写入快照；保留历史快照以支持按时间点重放
+func (s *MemoryStore) PutSnapshot(ctx context.Context, typeName, id string, snap *facade.JournalSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := recordKey(typeName, id)
+	cp := *snap
+	cp.Payload = append([]byte(nil), snap.Payload...)
+	s.snaps[key] = append(s.snaps[key], &cp)
+	return nil
+}
+
+// LatestSnapshot 返回 Seq<=maxSeq 且 TimeMs<=maxTimeMs 的最新快照（0 表示不限）；不存在时返回 nil, nil
+func (s *MemoryStore) LatestSnapshot(ctx context.Context, typeName, id string, maxSeq uint64, maxTimeMs int64) (*facade.JournalSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var best *facade.JournalSnapshot
+	for _, sn := range s.snaps[recordKey(typeName, id)] {
+		if maxSeq > 0 && sn.Seq > maxSeq || maxTimeMs > 0 && sn.TimeMs > maxTimeMs {
+			continue
+		}
+		if best == nil || sn.Seq >= best.Seq {
+			best = sn
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	cp := *best
+	return &cp, nil
+}
+
+var _ facade.JournalStorage = (*MemoryStore)(nil)
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+func TestMemoryStore_Journal(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	evs := []*facade.JournalEvent{{Seq: 1, Kind: "a", TimeMs: 10}, {Seq: 2, Kind: "b", TimeMs: 20}}
+	if err := s.AppendEvents(ctx, "wallet", "w1", 0, evs); err != nil {
+		t.Fatal(err)
+	}
+	// 乐观并发：末尾序号不符或序号不连续均视为冲突
+	if err := s.AppendEvents(ctx, "wallet", "w1", 1, []*facade.JournalEvent{{Seq: 2}}); !errors.Is(err, facade.ErrJournalConflict) {
+		t.Fatalf("stale append err=%v", err)
+	}
+	if err := s.AppendEvents(ctx, "wallet", "w1", 2, []*facade.JournalEvent{{Seq: 4}}); !errors.Is(err, facade.ErrJournalConflict) {
+		t.Fatalf("gap append err=%v", err)
+	}
+	_ = s.AppendEvents(ctx, "wallet", "w1", 2, []*facade.JournalEvent{{Seq: 3, Kind: "c", TimeMs: 30}})
+
+	got, err := s.ReadEvents(ctx, "wallet", "w1", 1, 0)
+	if err != nil || len(got) != 2 || got[0].Seq != 2 || got[1].Seq != 3 {
+		t.Fatalf("read (1,end]: %+v %v", got, err)
+	}
+	if got, _ := s.ReadEvents(ctx, "wallet", "w1", 0, 2); len(got) != 2 || got[1].Kind != "b" {
+		t.Fatalf("read (0,2]: %+v", got)
+	}
+	if got, _ := s.ReadEvents(ctx, "wallet", "w1", 3, 0); len(got) != 0 {
+		t.Fatalf("read past end: %+v", got)
+	}
+
+	if snap, err := s.LatestSnapshot(ctx, "wallet", "w1", 0, 0); snap != nil || err != nil {
+		t.Fatalf("no snapshot yet: %+v %v", snap, err)
+	}
+	_ = s.PutSnapshot(ctx, "wallet", "w1", &facade.JournalSnapshot{Seq: 1, TimeMs: 15})
+	_ = s.PutSnapshot(ctx, "wallet", "w1", &facade.JournalSnapshot{Seq: 3, TimeMs: 35})
+	if snap, _ := s.LatestSnapshot(ctx, "wallet", "w1", 0, 0); snap == nil || snap.Seq != 3 {
+		t.Fatalf("latest: %+v", snap)
+	}
+	if snap, _ := s.LatestSnapshot(ctx, "wallet", "w1", 2, 0); snap == nil || snap.Seq != 1 {
+		t.Fatalf("latest <= seq 2: %+v", snap)
+	}
+	if snap, _ := s.LatestSnapshot(ctx, "wallet", "w1", 0, 10); snap != nil {
+		t.Fatalf("latest <= time 10: %+v", snap)
+	}
+}
@@ -0,0 +1,159 @@
+// Package store 提供实体存储驱动的内存实现（单进程、测试与本地开发使用）。
+// MemoryStore 除全量读写外，按可选能力实现 facade 中的存储接口：
+// - facade.FieldStorage：按字段（分区）部分读写，供增量保存的实体使用
+// - facade.BatchStorage：批量写入，供 write-behind 保存管道使用
+// - facade.JournalStorage：事件日志与快照，供事件溯源实体使用（见 journal.go）
+// - facade.RecordStorage / SoftDeleteStorage / ActivityStorage：整记录读写、软删除与归档扫描（见 record.go）
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// MemoryStore 内存存储驱动
+// 每个实体一条记录：全量 Payload 与分区 Fields 互不覆盖，Schema 取最近一次写入的版本。
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]*memRecord // type/id -> 记录
+	events  map[string][]*facade.JournalEvent
+	snaps   map[string][]*facade.JournalSnapshot
+}
+
+type memRecord struct {
+	typeName  string
+	id        string
+	payload   []byte
+	fields    map[string][]byte
+	index     map[string]facade.IndexValue
+	schema    int
+	updatedMs int64
+	purgeAtMs int64 // 软删除后的清除时间；0 表示未删除
+}
+
+// visible 记录存在且未被软删除
+func (r *memRecord) visible() bool { return r != nil && r.purgeAtMs == 0 }
+
+// NewMemoryStore 创建内存存储驱动
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]*memRecord),
+		events:  make(map[string][]*facade.JournalEvent),
+		snaps:   make(map[string][]*facade.JournalSnapshot),
+	}
+}
+
+func recordKey(typeName, id string) string { return typeName + "/" + id }
+
+// recordLocked 返回实体记录，不存在时创建；需持有写锁
+func (s *MemoryStore) recordLocked(typeName, id string) *memRecord {
+	key := recordKey(typeName, id)
+	rec := s.records[key]
+	if rec == nil {
+		rec = &memRecord{typeName: typeName, id: id}
+		s.records[key] = rec
+	}
+	return rec
+}
+
+// Put 写入实体的全量序列化结果
+func (s *MemoryStore) Put(ctx context.Context, typeName, id string, payload []byte, schema int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.recordLocked(typeName, id)
+	rec.payload = append([]byte(nil), payload...)
+	rec.schema = schema
+	rec.updatedMs = time.Now().UnixMilli()
+	return nil
+}
+
+// Get 读取实体的全量序列化结果；不存在或已软删除时返回 facade.ErrNotFound
+func (s *MemoryStore) Get(ctx context.Context, typeName, id string) ([]byte, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec := s.records[recordKey(typeName, id)]
+	if !rec.visible() || rec.payload == nil {
+		return nil, 0, facade.ErrNotFound
+	}
+	return append([]byte(nil), rec.payload...), rec.schema, nil
+}
+
+// PutFields 仅写入给定字段，其余字段保持不变
+func (s *MemoryStore) PutFields(ctx context.Context, typeName, id string, fields map[string][]byte, schema int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.recordLocked(typeName, id)
+	if rec.fields == nil {
+		rec.fields = make(map[string][]byte, len(fields))
+	}
+	for name, data := range fields {
+		rec.fields[name] = append([]byte(nil), data...)
+	}
+	rec.schema = schema
+	rec.updatedMs = time.Now().UnixMilli()
+	return nil
+}
+
+// GetFields 读取全部字段与 schema 版本；不存在或已软删除时返回 facade.ErrNotFound
+func (s *MemoryStore) GetFields(ctx context.Context, typeName, id string) (map[string][]byte, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec := s.records[recordKey(typeName, id)]
+	if !rec.visible() || len(rec.fields) == 0 {
+		return nil, 0, facade.ErrNotFound
+	}
+	return copyFields(rec.fields), rec.schema, nil
+}
+
+// BatchPut 批量写入：Payload 整体覆盖，Fields 按字段合并，索引取值（非 nil 时）随记录保存
+func (s *MemoryStore) BatchPut(ctx context.Context, records []*facade.SaveRecord) []error {
+	if err := ctx.Err(); err != nil {
+		errs := make([]error, len(records))
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UnixMilli()
+	for _, r := range records {
+		rec := s.recordLocked(r.TypeName, r.ID)
+		if r.Payload != nil {
+			rec.payload = append([]byte(nil), r.Payload...)
+		}
+		if len(r.Fields) > 0 {
+			if rec.fields == nil {
+				rec.fields = make(map[string][]byte, len(r.Fields))
+			}
+			for name, data := range r.Fields {
+				rec.fields[name] = append([]byte(nil), data...)
+			}
+		}
+		if r.Index != nil {
+			rec.index = copyIndex(r.Index)
+		}
+		rec.schema = r.Schema
+		rec.updatedMs = now
+	}
+	return nil
+}
+
+func copyFields(fields map[string][]byte) map[string][]byte {
+	if fields == nil {
+		return nil
+	}
+	out := make(map[string][]byte, len(fields))
+	for name, data := range fields {
+		out[name] = append([]byte(nil), data...)
+	}
+	return out
+}
+
+var (
+	_ facade.FieldStorage = (*MemoryStore)(nil)
+	_ facade.BatchStorage = (*MemoryStore)(nil)
+)
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+func TestMemoryStore_Fields(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	if _, _, err := s.GetFields(ctx, "player", "p1"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("want ErrNotFound, got %v", err)
+	}
+	_ = s.PutFields(ctx, "player", "p1", map[string][]byte{"name": []byte(`"a"`), "bag": []byte(`{}`)}, 1)
+	// 部分写入只覆盖给定字段
+	_ = s.PutFields(ctx, "player", "p1", map[string][]byte{"bag": []byte(`{"gold":1}`)}, 2)
+	fields, schema, err := s.GetFields(ctx, "player", "p1")
+	if err != nil || schema != 2 {
+		t.Fatalf("get fields: schema=%d err=%v", schema, err)
+	}
+	if string(fields["name"]) != `"a"` || string(fields["bag"]) != `{"gold":1}` {
+		t.Fatalf("fields=%q", fields)
+	}
+	// 返回副本，修改不影响存储
+	fields["name"][0] = 'x'
+	if again, _, _ := s.GetFields(ctx, "player", "p1"); string(again["name"]) != `"a"` {
+		t.Fatalf("stored field mutated: %q", again["name"])
+	}
+
+	if _, _, err := s.Get(ctx, "player", "p1"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("fields-only record has no payload, got %v", err)
+	}
+	_ = s.Put(ctx, "player", "p1", []byte("full"), 3)
+	if payload, schema, err := s.Get(ctx, "player", "p1"); err != nil || string(payload) != "full" || schema != 3 {
+		t.Fatalf("get: %q %d %v", payload, schema, err)
+	}
+}
+
+func TestMemoryStore_BatchPut(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	_ = s.PutFields(ctx, "player", "p1", map[string][]byte{"name": []byte(`"a"`)}, 1)
+	errs := s.BatchPut(ctx, []*facade.SaveRecord{
+		{TypeName: "player", ID: "p1", Fields: map[string][]byte{"bag": []byte(`{}`)}, Schema: 2},
+		{TypeName: "user", ID: "u1", Payload: []byte("v1"), Schema: 1},
+	})
+	if errs != nil {
+		t.Fatalf("batch put: %v", errs)
+	}
+	fields, schema, err := s.GetFields(ctx, "player", "p1")
+	if err != nil || schema != 2 || string(fields["name"]) != `"a"` || string(fields["bag"]) != `{}` {
+		t.Fatalf("fields=%q schema=%d err=%v", fields, schema, err)
+	}
+	if payload, _, err := s.Get(ctx, "user", "u1"); err != nil || string(payload) != "v1" {
+		t.Fatalf("get: %q %v", payload, err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	errs = s.BatchPut(cctx, []*facade.SaveRecord{{TypeName: "user", ID: "u2", Payload: []byte("v1")}})
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Fatalf("canceled batch: %v", errs)
+	}
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+// GetRecord 读取实体的完整记录；不存在或已软删除时返回 facade.ErrNotFound
+func (s *MemoryStore) GetRecord(ctx context.Context, typeName, id string) (*facade.SaveRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec := s.records[recordKey(typeName, id)]
+	if !rec.visible() || rec.payload == nil && len(rec.fields) == 0 {
+		return nil, facade.ErrNotFound
+	}
+	out := &facade.SaveRecord{
+		TypeName: typeName,
+		ID:       id,
+		Fields:   copyFields(rec.fields),
+		Schema:   rec.schema,
+		Index:    copyIndex(rec.index),
+	}
+	if rec.payload != nil {
+		out.Payload = append([]byte(nil), rec.payload...)
+	}
+	return out, nil
+}
+
+// PutRecord 整体写入记录（覆盖全量、字段与索引取值，并清除软删除标记）
+func (s *MemoryStore) PutRecord(ctx context.Context, r *facade.SaveRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.recordLocked(r.TypeName, r.ID)
+	rec.payload = nil
+	if r.Payload != nil {
+		rec.payload = append([]byte(nil), r.Payload...)
+	}
+	rec.fields = copyFields(r.Fields)
+	rec.index = copyIndex(r.Index)
+	rec.schema = r.Schema
+	rec.updatedMs = time.Now().UnixMilli()
+	rec.purgeAtMs = 0
+	return nil
+}
+
+// DeleteRecord 物理删除实体的记录与事件日志；不存在时返回 nil
+func (s *MemoryStore) DeleteRecord(ctx context.Context, typeName, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteLocked(recordKey(typeName, id))
+	return nil
+}
+
+func (s *MemoryStore) deleteLocked(key string) {
+	delete(s.records, key)
+	delete(s.events, key)
+	delete(s.snaps, key)
+}
+
+// MarkDeleted 软删除：数据保留至 purgeAtMs，期间读取视为不存在；记录不存在时返回 nil
+func (s *MemoryStore) MarkDeleted(ctx context.Context, typeName, id string, purgeAtMs int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec := s.records[recordKey(typeName, id)]; rec != nil {
+		rec.purgeAtMs = purgeAtMs
+	}
+	return nil
+}
+
+// Undelete 撤销软删除；不存在软删除标记时返回 facade.ErrNotFound
+func (s *MemoryStore) Undelete(ctx context.Context, typeName, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.records[recordKey(typeName, id)]
+	if rec == nil || rec.purgeAtMs == 0 {
+		return facade.ErrNotFound
+	}
+	rec.purgeAtMs = 0
+	return nil
+}
+
+// PurgeDeleted 物理删除保留期已过的软删除数据（limit<=0 表示不限），返回删除数量
+func (s *MemoryStore) PurgeDeleted(ctx context.Context, nowMs int64, limit int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for key, rec := range s.records {
+		if limit > 0 && n >= limit {
+			break
+		}
+		if rec.purgeAtMs > 0 && rec.purgeAtMs <= nowMs {
+			s.deleteLocked(key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// ListInactive 返回最后写入早于 beforeMs 的实体 id（有序，至多 limit 个；limit<=0 表示不限）
+// 已软删除的实体不在其列。
+func (s *MemoryStore) ListInactive(ctx context.Context, typeName string, beforeMs int64, limit int) ([]string, error) {
+	s.mu.RLock()
+	var ids []string
+	for _, rec := range s.records {
+		if rec.typeName == typeName && rec.visible() && rec.updatedMs < beforeMs {
+			ids = append(ids, rec.id)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Strings(ids)
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func copyIndex(index map[string]facade.IndexValue) map[string]facade.IndexValue {
+	if index == nil {
+		return nil
+	}
+	out := make(map[string]facade.IndexValue, len(index))
+	for k, v := range index {
+		out[k] = v
+	}
+	return out
+}
+
+var (
+	_ facade.RecordStorage     = (*MemoryStore)(nil)
+	_ facade.SoftDeleteStorage = (*MemoryStore)(nil)
+	_ facade.ActivityStorage   = (*MemoryStore)(nil)
+)
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+)
+
+func TestMemoryStore_RecordSoftDeleteAndActivity(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	rec := &facade.SaveRecord{TypeName: "user", ID: "u1", Payload: []byte("v"), Schema: 2,
+		Index: map[string]facade.IndexValue{"guild": facade.ExactIndex("g")}}
+	_ = s.PutRecord(ctx, rec)
+	_ = s.PutRecord(ctx, &facade.SaveRecord{TypeName: "user", ID: "u2", Payload: []byte("v")})
+	_ = s.PutRecord(ctx, &facade.SaveRecord{TypeName: "bag", ID: "u1", Payload: []byte("v")})
+	got, err := s.GetRecord(ctx, "user", "u1")
+	if err != nil || !reflect.DeepEqual(got, rec) {
+		t.Fatalf("get record: %+v %v", got, err)
+	}
+
+	// 软删除期间读取视为不存在，可撤销
+	_ = s.MarkDeleted(ctx, "user", "u1", time.Now().Add(time.Hour).UnixMilli())
+	if _, err := s.GetRecord(ctx, "user", "u1"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("soft-deleted record visible: %v", err)
+	}
+	if _, _, err := s.Get(ctx, "user", "u1"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("soft-deleted payload visible: %v", err)
+	}
+	if err := s.Undelete(ctx, "user", "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Undelete(ctx, "user", "u1"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("undelete without mark: %v", err)
+	}
+
+	ids, _ := s.ListInactive(ctx, "user", time.Now().Add(time.Second).UnixMilli(), 0)
+	if !reflect.DeepEqual(ids, []string{"u1", "u2"}) {
+		t.Fatalf("inactive=%v", ids)
+	}
+	if ids, _ := s.ListInactive(ctx, "user", time.Now().Add(time.Second).UnixMilli(), 1); len(ids) != 1 {
+		t.Fatalf("limit ignored: %v", ids)
+	}
+
+	// 保留期已过的软删除数据被清除
+	_ = s.MarkDeleted(ctx, "user", "u2", time.Now().Add(-time.Second).UnixMilli())
+	if n, err := s.PurgeDeleted(ctx, time.Now().UnixMilli(), 10); err != nil || n != 1 {
+		t.Fatalf("purged=%d err=%v", n, err)
+	}
+	if err := s.Undelete(ctx, "user", "u2"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("purged record recoverable: %v", err)
+	}
+
+	_ = s.DeleteRecord(ctx, "bag", "u1")
+	if _, err := s.GetRecord(ctx, "bag", "u1"); !errors.Is(err, facade.ErrNotFound) {
+		t.Fatalf("deleted record visible: %v", err)
+	}
+	if err := s.DeleteRecord(ctx, "bag", "missing"); err != nil {
+		t.Fatalf("delete missing: %v", err)
+	}
+}
@@ -27,6 +27,12 @@ func NewPromise[T any](fn func(resolve func(T), reject func(error))) *Promise[T]
 	return p
 }
 
+// Pending 创建一个由外部完成的 Promise（不启动 goroutine），返回 Promise 及其 resolve/reject
+func Pending[T any]() (*Promise[T], func(T), func(error)) {
+	p := &Promise[T]{done: make(chan struct{})}
+	return p, p.resolve, p.reject
+}
+
 func (p *Promise[T]) resolve(val T) {
 	p.once.Do(func() {
 		p.value = val
@@ -51,6 +57,31 @@ func (p *Promise[T]) Await(ctx context.Context) (T, error) {
 	}
 }
 
+// Done 返回完成信号（resolve 或 reject 后关闭）
+func (p *Promise[T]) Done() <-chan struct{} {
+	return p.done
+}
+
+// Timeout 返回带超时的 Promise：d 内未完成则以 context.DeadlineExceeded 拒绝
+func Timeout[T any](p *Promise[T], d time.Duration) *Promise[T] {
+	out, resolve, reject := Pending[T]()
+	go func() {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-p.done:
+			if p.err != nil {
+				reject(p.err)
+			} else {
+				resolve(p.value)
+			}
+		case <-timer.C:
+			reject(context.DeadlineExceeded)
+		}
+	}()
+	return out
+}
+
 func Resolve[T any](val T) *Promise[T] {
 	return NewPromise(func(resolve func(T), reject func(error)) {
 		resolve(val)
@@ -107,3 +107,20 @@ func TestCatchOnReject(t *testing.T) {
 	}
 	t.Logf("Catch recovered with value: %d", val)
 }
+
+func TestPendingAndTimeout(t *testing.T) {
+	p, resolve, _ := Pending[int]()
+	if _, err := Timeout(p, 20*time.Millisecond).Await(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded, got %v", err)
+	}
+	resolve(7)
+	v, err := Timeout(p, time.Second).Await(context.Background())
+	if err != nil || v != 7 {
+		t.Fatalf("want 7, got %v %v", v, err)
+	}
+	select {
+	case <-p.Done():
+	default:
+		t.Fatalf("done not closed after resolve")
+	}
+}
@@ -210,6 +210,23 @@ func (c *StatefulRouteForClientDriverImpl) CacheExpired(
 	return 0, fmt.Errorf("no valid cache found")
 }
 
+// SetLinkCacheTTL 设置链接信息本地缓存的时长（秒级），此后写入的缓存生效；不足 1 秒时保持当前值
+func (c *StatefulRouteForClientDriverImpl) SetLinkCacheTTL(ttl time.Duration) {
+	if ttl < time.Second {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.userLinkInfoCacheTime = int(ttl / time.Second)
+}
+
+// linkCacheTTL 链接信息本地缓存的时长
+func (c *StatefulRouteForClientDriverImpl) linkCacheTTL() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Duration(c.userLinkInfoCacheTime) * time.Second
+}
+
 // SetCache 设置连接信息到缓存
 func (c *StatefulRouteForClientDriverImpl) SetCache(
 	ctx context.Context,
@@ -225,7 +242,7 @@ func (c *StatefulRouteForClientDriverImpl) SetCache(
 		}
 	}
 
-	c.userPodIndexCache.Set(key, podIndex, cache.DefaultExpiration)
+	c.userPodIndexCache.Set(key, podIndex, c.linkCacheTTL())
 
 	if previousPodIndex != 0 {
 		return previousPodIndex, nil
@@ -1,7 +1,9 @@
 package driver
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/stretchr/testify/assert"
@@ -60,3 +62,19 @@ func TestBaseConfig(t *testing.T) {
 	assert.Equal(t, 300, baseConfig.TempLinkInfoCacheTimeSeconds)
 	assert.Equal(t, 240, baseConfig.UserLinkInfoCacheTimeSeconds)
 }
+
+func TestClientDriver_SetLinkCacheTTL(t *testing.T) {
+	c := NewStatefulRouteForClientDriverImpl(nil, nil, &route.ServerInfo{}, &SimpleLogger{})
+	assert.NoError(t, c.Init())
+	ctx := context.Background()
+
+	c.SetLinkCacheTTL(50 * time.Millisecond)
+	// 不足 1 秒保持默认值
+	assert.Equal(t, 240*time.Second, c.linkCacheTTL())
+
+	c.SetLinkCacheTTL(time.Second)
+	_, _ = c.SetCache(ctx, "ns", "u1", "svc", 3)
+	_, exp, ok := c.userPodIndexCache.GetWithExpiration(c.formatCacheKey("ns", "u1", "svc"))
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Second), exp, 200*time.Millisecond)
+}
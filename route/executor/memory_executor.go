@@ -0,0 +1,268 @@
+package executor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/route"
+)
+
+// MemoryStatefulExecutor 基于内存的有状态执行器
+// 语义与 Redis Lua 脚本版本保持一致（持久链接 / 临时链接 / 按 podId 条件删除），
+// 用于单进程测试与多 Pod 模拟，无需外部 Redis。
+type MemoryStatefulExecutor struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	states    map[string]map[int]string // namespace/service -> podID -> state
+	workloads map[string]string         // namespace/service -> state
+	links     map[string]*memoryLink    // namespace/service/uid -> link
+	uidLinks  map[string]map[string]int // namespace/uid -> service -> podID（仅持久链接）
+}
+
+type memoryLink struct {
+	podID    int
+	expireAt time.Time // 零值表示持久
+}
+
+// NewMemoryStatefulExecutor 创建内存执行器
+func NewMemoryStatefulExecutor() *MemoryStatefulExecutor {
+	return &MemoryStatefulExecutor{
+		now:       time.Now,
+		states:    make(map[string]map[int]string),
+		workloads: make(map[string]string),
+		links:     make(map[string]*memoryLink),
+		uidLinks:  make(map[string]map[string]int),
+	}
+}
+
+func memoryServiceKey(namespace, serviceName string) string { return namespace + "/" + serviceName }
+
+func memoryLinkKey(namespace, serviceName, uid string) string {
+	return namespace + "/" + serviceName + "/" + uid
+}
+
+// SetServiceState 设置服务中特定Pod的状态
+func (e *MemoryStatefulExecutor) SetServiceState(ctx context.Context, namespace, serviceName string, podID int, state string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := memoryServiceKey(namespace, serviceName)
+	m := e.states[key]
+	if m == nil {
+		m = make(map[int]string)
+		e.states[key] = m
+	}
+	m[podID] = state
+	return nil
+}
+
+// RemoveServiceState 移除特定Pod的状态（模拟 Pod 下线后状态过期）
+func (e *MemoryStatefulExecutor) RemoveServiceState(ctx context.Context, namespace, serviceName string, podID int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.states[memoryServiceKey(namespace, serviceName)], podID)
+	return nil
+}
+
+// GetServiceState 获取特定服务的所有Pod状态
+func (e *MemoryStatefulExecutor) GetServiceState(ctx context.Context, namespace, serviceName string) (map[int]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ret := make(map[int]string)
+	for podID, state := range e.states[memoryServiceKey(namespace, serviceName)] {
+		ret[podID] = state
+	}
+	return ret, nil
+}
+
+// SetWorkloadState 设置整个工作负载（服务）的状态
+func (e *MemoryStatefulExecutor) SetWorkloadState(ctx context.Context, namespace, serviceName, state string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.workloads[memoryServiceKey(namespace, serviceName)] = state
+	return nil
+}
+
+// GetWorkloadState 获取工作负载状态
+func (e *MemoryStatefulExecutor) GetWorkloadState(ctx context.Context, namespace, serviceName string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.workloads[memoryServiceKey(namespace, serviceName)], nil
+}
+
+// GetWorkloadStateBatch 批量获取工作负载状态
+func (e *MemoryStatefulExecutor) GetWorkloadStateBatch(ctx context.Context, namespace string, serviceNames []string) (map[string]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ret := make(map[string]string, len(serviceNames))
+	for _, name := range serviceNames {
+		if state, ok := e.workloads[memoryServiceKey(namespace, name)]; ok {
+			ret[name] = state
+		}
+	}
+	return ret, nil
+}
+
+// getLinkLocked 返回未过期的链接（过期的链接在读取时清理）
+func (e *MemoryStatefulExecutor) getLinkLocked(key string) *memoryLink {
+	l := e.links[key]
+	if l == nil {
+		return nil
+	}
+	if !l.expireAt.IsZero() && !e.now().Before(l.expireAt) {
+		delete(e.links, key)
+		return nil
+	}
+	return l
+}
+
+// setLinkLocked 写入链接：persistSeconds 为 -1 表示持久，否则为临时链接
+func (e *MemoryStatefulExecutor) setLinkLocked(namespace, uid, serviceName string, podID, persistSeconds int) {
+	l := &memoryLink{podID: podID}
+	uidKey := memoryServiceKey(namespace, uid)
+	if persistSeconds == -1 {
+		m := e.uidLinks[uidKey]
+		if m == nil {
+			m = make(map[string]int)
+			e.uidLinks[uidKey] = m
+		}
+		m[serviceName] = podID
+	} else {
+		l.expireAt = e.now().Add(time.Duration(persistSeconds) * time.Second)
+		e.delUIDLinkLocked(uidKey, serviceName)
+	}
+	e.links[memoryLinkKey(namespace, serviceName, uid)] = l
+}
+
+func (e *MemoryStatefulExecutor) delUIDLinkLocked(uidKey, serviceName string) {
+	if m := e.uidLinks[uidKey]; m != nil {
+		delete(m, serviceName)
+		if len(m) == 0 {
+			delete(e.uidLinks, uidKey)
+		}
+	}
+}
+
+// SetLinkedPod 为UID设置链接的Pod，返回之前的 podID（不存在为 -1）
+func (e *MemoryStatefulExecutor) SetLinkedPod(ctx context.Context, namespace, uid, serviceName string, podID, persistSeconds int) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	prev := -1
+	if l := e.getLinkLocked(memoryLinkKey(namespace, serviceName, uid)); l != nil {
+		prev = l.podID
+	}
+	e.setLinkLocked(namespace, uid, serviceName, podID, persistSeconds)
+	return prev, nil
+}
+
+// TrySetLinkedPod 尝试设置链接：已被其他 Pod 持久占有时失败并返回当前 podID
+func (e *MemoryStatefulExecutor) TrySetLinkedPod(ctx context.Context, namespace, uid, serviceName string, podID, persistSeconds int) (bool, int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if l := e.getLinkLocked(memoryLinkKey(namespace, serviceName, uid)); l != nil && l.podID != podID && l.expireAt.IsZero() {
+		return false, l.podID, nil
+	}
+	e.setLinkLocked(namespace, uid, serviceName, podID, persistSeconds)
+	return true, podID, nil
+}
+
+// SetLinkedPodIfAbsent 仅在不存在链接时设置，返回生效的 podID
+func (e *MemoryStatefulExecutor) SetLinkedPodIfAbsent(ctx context.Context, namespace, uid, serviceName string, podID, persistSeconds int) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if l := e.getLinkLocked(memoryLinkKey(namespace, serviceName, uid)); l != nil {
+		return l.podID, nil
+	}
+	e.setLinkLocked(namespace, uid, serviceName, podID, persistSeconds)
+	return podID, nil
+}
+
+// GetLinkedPod 获取UID和服务当前链接的Pod（不存在为 -1）
+func (e *MemoryStatefulExecutor) GetLinkedPod(ctx context.Context, namespace, uid, serviceName string) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if l := e.getLinkLocked(memoryLinkKey(namespace, serviceName, uid)); l != nil {
+		return l.podID, nil
+	}
+	return -1, nil
+}
+
+// GetLinkedPodIfPersist 仅在链接持久时返回 podID（否则为 -1）
+func (e *MemoryStatefulExecutor) GetLinkedPodIfPersist(ctx context.Context, namespace, uid, serviceName string) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if l := e.getLinkLocked(memoryLinkKey(namespace, serviceName, uid)); l != nil && l.expireAt.IsZero() {
+		return l.podID, nil
+	}
+	return -1, nil
+}
+
+// BatchGetLinkedPod 批量获取多个UID的链接Pod
+func (e *MemoryStatefulExecutor) BatchGetLinkedPod(ctx context.Context, namespace string, keys []string, serviceName string) (map[int][]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ret := make(map[int][]string)
+	for _, uid := range keys {
+		if l := e.getLinkLocked(memoryLinkKey(namespace, serviceName, uid)); l != nil {
+			ret[l.podID] = append(ret[l.podID], uid)
+		}
+	}
+	return ret, nil
+}
+
+// RemoveLinkedPod 移除Pod链接
+func (e *MemoryStatefulExecutor) RemoveLinkedPod(ctx context.Context, namespace, uid, serviceName string, persistSeconds int) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := memoryLinkKey(namespace, serviceName, uid)
+	l := e.getLinkLocked(key)
+	delete(e.links, key)
+	e.delUIDLinkLocked(memoryServiceKey(namespace, uid), serviceName)
+	return l != nil, nil
+}
+
+// RemoveLinkedPodWithId 仅当链接指向 podID 时移除
+func (e *MemoryStatefulExecutor) RemoveLinkedPodWithId(ctx context.Context, namespace, uid, serviceName string, persistSeconds, podID int) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := memoryLinkKey(namespace, serviceName, uid)
+	l := e.getLinkLocked(key)
+	if l == nil || l.podID != podID {
+		return false, nil
+	}
+	delete(e.links, key)
+	e.delUIDLinkLocked(memoryServiceKey(namespace, uid), serviceName)
+	return true, nil
+}
+
+// GetLinkService 获取特定UID持久链接的所有服务
+func (e *MemoryStatefulExecutor) GetLinkService(ctx context.Context, namespace, uid string) (map[string]int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ret := make(map[string]int)
+	for svc, podID := range e.uidLinks[memoryServiceKey(namespace, uid)] {
+		ret[svc] = podID
+	}
+	return ret, nil
+}
+
+// LinkedUIDs 返回链接到指定 Pod 的全部 UID（有序，便于测试断言）
+func (e *MemoryStatefulExecutor) LinkedUIDs(namespace, serviceName string, podID int) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	prefix := memoryServiceKey(namespace, serviceName) + "/"
+	var uids []string
+	for key := range e.links {
+		if len(key) <= len(prefix) || key[:len(prefix)] != prefix {
+			continue
+		}
+		if l := e.getLinkLocked(key); l != nil && l.podID == podID {
+			uids = append(uids, key[len(prefix):])
+		}
+	}
+	sort.Strings(uids)
+	return uids
+}
+
+var _ route.StatefulExecutor = (*MemoryStatefulExecutor)(nil)
@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStatefulExecutor_LinkSemantics(t *testing.T) {
+	ctx := context.Background()
+	e := NewMemoryStatefulExecutor()
+
+	// 临时链接可被其他 Pod 抢占为持久链接
+	if pod, _ := e.SetLinkedPodIfAbsent(ctx, "ns", "u1", "svc", 1, 300); pod != 1 {
+		t.Fatalf("SetLinkedPodIfAbsent=%d want 1", pod)
+	}
+	if ok, cur, _ := e.TrySetLinkedPod(ctx, "ns", "u1", "svc", 2, -1); !ok || cur != 2 {
+		t.Fatalf("TrySetLinkedPod over temp link: ok=%v cur=%d", ok, cur)
+	}
+	// 持久链接不可被其他 Pod 抢占
+	if ok, cur, _ := e.TrySetLinkedPod(ctx, "ns", "u1", "svc", 3, -1); ok || cur != 2 {
+		t.Fatalf("TrySetLinkedPod over persist link: ok=%v cur=%d", ok, cur)
+	}
+	if pod, _ := e.GetLinkedPodIfPersist(ctx, "ns", "u1", "svc"); pod != 2 {
+		t.Fatalf("GetLinkedPodIfPersist=%d want 2", pod)
+	}
+	if svcs, _ := e.GetLinkService(ctx, "ns", "u1"); svcs["svc"] != 2 {
+		t.Fatalf("GetLinkService=%v", svcs)
+	}
+	if uids := e.LinkedUIDs("ns", "svc", 2); len(uids) != 1 || uids[0] != "u1" {
+		t.Fatalf("LinkedUIDs=%v", uids)
+	}
+	// 按 podId 条件删除
+	if ok, _ := e.RemoveLinkedPodWithId(ctx, "ns", "u1", "svc", 0, 3); ok {
+		t.Fatalf("remove with wrong pod should fail")
+	}
+	if ok, _ := e.RemoveLinkedPodWithId(ctx, "ns", "u1", "svc", 0, 2); !ok {
+		t.Fatalf("remove with owner pod should succeed")
+	}
+	if pod, _ := e.GetLinkedPod(ctx, "ns", "u1", "svc"); pod != -1 {
+		t.Fatalf("GetLinkedPod after remove=%d want -1", pod)
+	}
+}
+
+func TestMemoryStatefulExecutor_TempLinkExpire(t *testing.T) {
+	ctx := context.Background()
+	e := NewMemoryStatefulExecutor()
+	now := time.Unix(1000, 0)
+	e.now = func() time.Time { return now }
+
+	_, _ = e.SetLinkedPod(ctx, "ns", "u1", "svc", 1, 10)
+	if pod, _ := e.GetLinkedPodIfPersist(ctx, "ns", "u1", "svc"); pod != -1 {
+		t.Fatalf("temp link should not be persist, got %d", pod)
+	}
+	now = now.Add(11 * time.Second)
+	if pod, _ := e.GetLinkedPod(ctx, "ns", "u1", "svc"); pod != -1 {
+		t.Fatalf("temp link should expire, got %d", pod)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
+	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"google.golang.org/protobuf/proto"
@@ -22,7 +24,11 @@ func (c *CallDispatcher) Dispatch(ctx context.Context, fun string, req RpcConten
 	if !exists {
 		return nil, errors.New(404, "METHOD_NOT_FOUND", fmt.Sprintf("Method not found for msgId: %s", fun))
 	}
-	return funInfo.Packer.Call(ctx, req, funInfo)
+	packer := funInfo.PackerFor(req.Type())
+	if packer == nil {
+		return nil, errors.New(400, "UNSUPPORTED_METHOD", fmt.Sprintf("no packer for msgId: %s", fun))
+	}
+	return packer.Call(ctx, req, funInfo)
 }
 
 // NewService 创建新的服务
@@ -34,15 +40,16 @@ func NewCallDispatcher(service any) *CallDispatcher {
 		name:      serviceName,
 		RpcMethod: make(map[string]*FunInfo),
 	}
+	methodOpts := methodOptionsOf(service)
 	// 遍历服务的方法
 	for i := 0; i < serviceType.NumMethod(); i++ {
 		method := serviceType.Method(i)
 		methodValue := serviceValue.Method(i)
 
-		// 跳过私有方法
-		if method.IsExported() {
+		// 跳过私有方法与方法选项声明
+		if method.IsExported() && !(methodOpts != nil && method.Name == "RpcMethodOptions") {
 			// 注册方法
-			err := c.RegisterMethod(method.Name, methodValue.Interface())
+			err := c.RegisterMethod(method.Name, methodValue.Interface(), methodOpts[method.Name]...)
 			if err != nil {
 				log.Errorf("Failed to register Method %s: %v", method.Name, err)
 				continue
@@ -54,8 +61,8 @@ func NewCallDispatcher(service any) *CallDispatcher {
 	return c
 }
 
-// RegisterMethod 注册RPC方法
-func (s *CallDispatcher) RegisterMethod(msgId string, method interface{}) error {
+// RegisterMethod 注册RPC方法，opts 可覆盖按签名推断的打包器与编解码器
+func (s *CallDispatcher) RegisterMethod(msgId string, method interface{}, opts ...MethodOption) error {
 	if method != nil {
 		methodValue := reflect.ValueOf(method)
 		methodType := methodValue.Type()
@@ -65,79 +72,95 @@ func (s *CallDispatcher) RegisterMethod(msgId string, method interface{}) error
 			return errors.New(400, "INVALID_METHOD", "Method must be a function")
 		}
 
-		protoMessageType := reflect.TypeOf((*proto.Message)(nil)).Elem()
-		ctxType := reflect.TypeOf((*context.Context)(nil)).Elem()
-		errorType := reflect.TypeOf((*error)(nil)).Elem()
-
-		// 分析参数
-		paramInfos := make([]ParamInfo, 0, methodType.NumIn())
-		for i := 0; i < methodType.NumIn(); i++ {
-			paramType := methodType.In(i)
-			// 跳过 context.Context
-			if paramType == ctxType {
-				continue
-			}
-			paramName := paramType.String()
-			codec := "json"
-			underlying := paramType
-			if underlying.Kind() == reflect.Ptr {
-				underlying = underlying.Elem()
-			}
-			// 判定 proto
-			if paramType.Implements(protoMessageType) || reflect.PtrTo(underlying).Implements(protoMessageType) {
-				codec = "proto"
-			} else if underlying.Kind() == reflect.Struct && underlying.Name() != "" {
-				codec = "json"
-			} else {
-				codec = "msgpack"
-			}
-			paramInfos = append(paramInfos, ParamInfo{
-				Name:  paramName,
-				Type:  paramType,
-				Codec: codec,
-			})
+		// 分析签名并选择合适的打包器
+		funInfo, err := newFunInfo(msgId, methodType, opts...)
+		if err != nil {
+			return err
 		}
+		funInfo.Method = methodValue
 
-		// 分析返回值（忽略 error 类型）
-		returnParamInfos := make([]ParamInfo, 0, methodType.NumOut())
-		for i := 0; i < methodType.NumOut(); i++ {
-			returnType := methodType.Out(i)
-			if returnType.Implements(errorType) {
-				// 跳过 error 返回
-				continue
-			}
-			paramName := returnType.String()
-			codec := "json"
-			underlying := returnType
-			if underlying.Kind() == reflect.Ptr {
-				underlying = underlying.Elem()
-			}
-			if returnType.Implements(protoMessageType) || reflect.PtrTo(underlying).Implements(protoMessageType) {
-				codec = "proto"
-			} else if underlying.Kind() == reflect.Struct && underlying.Name() != "" {
-				codec = "json"
-			} else {
-				codec = "msgpack"
-			}
-			returnParamInfos = append(returnParamInfos, ParamInfo{
-				Name:  paramName,
-				Type:  returnType,
-				Codec: codec,
-			})
+		s.RpcMethod[msgId] = funInfo
+	}
+	return nil
+}
+
+// analyzeSignature 分析方法签名得到参数与返回值信息（跳过 context.Context 参数与 error 返回值）
+// 服务端注册与客户端代理共用同一套规则，保证双方选择相同的编解码器与打包器。
+func analyzeSignature(methodType reflect.Type) (params []ParamInfo, returns []ParamInfo) {
+	ctxType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+
+	// 分析参数
+	params = make([]ParamInfo, 0, methodType.NumIn())
+	for i := 0; i < methodType.NumIn(); i++ {
+		paramType := methodType.In(i)
+		// 跳过 context.Context
+		if paramType == ctxType {
+			continue
 		}
+		params = append(params, ParamInfo{
+			Name:  paramType.String(),
+			Type:  paramType,
+			Codec: codecOf(paramType),
+		})
+	}
 
-		// 创建方法信息
-		funInfo := &FunInfo{
-			Name:        msgId,
-			Method:      methodValue,
-			Param:       paramInfos,
-			ReturnParam: returnParamInfos,
+	// 分析返回值（忽略 error 类型）
+	returns = make([]ParamInfo, 0, methodType.NumOut())
+	for i := 0; i < methodType.NumOut(); i++ {
+		returnType := methodType.Out(i)
+		if returnType.Implements(errorType) {
+			// 跳过 error 返回
+			continue
 		}
-		// 根据方法参数和返回值选择合适的打包器
-		packer := SelectPacker(funInfo)
-		funInfo.Packer = packer
+		returns = append(returns, ParamInfo{
+			Name:  returnType.String(),
+			Type:  returnType,
+			Codec: codecOf(returnType),
+		})
+	}
+	return params, returns
+}
 
-		s.RpcMethod[msgId] = funInfo
+// codecOf 按类型选择编解码器：结构体标签指定的优先，proto 消息用 proto，具名结构体用 json，其余用 msgpack
+func codecOf(t reflect.Type) string {
+	protoMessageType := reflect.TypeOf((*proto.Message)(nil)).Elem()
+	underlying := t
+	if underlying.Kind() == reflect.Ptr {
+		underlying = underlying.Elem()
 	}
-	return nil
+	if codec := tagCodec(underlying); codec != "" {
+		return codec
+	}
+	// 判定 proto
+	if t.Implements(protoMessageType) || reflect.PtrTo(underlying).Implements(protoMessageType) {
+		return "proto"
+	} else if underlying.Kind() == reflect.Struct && underlying.Name() != "" {
+		return "json"
+	}
+	return "msgpack"
+}
+
+// tagCodec 读取结构体占位字段上的编解码器标签，如：
+//
+//	type HotReq struct {
+//		_ struct{} `rpc:"codec=msgpack"`
+//		...
+//	}
+func tagCodec(t reflect.Type) string {
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name != "_" {
+			continue
+		}
+		for _, kv := range strings.Split(f.Tag.Get("rpc"), ",") {
+			if codec, ok := strings.CutPrefix(strings.TrimSpace(kv), "codec="); ok && encoding.GetCodec(codec) != nil {
+				return codec
+			}
+		}
+	}
+	return ""
 }
@@ -2,30 +2,50 @@ package rpc
 
 import (
 	"context"
+	"fmt"
+	"io"
 
 	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 // RouterServiceServerImpl RouterService的gRPC服务器实现
 type RouterServiceServerImpl struct {
 	rpc.UnimplementedRouterServiceServer
-	rpcServer RpcServerDriver
+	rpcServer  RpcServerDriver
+	streamOpts streamOptions
+	transfers  *transferManager
 }
 
 // NewRouterServiceServer 创建RouterService服务器
-func NewRouterServiceServer(rpcServer RpcServerDriver) rpc.RouterServiceServer {
+func NewRouterServiceServer(rpcServer RpcServerDriver, opts ...StreamOption) rpc.RouterServiceServer {
+	o := defaultStreamOptions()
+	for _, fn := range opts {
+		fn(&o)
+	}
 	return &RouterServiceServerImpl{
-		rpcServer: rpcServer,
+		rpcServer:  rpcServer,
+		streamOpts: o,
+		transfers:  newTransferManager(rpcServer, o),
 	}
 }
 
 // JsonMessage 处理JSON消息
-func (s *RouterServiceServerImpl) JsonMessage(ctx context.Context, req *rpc.JsonReq) (*rpc.JsonRsp, error) {
-	log.Infof("Received JSON message from %s, msgId: %s", req.SrcService, req.MsgId)
+func (s *RouterServiceServerImpl) JsonMessage(ctx context.Context, req *rpc.JsonReq) (rsp *rpc.JsonRsp, err error) {
+	log.Debugf("Received JSON message from %s, msgId: %s", req.SrcService, req.MsgId)
+
+	ctx, span := startUnaryServerSpan(ctx, req.MsgId, req.SrcService)
+	defer func() { serverTracer.End(ctx, span, rsp, err) }()
 
+	if rsp, err = s.jsonMessage(ctx, req); err != nil {
+		return nil, toStatusError(err)
+	}
+	return rsp, nil
+}
+
+// jsonMessage JSON 调用的处理逻辑，gRPC 与 HTTP 共用；返回 kratos 错误
+func (s *RouterServiceServerImpl) jsonMessage(ctx context.Context, req *rpc.JsonReq) (*rpc.JsonRsp, error) {
 	// 创建RPC内容
 	content := &Content[string]{
 		CType: RpcContentJson,
@@ -36,7 +56,7 @@ func (s *RouterServiceServerImpl) JsonMessage(ctx context.Context, req *rpc.Json
 	result, err := s.rpcServer.OnCall(ctx, req.SrcService, req.MsgId, content)
 	if err != nil {
 		log.Errorf("RPC call failed: %v", err)
-		return nil, status.Errorf(codes.Internal, "RPC call failed: %v", err)
+		return nil, err
 	}
 
 	// 处理返回结果
@@ -48,12 +68,12 @@ func (s *RouterServiceServerImpl) JsonMessage(ctx context.Context, req *rpc.Json
 	}
 
 	if result.Type() != RpcContentJson {
-		return nil, status.Errorf(codes.Internal, "unexpected response type: %v", result.Type())
+		return nil, errors.New(500, "UNEXPECTED_RESPONSE_TYPE", fmt.Sprintf("unexpected response type: %v", result.Type()))
 	}
 
 	responseData, ok := result.Data().(string)
 	if !ok {
-		return nil, status.Errorf(codes.Internal, "invalid response data type")
+		return nil, errors.New(500, "INVALID_RESPONSE_DATA", "invalid response data type")
 	}
 
 	return &rpc.JsonRsp{
@@ -63,9 +83,20 @@ func (s *RouterServiceServerImpl) JsonMessage(ctx context.Context, req *rpc.Json
 }
 
 // RpcCall 处理RPC调用
-func (s *RouterServiceServerImpl) RpcCall(ctx context.Context, req *rpc.Req) (*rpc.Rsp, error) {
-	log.Infof("Received RPC call from %s, msgId: %s", req.SrcService, req.MsgId)
+func (s *RouterServiceServerImpl) RpcCall(ctx context.Context, req *rpc.Req) (rsp *rpc.Rsp, err error) {
+	log.Debugf("Received RPC call from %s, msgId: %s", req.SrcService, req.MsgId)
+
+	ctx, span := startUnaryServerSpan(ctx, req.MsgId, req.SrcService)
+	defer func() { serverTracer.End(ctx, span, rsp, err) }()
 
+	if rsp, err = s.rpcCall(ctx, req); err != nil {
+		return nil, toStatusError(err)
+	}
+	return rsp, nil
+}
+
+// rpcCall 字节调用的处理逻辑，gRPC 与 HTTP 共用；返回 kratos 错误
+func (s *RouterServiceServerImpl) rpcCall(ctx context.Context, req *rpc.Req) (*rpc.Rsp, error) {
 	// 创建RPC内容
 	content := &Content[[][]byte]{
 		CType: RpcContentBytes,
@@ -76,7 +107,7 @@ func (s *RouterServiceServerImpl) RpcCall(ctx context.Context, req *rpc.Req) (*r
 	result, err := s.rpcServer.OnCall(ctx, req.SrcService, req.MsgId, content)
 	if err != nil {
 		log.Errorf("RPC call failed: %v", err)
-		return nil, status.Errorf(codes.Internal, "RPC call failed: %v", err)
+		return nil, err
 	}
 
 	// 处理返回结果
@@ -88,12 +119,12 @@ func (s *RouterServiceServerImpl) RpcCall(ctx context.Context, req *rpc.Req) (*r
 	}
 
 	if result.Type() != RpcContentBytes {
-		return nil, status.Errorf(codes.Internal, "unexpected response type: %v", result.Type())
+		return nil, errors.New(500, "UNEXPECTED_RESPONSE_TYPE", fmt.Sprintf("unexpected response type: %v", result.Type()))
 	}
 
 	responseData, ok := result.Data().([][]byte)
 	if !ok {
-		return nil, status.Errorf(codes.Internal, "invalid response data type")
+		return nil, errors.New(500, "INVALID_RESPONSE_DATA", "invalid response data type")
 	}
 
 	return &rpc.Rsp{
@@ -103,62 +134,102 @@ func (s *RouterServiceServerImpl) RpcCall(ctx context.Context, req *rpc.Req) (*r
 }
 
 // StreamCall 处理流式调用
+// 请求交给执行器并发处理：同一顺序键按到达顺序执行，其余并行；携带分块的请求交给分块传输管理器；
+// 在途请求达到上限时暂停读取；客户端关闭发送端或流出错后，等待在途请求处理完再返回。
 func (s *RouterServiceServerImpl) StreamCall(stream rpc.RouterService_StreamCallServer) error {
 	log.Info("Stream call started")
 
-	// 处理流式请求
+	ctx := stream.Context()
+	sender := &streamSender{stream: stream}
+	ex := newStreamExecutor(s.streamOpts, func(req *rpc.StreamReq) {
+		if err := sender.send(s.handleStream(ctx, req)); err != nil {
+			log.Errorf("Failed to send stream response: %v", err)
+		}
+	}, func(req *rpc.StreamReq, err error) {
+		rsp := &rpc.StreamRsp{
+			MsgId:      req.MsgId,
+			MsgContent: [][]byte{},
+			ReqId:      req.ReqId,
+			Spnid:      req.GetTraceinfo().GetSpanid(),
+			Error:      toRpcError(err),
+		}
+		if err := sender.send(rsp); err != nil {
+			log.Errorf("Failed to send stream response: %v", err)
+		}
+	})
+
+	var recvErr error
 	for {
 		req, err := stream.Recv()
 		if err != nil {
-			log.Errorf("Stream receive error: %v", err)
-			return err
+			recvErr = err
+			break
 		}
 
-		log.Infof("Received stream request from %s, msgId: %s, reqId: %s",
+		// 分块传输的消息由传输管理器处理，不占用执行器
+		if req.Chunk != nil {
+			s.transfers.onChunk(ctx, sender, req)
+			continue
+		}
+
+		log.Debugf("Received stream request from %s, msgId: %s, reqId: %s",
 			req.SrcService, req.MsgId, req.ReqId)
 
-		// 创建RPC内容
-		content := &Content[[][]byte]{
-			CType: RpcContentBytes,
-			Dt:    req.MsgContent,
+		if !ex.submit(ctx, req) {
+			recvErr = ctx.Err()
+			break
 		}
+	}
 
-		// 调用RPC服务器
-		result, err := s.rpcServer.OnCall(stream.Context(), req.SrcService, req.MsgId, content)
-		if err != nil {
-			log.Errorf("Stream RPC call failed: %v", err)
-			// 发送错误响应
-			errRsp := &rpc.StreamRsp{
-				MsgId:      req.MsgId,
-				MsgContent: [][]byte{},
-				ReqId:      req.ReqId,
-				Spnid:      req.Traceinfo.Spanid,
-			}
-			if sendErr := stream.Send(errRsp); sendErr != nil {
-				log.Errorf("Failed to send error response: %v", sendErr)
-			}
-			continue
-		}
+	// 优雅排空：流关闭前发送完在途请求的响应
+	if !ex.drain(s.streamOpts.drainTimeout) {
+		log.Errorf("Stream drain timeout after %v, pending responses dropped", s.streamOpts.drainTimeout)
+	}
+	sender.close()
+	// 未完成的分块传输等待客户端在新流上续传
+	s.transfers.detach(sender)
 
-		// 处理返回结果
-		var responseData [][]byte
-		if result != nil && result.Type() == RpcContentBytes {
-			if data, ok := result.Data().([][]byte); ok {
-				responseData = data
-			}
-		}
+	if errors.Is(recvErr, io.EOF) {
+		return nil
+	}
+	log.Errorf("Stream receive error: %v", recvErr)
+	return recvErr
+}
 
-		// 发送响应
-		rsp := &rpc.StreamRsp{
-			MsgId:      req.MsgId,
-			MsgContent: responseData,
-			ReqId:      req.ReqId,
-			Spnid:      req.Traceinfo.Spanid,
-		}
+// handleStream 处理单个流式请求；调用失败时错误写入响应的 Error 字段
+func (s *RouterServiceServerImpl) handleStream(ctx context.Context, req *rpc.StreamReq) (rsp *rpc.StreamRsp) {
+	var err error
+	ctx, span := startStreamServerSpan(ctx, req)
+	if span != nil {
+		defer func() { serverTracer.End(ctx, span, rsp, err) }()
+	}
 
-		if err := stream.Send(rsp); err != nil {
-			log.Errorf("Failed to send stream response: %v", err)
-			return err
+	// 创建RPC内容
+	content := &Content[[][]byte]{
+		CType: RpcContentBytes,
+		Dt:    req.MsgContent,
+	}
+
+	rsp = &rpc.StreamRsp{
+		MsgId:      req.MsgId,
+		MsgContent: [][]byte{},
+		ReqId:      req.ReqId,
+		Spnid:      req.GetTraceinfo().GetSpanid(),
+	}
+
+	// 调用RPC服务器
+	result, err := s.rpcServer.OnCall(ctx, req.SrcService, req.MsgId, content)
+	if err != nil {
+		log.Errorf("Stream RPC call failed: %v", err)
+		rsp.Error = toRpcError(err)
+		return rsp
+	}
+
+	// 处理返回结果
+	if result != nil && result.Type() == RpcContentBytes {
+		if data, ok := result.Data().([][]byte); ok {
+			rsp.MsgContent = data
 		}
 	}
+	return rsp
 }
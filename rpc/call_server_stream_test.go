@@ -0,0 +1,223 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// fakeServerStream 服务端视角的内存双向流
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs chan *rpc.StreamReq // 关闭表示客户端关闭发送端
+	rsps chan *rpc.StreamRsp
+}
+
+func newFakeServerStream(ctx context.Context) *fakeServerStream {
+	return &fakeServerStream{ctx: ctx, reqs: make(chan *rpc.StreamReq, 64), rsps: make(chan *rpc.StreamRsp, 64)}
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeServerStream) Recv() (*rpc.StreamReq, error) {
+	select {
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	case req, ok := <-s.reqs:
+		if !ok {
+			return nil, io.EOF
+		}
+		return req, nil
+	}
+}
+
+func (s *fakeServerStream) Send(rsp *rpc.StreamRsp) error {
+	s.rsps <- rsp
+	return nil
+}
+
+// funcServerDriver 以函数处理 OnCall
+type funcServerDriver func(ctx context.Context, msgId string, data [][]byte) ([][]byte, error)
+
+func (f funcServerDriver) OnCall(ctx context.Context, _ string, msgId string, data RpcContent) (RpcContent, error) {
+	out, err := f(ctx, msgId, data.Data().([][]byte))
+	if err != nil {
+		return nil, err
+	}
+	return &Content[[][]byte]{CType: RpcContentBytes, Dt: out}, nil
+}
+
+func (f funcServerDriver) Register(any) error { return nil }
+
+func streamReq(id, msgId, key string) *rpc.StreamReq {
+	return &rpc.StreamReq{ReqId: id, MsgId: msgId, OrderKey: key, MsgContent: [][]byte{[]byte(id)}}
+}
+
+func serveStream(t *testing.T, drv RpcServerDriver, opts ...StreamOption) (*fakeServerStream, <-chan error) {
+	srv := NewRouterServiceServer(drv, opts...)
+	st := newFakeServerStream(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- srv.StreamCall(st) }()
+	t.Cleanup(func() {
+		defer func() { _ = recover() }()
+		close(st.reqs)
+	})
+	return st, errc
+}
+
+func TestStreamCall_SlowRequestDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	drv := funcServerDriver(func(_ context.Context, msgId string, data [][]byte) ([][]byte, error) {
+		if msgId == "slow" {
+			<-release
+		}
+		return data, nil
+	})
+	st, _ := serveStream(t, drv)
+
+	st.reqs <- streamReq("1", "slow", "")
+	st.reqs <- streamReq("2", "fast", "")
+	rsp := <-st.rsps
+	assert.Equal(t, "2", rsp.ReqId)
+	close(release)
+	rsp = <-st.rsps
+	assert.Equal(t, "1", rsp.ReqId)
+}
+
+func TestStreamCall_OrderedPerKey(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string][]string{}
+	drv := funcServerDriver(func(_ context.Context, _ string, data [][]byte) ([][]byte, error) {
+		id := string(data[0])
+		time.Sleep(time.Duration(len(id)%3) * time.Millisecond)
+		mu.Lock()
+		key := id[:1]
+		seen[key] = append(seen[key], id)
+		mu.Unlock()
+		return data, nil
+	})
+	st, errc := serveStream(t, drv, WithStreamWorkers(4))
+
+	const n = 20
+	for i := 0; i < n; i++ {
+		for _, key := range []string{"a", "b"} {
+			st.reqs <- streamReq(fmt.Sprintf("%s%02d", key, i), "m", key)
+		}
+	}
+	close(st.reqs)
+	require.NoError(t, <-errc)
+	assert.Len(t, st.rsps, 2*n)
+
+	for _, key := range []string{"a", "b"} {
+		require.Len(t, seen[key], n)
+		for i, id := range seen[key] {
+			assert.Equal(t, fmt.Sprintf("%s%02d", key, i), id)
+		}
+	}
+}
+
+func TestStreamCall_MaxInFlight(t *testing.T) {
+	var cur, peak atomic.Int32
+	release := make(chan struct{})
+	drv := funcServerDriver(func(context.Context, string, [][]byte) ([][]byte, error) {
+		if v := cur.Add(1); v > peak.Load() {
+			peak.Store(v)
+		}
+		<-release
+		cur.Add(-1)
+		return nil, nil
+	})
+	st, errc := serveStream(t, drv, WithStreamWorkers(8), WithStreamMaxInFlight(2))
+
+	for i := 0; i < 5; i++ {
+		st.reqs <- streamReq(fmt.Sprint(i), "m", "")
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.EqualValues(t, 2, cur.Load())
+	close(release)
+	close(st.reqs)
+	require.NoError(t, <-errc)
+	assert.EqualValues(t, 2, peak.Load())
+	assert.Len(t, st.rsps, 5)
+}
+
+func TestStreamCall_DrainOnClose(t *testing.T) {
+	release := make(chan struct{})
+	drv := funcServerDriver(func(_ context.Context, _ string, data [][]byte) ([][]byte, error) {
+		<-release
+		return data, nil
+	})
+	st, errc := serveStream(t, drv)
+
+	st.reqs <- streamReq("1", "m", "k")
+	st.reqs <- streamReq("2", "m", "")
+	close(st.reqs)
+
+	select {
+	case <-errc:
+		t.Fatal("StreamCall returned before in-flight requests finished")
+	case <-time.After(30 * time.Millisecond):
+	}
+	close(release)
+	require.NoError(t, <-errc)
+	assert.Len(t, st.rsps, 2)
+}
+
+func TestStreamCall_DrainTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	drv := funcServerDriver(func(context.Context, string, [][]byte) ([][]byte, error) {
+		<-release
+		return nil, nil
+	})
+	st, errc := serveStream(t, drv, WithStreamDrainTimeout(20*time.Millisecond))
+
+	st.reqs <- streamReq("1", "m", "")
+	close(st.reqs)
+	require.NoError(t, <-errc)
+	assert.Empty(t, st.rsps)
+}
+
+func TestStreamCall_HandlerPanic(t *testing.T) {
+	drv := funcServerDriver(func(_ context.Context, msgId string, data [][]byte) ([][]byte, error) {
+		if msgId == "boom" {
+			panic("boom")
+		}
+		return data, nil
+	})
+	st, errc := serveStream(t, drv, WithStreamWorkers(1))
+
+	// 同键的后续请求与唯一的 worker 不受 panic 影响
+	st.reqs <- streamReq("1", "boom", "k")
+	st.reqs <- streamReq("2", "ok", "k")
+	st.reqs <- streamReq("3", "boom", "")
+	st.reqs <- streamReq("4", "ok", "")
+	close(st.reqs)
+	require.NoError(t, <-errc)
+	require.Len(t, st.rsps, 4)
+
+	got := map[string]*rpc.StreamRsp{}
+	for i := 0; i < 4; i++ {
+		rsp := <-st.rsps
+		got[rsp.ReqId] = rsp
+	}
+	for _, id := range []string{"1", "3"} {
+		require.NotNil(t, got[id].GetError(), id)
+		assert.EqualValues(t, 500, got[id].GetError().GetCode())
+		assert.Equal(t, "STREAM_HANDLER_PANIC", got[id].GetError().GetReason())
+	}
+	for _, id := range []string{"2", "4"} {
+		assert.Nil(t, got[id].GetError(), id)
+		assert.Equal(t, [][]byte{[]byte(id)}, got[id].MsgContent)
+	}
+}
@@ -0,0 +1,318 @@
+package rpc
+
+import (
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+const (
+	// defaultChunkSize 单个分块的最大字节数
+	defaultChunkSize = 64 << 10
+	// defaultTransferWindow 发送方已发送未确认的最大字节数（背压窗口）
+	defaultTransferWindow = 1 << 20
+)
+
+// errTransferDone 传输正常结束，用于停止分块发送
+var errTransferDone = errors.New(499, "TRANSFER_DONE", "transfer done")
+
+// chunkSender 分块发送方：从 src 读取并按窗口发送
+// 已发送未确认的数据保留在内存中（不超过窗口），流断开后可从接收方报告的偏移重发。
+type chunkSender struct {
+	src       io.Reader
+	chunkSize int
+	window    int64
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	send    func(*rpc.Chunk) error // 当前流上的发送函数，流断开时为 nil
+	gen     int                    // send 的绑定代数，用于识别过期的发送失败
+	buf     []byte                 // [acked, read) 区间的数据
+	acked   int64                  // 接收方已确认（消费）的偏移
+	read    int64                  // 已从 src 读取的偏移
+	next    int64                  // 下一个待发送的偏移
+	eof     bool                   // src 已读完
+	eofSent bool
+	crc     hash.Hash32
+	err     error // 终止原因
+}
+
+func newChunkSender(src io.Reader, chunkSize int, window int64) *chunkSender {
+	s := &chunkSender{
+		src:       src,
+		chunkSize: chunkSize,
+		window:    window,
+		crc:       crc32.NewIEEE(),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// bind 绑定发送函数并从 offset 开始（重新）发送；offset 必须落在未确认的区间内
+func (s *chunkSender) bind(send func(*rpc.Chunk) error, offset int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if offset < s.acked || offset > s.read {
+		return errors.New(409, "INVALID_RESUME_OFFSET", fmt.Sprintf("resume offset %d out of range [%d, %d]", offset, s.acked, s.read))
+	}
+	s.send = send
+	s.gen++
+	s.next = offset
+	s.eofSent = false
+	s.cond.Broadcast()
+	return nil
+}
+
+// unbind 流断开，暂停发送直到重新 bind
+func (s *chunkSender) unbind() {
+	s.mu.Lock()
+	s.send = nil
+	s.gen++
+	s.mu.Unlock()
+}
+
+// ack 接收方确认消费到 offset，释放窗口
+func (s *chunkSender) ack(offset int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if offset <= s.acked || offset > s.read {
+		return
+	}
+	s.buf = s.buf[offset-s.acked:]
+	s.acked = offset
+	// 续传重发期间接收方可能确认到更靠后的位置，已确认的数据无需再发
+	if s.next < offset {
+		s.next = offset
+	}
+	s.cond.Broadcast()
+}
+
+// finished 全部数据已发送并被接收方确认
+func (s *chunkSender) finished() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.eof && s.eofSent && s.acked == s.read
+}
+
+// close 终止发送
+func (s *chunkSender) close(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// ready 是否有可发送的分块或需要读取新数据
+func (s *chunkSender) ready() bool {
+	if s.send == nil {
+		return false
+	}
+	if s.next < s.read {
+		return true
+	}
+	if s.eof {
+		return !s.eofSent
+	}
+	return s.read-s.acked < s.window
+}
+
+// run 发送循环，直到 close；读取 src 失败时返回该错误
+func (s *chunkSender) run() error {
+	rbuf := make([]byte, s.chunkSize)
+	for {
+		s.mu.Lock()
+		for s.err == nil && !s.ready() {
+			s.cond.Wait()
+		}
+		if s.err != nil {
+			err := s.err
+			s.mu.Unlock()
+			return err
+		}
+		send, gen := s.send, s.gen
+		var c *rpc.Chunk
+		switch {
+		case s.next < s.read:
+			n := min(int64(s.chunkSize), s.read-s.next)
+			start := s.next - s.acked
+			c = &rpc.Chunk{Offset: s.next, Data: append([]byte(nil), s.buf[start:start+n]...)}
+			s.next += n
+		case s.eof:
+			c = &rpc.Chunk{Offset: s.read, Eof: true, Crc32: s.crc.Sum32()}
+			s.eofSent = true
+		default:
+			// 读取新数据时不持锁，期间仍可处理确认与续传；读取量不超过剩余窗口
+			room := min(int64(len(rbuf)), s.window-(s.read-s.acked))
+			s.mu.Unlock()
+			n, err := s.src.Read(rbuf[:room])
+			s.mu.Lock()
+			if n > 0 {
+				s.buf = append(s.buf, rbuf[:n]...)
+				s.crc.Write(rbuf[:n])
+				s.read += int64(n)
+			}
+			if err == io.EOF {
+				s.eof = true
+			} else if err != nil && s.err == nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+			continue
+		}
+		s.mu.Unlock()
+
+		if err := send(c); err != nil {
+			// 流已断开：暂停直到续传时重新绑定
+			s.mu.Lock()
+			if s.gen == gen {
+				s.send = nil
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// chunkReceiver 分块接收方：按偏移拼接分块供消费方读取，消费后确认以释放发送方窗口
+type chunkReceiver struct {
+	window int64
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	bufs     [][]byte
+	received int64 // 已收到的偏移，续传时报告给发送方
+	consumed int64
+	acked    int64
+	crc      hash.Hash32
+	eof      bool
+	err      error              // 终止原因，缓冲的数据读完后返回
+	ack      func(offset int64) // 确认函数
+	onClose  func()             // 消费方提前关闭时回调
+}
+
+func newChunkReceiver(window int64, ack func(offset int64)) *chunkReceiver {
+	r := &chunkReceiver{window: window, crc: crc32.NewIEEE(), ack: ack}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+// resumePoint 续传时报告的已收到偏移与已消费偏移；断开期间发出的确认可能丢失，随续传重新确认
+func (r *chunkReceiver) resumePoint() (received, consumed int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.acked = r.consumed
+	return r.received, r.consumed
+}
+
+// push 追加分块；续传导致的重复数据被跳过，超出窗口或偏移不连续返回错误
+func (r *chunkReceiver) push(c *rpc.Chunk) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.eof || r.err != nil {
+		return nil
+	}
+	data := c.Data
+	if c.Offset > r.received {
+		return errors.New(400, "CHUNK_OUT_OF_ORDER", fmt.Sprintf("chunk offset %d, expect %d", c.Offset, r.received))
+	}
+	if skip := r.received - c.Offset; skip > 0 {
+		if skip >= int64(len(data)) {
+			data = nil
+		} else {
+			data = data[skip:]
+		}
+		if c.Eof && c.Offset+int64(len(c.Data)) != r.received {
+			return errors.New(400, "CHUNK_OUT_OF_ORDER", fmt.Sprintf("eof at %d, received %d", c.Offset+int64(len(c.Data)), r.received))
+		}
+	}
+	if r.received+int64(len(data))-r.consumed > r.window {
+		return errors.New(429, "TRANSFER_WINDOW_EXCEEDED", fmt.Sprintf("buffered data exceeds window %d", r.window))
+	}
+	if len(data) > 0 {
+		r.bufs = append(r.bufs, data)
+		r.crc.Write(data)
+		r.received += int64(len(data))
+	}
+	if c.Eof {
+		if sum := r.crc.Sum32(); sum != c.Crc32 {
+			r.err = errors.New(422, "CHECKSUM_MISMATCH", fmt.Sprintf("crc32 %08x, expect %08x", sum, c.Crc32))
+		} else {
+			r.eof = true
+		}
+	}
+	r.cond.Broadcast()
+	return nil
+}
+
+// fail 终止接收，消费方读完已缓冲的数据后得到 err
+func (r *chunkReceiver) fail(err error) {
+	r.mu.Lock()
+	if !r.eof && r.err == nil {
+		r.err = err
+	}
+	r.cond.Broadcast()
+	r.mu.Unlock()
+}
+
+// Read 实现 io.Reader；每消费满 1/4 窗口或读空缓冲时确认（发送方窗口可能小于接收窗口）
+func (r *chunkReceiver) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	for len(r.bufs) == 0 && !r.eof && r.err == nil {
+		r.cond.Wait()
+	}
+	if len(r.bufs) == 0 {
+		err := r.err
+		if err == nil {
+			err = io.EOF
+		}
+		// 结尾在缓冲读空后到达：补发最终确认
+		var ack func(int64)
+		offset := r.consumed
+		if r.ack != nil && r.eof && offset > r.acked {
+			r.acked = offset
+			ack = r.ack
+		}
+		r.mu.Unlock()
+		if ack != nil {
+			ack(offset)
+		}
+		return 0, err
+	}
+	n := copy(p, r.bufs[0])
+	if n == len(r.bufs[0]) {
+		r.bufs = r.bufs[1:]
+	} else {
+		r.bufs[0] = r.bufs[0][n:]
+	}
+	r.consumed += int64(n)
+	var ack func(int64)
+	offset := r.consumed
+	if r.ack != nil && (offset-r.acked >= r.window/4 || len(r.bufs) == 0) {
+		r.acked = offset
+		ack = r.ack
+	}
+	r.mu.Unlock()
+	if ack != nil {
+		ack(offset)
+	}
+	return n, nil
+}
+
+// Close 消费方不再读取，终止传输
+func (r *chunkReceiver) Close() error {
+	r.fail(errors.New(499, "TRANSFER_CLOSED", "reader closed"))
+	r.mu.Lock()
+	onClose := r.onClose
+	r.onClose = nil
+	r.mu.Unlock()
+	if onClose != nil {
+		onClose()
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package rpc
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPayload(n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(int64(n))).Read(b)
+	return b
+}
+
+// loopback 将发送方的分块直接交给接收方，确认回传给发送方
+func loopback(t *testing.T, s *chunkSender, window int64) *chunkReceiver {
+	r := newChunkReceiver(window, s.ack)
+	require.NoError(t, s.bind(func(c *rpc.Chunk) error {
+		require.NoError(t, r.push(c))
+		return nil
+	}, 0))
+	go func() {
+		if err := s.run(); err != errTransferDone {
+			r.fail(err)
+		}
+	}()
+	return r
+}
+
+func TestChunkTransfer(t *testing.T) {
+	payload := randomPayload(300 << 10)
+	// 窗口小于负载，接收方缓冲超出窗口时 push 报错
+	s := newChunkSender(bytes.NewReader(payload), 7<<10, 32<<10)
+	r := loopback(t, s, 32<<10)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, payload, got)
+	assert.True(t, s.finished())
+	s.close(errTransferDone)
+
+	// 空负载
+	s = newChunkSender(bytes.NewReader(nil), 1024, 4096)
+	got, err = io.ReadAll(loopback(t, s, 4096))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	s.close(errTransferDone)
+}
+
+func TestChunkTransfer_Resume(t *testing.T) {
+	payload := randomPayload(100 << 10)
+	s := newChunkSender(bytes.NewReader(payload), 4<<10, 64<<10)
+	r := newChunkReceiver(64<<10, s.ack)
+
+	// 第一条“流”在转发 5 个分块后断开
+	sent := 0
+	require.NoError(t, s.bind(func(c *rpc.Chunk) error {
+		if sent == 5 {
+			return io.ErrClosedPipe
+		}
+		sent++
+		return r.push(c)
+	}, 0))
+	go func() { _ = s.run() }()
+	defer s.close(errTransferDone)
+
+	require.Eventually(t, func() bool { received, _ := r.resumePoint(); return received == 20<<10 }, time.Second, time.Millisecond)
+	// 消费不足 1/4 窗口且缓冲未读空，不会确认
+	buf := make([]byte, 12<<10)
+	_, err := io.ReadFull(r, buf)
+	require.NoError(t, err)
+
+	// 续传：从早于已收到偏移的位置重发，重复数据被跳过
+	require.NoError(t, s.bind(r.push, 8<<10))
+	rest, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, payload, append(buf, rest...))
+
+	// 已确认的数据不能重发
+	assert.Equal(t, "INVALID_RESUME_OFFSET", errors.Reason(s.bind(r.push, 0)))
+}
+
+func TestChunkReceiver_Errors(t *testing.T) {
+	r := newChunkReceiver(8, nil)
+	assert.Equal(t, "CHUNK_OUT_OF_ORDER", errors.Reason(r.push(&rpc.Chunk{Offset: 3, Data: []byte("x")})))
+	assert.Equal(t, "TRANSFER_WINDOW_EXCEEDED", errors.Reason(r.push(&rpc.Chunk{Data: []byte("123456789")})))
+
+	require.NoError(t, r.push(&rpc.Chunk{Data: []byte("abc")}))
+	require.NoError(t, r.push(&rpc.Chunk{Offset: 3, Eof: true, Crc32: 1}))
+	got, err := io.ReadAll(r)
+	assert.Equal(t, "abc", string(got))
+	assert.Equal(t, "CHECKSUM_MISMATCH", errors.Reason(err))
+}
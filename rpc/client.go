@@ -18,12 +18,21 @@ import (
 type rpcClient struct {
 	conn       *grpc.ClientConn
 	client     rpc.RouterServiceClient
-	stream     rpc.RouterService_StreamCallClient
 	stateful   bool
 	mu         sync.Mutex
 	srcService string
 	reqIdGen   func() string
 	closed     bool
+
+	// 有状态服务的多路复用流，断开后在下次调用时重建
+	streamMu       sync.Mutex
+	sc             *streamConn
+	streamClosed   bool
+	streamFailures int
+	retryAt        time.Time
+
+	// 进行中的分块传输（reqId -> *clientTransfer），跨流重建保留
+	transfers sync.Map
 }
 
 // NewRpcProxy 创建新的RPC代理
@@ -60,12 +69,10 @@ func NewRpcProxy(ctx context.Context, targetService string, srcServiceName strin
 
 	// 如果是有状态服务，建立stream
 	if stateful {
-		stream, err := client.StreamCall(ctx)
-		if err != nil {
+		if _, err := rc.acquireStream(ctx); err != nil {
 			conn.Close()
 			return nil, err
 		}
-		rc.stream = stream
 	}
 
 	return rc, nil
@@ -82,20 +89,24 @@ func NewRpcProxyDirect(ctx context.Context, targetAddress string, serviceName st
 }
 
 // Call 执行RPC调用
+// 调用之间不互斥：有状态调用共享同一条流并发进行，响应按 ReqId 分发
 func (c *rpcClient) Call(ctx context.Context, msgId string, msgContent RpcContent) (RpcContent, error) {
-	if c.closed {
+	if c.IsClosed() {
 		return nil, errors.New(500, "PROXY_CLOSED", "RPC proxy is closed")
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	contentType := msgContent.Type()
 	switch contentType {
 	case RpcContentBytes:
 		return c.callBytes(ctx, msgId, msgContent)
 	case RpcContentJson:
 		return c.callJson(ctx, msgId, msgContent)
+	case RpcContentFile:
+		data, ok := msgContent.Data().(*FileData)
+		if !ok {
+			return nil, errors.New(400, "INVALID_DATA_TYPE", "expect *FileData for file request")
+		}
+		return c.callFile(ctx, msgId, data)
 	default:
 		return nil, errors.New(400, "UNSUPPORTED_CONTENT_TYPE", "unsupported content type")
 	}
@@ -122,16 +133,20 @@ func (c *rpcClient) callJson(ctx context.Context, msgId string, msgContent RpcCo
 		return nil, errors.New(400, "INVALID_DATA_TYPE", "expect string for json request")
 	}
 
+	ctx, span := startUnaryClientSpan(ctx, msgId, c.srcService)
 	rsp, err := c.client.JsonMessage(ctx, &rpc.JsonReq{
 		SrcService: c.srcService,
 		MsgId:      msgId,
 		MsgContent: data,
 	})
 	if err != nil {
+		err = fromCallError(err)
+		clientTracer.End(ctx, span, nil, err)
 		return nil, err
 	}
+	clientTracer.End(ctx, span, rsp, nil)
 
-	log.Infof("JSON response: %s", rsp.MsgContent)
+	log.Debugf("JSON response: %s", rsp.MsgContent)
 
 	// 返回JSON格式的响应
 	return &Content[string]{
@@ -141,54 +156,86 @@ func (c *rpcClient) callJson(ctx context.Context, msgId string, msgContent RpcCo
 }
 
 // callStream 处理流式调用
-func (c *rpcClient) callStream(ctx context.Context, msgId string, data [][]byte) (RpcContent, error) {
+// 请求发送失败（流已断开，服务端未收到）时在新流上重试一次；
+// 已发送的请求在流断开时直接失败，不重发以免重复执行。
+func (c *rpcClient) callStream(ctx context.Context, msgId string, data [][]byte) (content RpcContent, err error) {
 	reqId := c.reqIdGen()
-	trace := &rpc.TraceInfo{
-		Traceid: time.Now().UnixNano(),
-		Spanid:  1,
-		Sample:  true,
-	}
-
-	err := c.stream.Send(&rpc.StreamReq{
+	req := &rpc.StreamReq{
 		SrcService: c.srcService,
 		MsgId:      msgId,
 		MsgContent: data,
 		ReqId:      reqId,
-		Traceinfo:  trace,
-	})
-	if err != nil {
-		return nil, err
+		OrderKey:   orderKeyFrom(ctx),
 	}
-
-	// 等待响应
-	for {
-		rsp, err := c.stream.Recv()
-		if err != nil {
+	ctx, span := startStreamClientSpan(ctx, req)
+	defer func() { clientTracer.End(ctx, span, nil, err) }()
+
+	var (
+		sc *streamConn
+		ch chan *rpc.StreamRsp
+	)
+	for attempt := 0; ; attempt++ {
+		var err error
+		if sc, err = c.acquireStream(ctx); err != nil {
 			return nil, err
 		}
-		if rsp.ReqId == reqId {
-			// 返回字节格式的响应
-			return &Content[[][]byte]{
-				CType: RpcContentBytes,
-				Dt:    rsp.MsgContent,
-			}, nil
+		ch = sc.register(reqId)
+		if err = sc.send(req); err == nil {
+			break
+		}
+		sc.take(reqId)
+		sc.fail(err)
+		c.dropStream(sc)
+		if attempt > 0 {
+			return nil, streamBroken(err)
 		}
-		// 继续等待匹配的响应
 	}
+
+	// 等待响应
+	select {
+	case rsp := <-ch:
+		return streamContent(rsp)
+	case <-sc.done:
+		// 响应与断开同时到达时优先返回响应
+		select {
+		case rsp := <-ch:
+			return streamContent(rsp)
+		default:
+		}
+		return nil, streamBroken(sc.err)
+	case <-ctx.Done():
+		sc.take(reqId)
+		return nil, ctx.Err()
+	}
+}
+
+// streamContent 返回字节格式的响应；服务端返回错误时还原为 kratos 错误
+func streamContent(rsp *rpc.StreamRsp) (RpcContent, error) {
+	if rsp.Error != nil {
+		return nil, fromRpcError(rsp.Error)
+	}
+	return &Content[[][]byte]{
+		CType: RpcContentBytes,
+		Dt:    rsp.MsgContent,
+	}, nil
 }
 
 // callUnary 处理一元调用
 func (c *rpcClient) callUnary(ctx context.Context, msgId string, data [][]byte) (RpcContent, error) {
+	ctx, span := startUnaryClientSpan(ctx, msgId, c.srcService)
 	rsp, err := c.client.RpcCall(ctx, &rpc.Req{
 		SrcService: c.srcService,
 		MsgId:      msgId,
 		MsgContent: data,
 	})
 	if err != nil {
+		err = fromCallError(err)
+		clientTracer.End(ctx, span, nil, err)
 		return nil, err
 	}
+	clientTracer.End(ctx, span, rsp, nil)
 
-	log.Infof("Unary response: %v", rsp.MsgContent)
+	log.Debugf("Unary response: %v", rsp.MsgContent)
 
 	// 返回字节格式的响应
 	return &Content[[][]byte]{
@@ -207,12 +254,7 @@ func (c *rpcClient) Close() error {
 	}
 
 	c.closed = true
-
-	if c.stream != nil {
-		if err := c.stream.CloseSend(); err != nil {
-			log.Errorf("Failed to close stream: %v", err)
-		}
-	}
+	c.closeStream()
 
 	if c.conn != nil {
 		return c.conn.Close()
@@ -0,0 +1,160 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/registry"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// rpcHttpClient 基于 HTTP 传输的RPC代理客户端
+// 调用 RouterService 的 HTTP 路由，与 gRPC 客户端使用相同的 msgId 分发；仅支持一元调用（字节与 JSON 内容）。
+type rpcHttpClient struct {
+	conn       *khttp.Client
+	client     rpc.RouterServiceHTTPClient
+	srcService string
+	mu         sync.Mutex
+	closed     bool
+}
+
+// NewRpcHttpProxy 创建基于 HTTP 传输的RPC代理
+// 提供服务发现时经 discovery:///targetService 解析，否则 targetService 为目标地址（如 http://127.0.0.1:8000）。
+func NewRpcHttpProxy(ctx context.Context, targetService string, srcServiceName string, r registry.Discovery, opts ...khttp.ClientOption) (*rpcHttpClient, error) {
+	clientOpts := []khttp.ClientOption{khttp.WithErrorDecoder(decodeHttpError)}
+	if r != nil {
+		clientOpts = append(clientOpts,
+			khttp.WithEndpoint("discovery:///"+targetService),
+			khttp.WithDiscovery(r),
+			khttp.WithTimeout(360*time.Second),
+		)
+	} else {
+		clientOpts = append(clientOpts, khttp.WithEndpoint(targetService))
+	}
+
+	conn, err := khttp.NewClient(ctx, append(clientOpts, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcHttpClient{
+		conn:       conn,
+		client:     rpc.NewRouterServiceHTTPClient(conn),
+		srcService: srcServiceName,
+	}, nil
+}
+
+// decodeHttpError 还原 HTTP 调用返回的 kratos 错误；响应头携带原始错误码时以其为准
+func decodeHttpError(ctx context.Context, res *http.Response) error {
+	err := khttp.DefaultErrorDecoder(ctx, res)
+	if err == nil {
+		return nil
+	}
+	if code, perr := strconv.Atoi(res.Header.Get(rpcCodeHeader)); perr == nil {
+		if se := new(errors.Error); errors.As(err, &se) {
+			se.Code = int32(code)
+		}
+	}
+	return err
+}
+
+// Call 执行RPC调用
+func (c *rpcHttpClient) Call(ctx context.Context, msgId string, msgContent RpcContent) (RpcContent, error) {
+	if c.IsClosed() {
+		return nil, errors.New(500, "PROXY_CLOSED", "RPC proxy is closed")
+	}
+
+	switch msgContent.Type() {
+	case RpcContentBytes:
+		return c.callBytes(ctx, msgId, msgContent)
+	case RpcContentJson:
+		return c.callJson(ctx, msgId, msgContent)
+	default:
+		return nil, errors.New(400, "UNSUPPORTED_CONTENT_TYPE", "unsupported content type for http transport")
+	}
+}
+
+// callBytes 处理字节类型的调用
+func (c *rpcHttpClient) callBytes(ctx context.Context, msgId string, msgContent RpcContent) (RpcContent, error) {
+	data, ok := msgContent.Data().([][]byte)
+	if !ok {
+		return nil, errors.New(400, "INVALID_DATA_TYPE", "expect [][]byte for bytes request")
+	}
+
+	header := http.Header{}
+	ctx, span := startHttpClientSpan(ctx, msgId, c.srcService, header)
+	rsp, err := c.client.RpcCall(ctx, &rpc.Req{
+		SrcService: c.srcService,
+		MsgId:      msgId,
+		MsgContent: data,
+	}, khttp.Header(&header))
+	if err != nil {
+		clientTracer.End(ctx, span, nil, err)
+		return nil, err
+	}
+	clientTracer.End(ctx, span, rsp, nil)
+
+	log.Debugf("HTTP response: %v", rsp.MsgContent)
+
+	return &Content[[][]byte]{
+		CType: RpcContentBytes,
+		Dt:    rsp.MsgContent,
+	}, nil
+}
+
+// callJson 处理JSON类型的调用
+func (c *rpcHttpClient) callJson(ctx context.Context, msgId string, msgContent RpcContent) (RpcContent, error) {
+	data, ok := msgContent.Data().(string)
+	if !ok {
+		return nil, errors.New(400, "INVALID_DATA_TYPE", "expect string for json request")
+	}
+
+	header := http.Header{}
+	ctx, span := startHttpClientSpan(ctx, msgId, c.srcService, header)
+	rsp, err := c.client.JsonMessage(ctx, &rpc.JsonReq{
+		SrcService: c.srcService,
+		MsgId:      msgId,
+		MsgContent: data,
+	}, khttp.Header(&header))
+	if err != nil {
+		clientTracer.End(ctx, span, nil, err)
+		return nil, err
+	}
+	clientTracer.End(ctx, span, rsp, nil)
+
+	log.Debugf("HTTP JSON response: %s", rsp.MsgContent)
+
+	return &Content[string]{
+		CType: RpcContentJson,
+		Dt:    rsp.MsgContent,
+	}, nil
+}
+
+// Close 关闭连接
+func (c *rpcHttpClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.conn.Close()
+}
+
+// IsClosed 检查是否已关闭
+func (c *rpcHttpClient) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// GetService 获取服务名
+func (c *rpcHttpClient) GetService() string {
+	return c.srcService
+}
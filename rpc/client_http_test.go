@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// dialHttp 启动 HTTP 模式的 RouterService 并创建 HTTP 代理
+func dialHttp(t *testing.T, drv RpcServerDriver) *rpcHttpClient {
+	srv := khttp.NewServer()
+	require.NoError(t, RegisterRouterService(srv, drv))
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	c, err := NewRpcHttpProxy(context.Background(), ts.URL, "src", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestHttpTransport(t *testing.T) {
+	srv := NewRpcServer(WithHttpTransport())
+	require.NoError(t, srv.Register(calculatorImpl{}))
+	c := dialHttp(t, srv)
+	p, err := NewServiceProxy[Calculator](c)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var sum int
+	require.NoError(t, p.Invoke(ctx, "Add", []any{2, 3}, &sum))
+	assert.Equal(t, 5, sum)
+
+	var desc *JsonStructResult
+	require.NoError(t, p.Invoke(ctx, "Describe", []any{JsonStructParam{Name: "amy", Age: 7}}, &desc))
+	assert.Equal(t, "amy/7", desc.Summary)
+
+	err = p.Invoke(ctx, "Fail", []any{"CONFLICT"})
+	assert.Equal(t, "CONFLICT", errors.Reason(err))
+	assert.Equal(t, 409, errors.Code(err))
+
+	_, err = c.Call(ctx, "Missing", &Content[[][]byte]{CType: RpcContentBytes, Dt: [][]byte{}})
+	assert.Equal(t, "SERVICE_NOT_FOUND", errors.Reason(err))
+	assert.Equal(t, 404, errors.Code(err))
+
+	_, err = c.Call(ctx, "Add", &Content[*FileData]{CType: RpcContentFile, Dt: &FileData{}})
+	assert.Equal(t, "UNSUPPORTED_CONTENT_TYPE", errors.Reason(err))
+}
+
+func TestHttpTransport_ErrorCode(t *testing.T) {
+	// 1001 不是合法的 HTTP 状态码：以 500 响应，客户端从响应头还原
+	want := errors.New(1001, "ENTITY_LOCKED", "entity is locked").WithMetadata(map[string]string{"id": "u1"})
+	for _, content := range []RpcContent{
+		&Content[string]{CType: RpcContentJson, Dt: "{}"},
+		&Content[[][]byte]{CType: RpcContentBytes, Dt: [][]byte{}},
+	} {
+		_, err := dialHttp(t, errServerDriver{err: want}).Call(context.Background(), "m", content)
+		require.Error(t, err)
+		se := errors.FromError(err)
+		assert.EqualValues(t, 1001, se.Code)
+		assert.Equal(t, "ENTITY_LOCKED", se.Reason)
+		assert.Equal(t, "entity is locked", se.Message)
+		assert.Equal(t, map[string]string{"id": "u1"}, se.Metadata)
+	}
+}
+
+func TestRegisterRouterService(t *testing.T) {
+	assert.Equal(t, "RPC_TYPE_MISMATCH", errors.Reason(RegisterRouterService(khttp.NewServer(), NewRpcServer())))
+	assert.Equal(t, "RPC_TYPE_MISMATCH", errors.Reason(RegisterRouterService(grpc.NewServer(), NewRpcServer(WithHttpTransport()))))
+	assert.Equal(t, "UNSUPPORTED_TRANSPORT", errors.Reason(RegisterRouterService(struct{}{}, NewRpcServer())))
+
+	// 未声明类型的驱动按传输服务器注册
+	assert.NoError(t, RegisterRouterService(grpc.NewServer(), errServerDriver{}))
+	assert.NoError(t, RegisterRouterService(khttp.NewServer(), errServerDriver{}))
+}
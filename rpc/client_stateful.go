@@ -0,0 +1,273 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	facade "github.com/go-kratos/kratos/v2/entity/facade"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/route"
+	grpc "google.golang.org/grpc"
+)
+
+const (
+	// ReasonAlreadyInOtherPod 有状态调用被拒绝：路由键已链接到其他 Pod，未执行（与 entity 层一致）
+	ReasonAlreadyInOtherPod = facade.ReasonAlreadyInOtherPod
+	// defaultMaxReroutes 单次调用的最大重新路由次数
+	defaultMaxReroutes = 2
+)
+
+// ErrAlreadyInOtherPod 服务端拒绝有状态调用时返回的错误；pod 为路由键当前链接的 Pod（未知时为 0）
+// 有状态客户端收到后刷新路由缓存并重新路由到该 Pod。
+func ErrAlreadyInOtherPod(pod int) *errors.Error {
+	return facade.AlreadyInOtherPod(pod)
+}
+
+type routeKeyCtx struct{}
+
+// WithRouteKey 为有状态调用设置路由键（如 uid）：调用路由到该键链接的 Pod
+func WithRouteKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, routeKeyCtx{}, key)
+}
+
+// routeKeyFrom 读取调用上下文中的路由键
+func routeKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(routeKeyCtx{}).(string)
+	return key
+}
+
+// StatefulOption 有状态客户端选项
+type StatefulOption func(*statefulOptions)
+
+type statefulOptions struct {
+	namespace   string
+	dialOpts    []grpc.DialOption
+	maxReroutes int
+}
+
+// WithRouteNamespace 路由所在的命名空间，默认为空
+func WithRouteNamespace(namespace string) StatefulOption {
+	return func(o *statefulOptions) { o.namespace = namespace }
+}
+
+// WithPodDialOptions 连接各 Pod 时使用的 gRPC 选项
+func WithPodDialOptions(opts ...grpc.DialOption) StatefulOption {
+	return func(o *statefulOptions) { o.dialOpts = append(o.dialOpts, opts...) }
+}
+
+// WithMaxReroutes 单次调用因路由变化重新路由的最大次数，默认 2
+func WithMaxReroutes(n int) StatefulOption {
+	return func(o *statefulOptions) { o.maxReroutes = n }
+}
+
+// statefulRpcClient 按路由键路由到 Pod 的有状态RPC代理客户端
+// 每次调用按路由键经 ComputeLinkedPod 解析 Pod，每个 Pod 维护一个多路复用流的 rpcClient；
+// Pod 拒绝（ALREADY_IN_OTHER_POD）或无法建流时刷新路由缓存并重新路由，Pod 不可路由时释放其连接。
+type statefulRpcClient struct {
+	service    string
+	srcService string
+	router     route.StatefulRouteForClientDriver
+	podTarget  func(podIndex int) string
+	opts       statefulOptions
+	// maxReroutes 单次调用的最大重新路由次数，可运行时调整
+	maxReroutes atomic.Int32
+
+	mu     sync.Mutex
+	pods   map[int]*podConn
+	closed bool
+}
+
+// podConn 一个 Pod 的连接；退役后在进行中的调用结束时关闭
+type podConn struct {
+	client  *rpcClient
+	refs    int
+	retired bool
+}
+
+// NewStatefulRpcProxy 创建按路由键路由的有状态RPC代理
+// podTarget 返回 Pod 的拨号地址；调用需经 WithRouteKey 设置路由键，未设置顺序键时以路由键作为顺序键。
+// 客户端实现 route.StateChanged，可通过 RouteInfoDriver.RegisterRoutingStateChangedEvent 注册以感知 Pod 状态变化。
+func NewStatefulRpcProxy(targetService string, srcServiceName string, router route.StatefulRouteForClientDriver, podTarget func(podIndex int) string, opts ...StatefulOption) (*statefulRpcClient, error) {
+	if router == nil || podTarget == nil {
+		return nil, errors.New(400, "INVALID_CONFIG", "stateful proxy requires router and pod target")
+	}
+	o := statefulOptions{maxReroutes: defaultMaxReroutes}
+	for _, fn := range opts {
+		fn(&o)
+	}
+	c := &statefulRpcClient{
+		service:    targetService,
+		srcService: srcServiceName,
+		router:     router,
+		podTarget:  podTarget,
+		opts:       o,
+		pods:       make(map[int]*podConn),
+	}
+	c.maxReroutes.Store(int32(o.maxReroutes))
+	return c, nil
+}
+
+// SetMaxReroutes 运行时调整单次调用的最大重新路由次数（如按配置热更新），对之后的调用生效
+func (c *statefulRpcClient) SetMaxReroutes(n int) {
+	if n >= 0 {
+		c.maxReroutes.Store(int32(n))
+	}
+}
+
+// Call 执行RPC调用，路由到路由键链接的 Pod
+func (c *statefulRpcClient) Call(ctx context.Context, msgId string, msgContent RpcContent) (RpcContent, error) {
+	key := routeKeyFrom(ctx)
+	if key == "" {
+		return nil, errors.New(400, "ROUTE_KEY_REQUIRED", "stateful call requires a route key")
+	}
+	if orderKeyFrom(ctx) == "" {
+		ctx = WithOrderKey(ctx, key)
+	}
+
+	maxReroutes := int(c.maxReroutes.Load())
+	for attempt := 0; ; attempt++ {
+		pod, err := c.router.ComputeLinkedPod(ctx, c.opts.namespace, key, c.service)
+		if err != nil {
+			return nil, errors.New(503, "NO_AVAILABLE_POD", fmt.Sprintf("no pod for %s/%s", c.service, key)).WithCause(err)
+		}
+		var rsp RpcContent
+		pc, err := c.acquirePod(ctx, pod)
+		if err == nil {
+			rsp, err = pc.client.Call(ctx, msgId, msgContent)
+			c.releasePod(pod, pc)
+		}
+		if err == nil || attempt >= maxReroutes || !c.reroute(ctx, key, pod, err) {
+			return rsp, err
+		}
+		log.Warnf("Reroute %s/%s from pod %d: %v", c.service, key, pod, err)
+	}
+}
+
+// reroute 判断失败的调用能否重新路由，并刷新路由缓存
+// 仅在请求未被执行时重新路由：Pod 拒绝了调用，或无法与 Pod 建立流。
+func (c *statefulRpcClient) reroute(ctx context.Context, key string, pod int, err error) bool {
+	switch errors.Reason(err) {
+	case ReasonAlreadyInOtherPod:
+		_, _ = c.router.CacheExpired(ctx, c.opts.namespace, key, c.service)
+		if hint, perr := strconv.Atoi(errors.FromError(err).Metadata[facade.PodMetadataKey]); perr == nil && hint > 0 && hint != pod {
+			_, _ = c.router.SetCache(ctx, c.opts.namespace, key, c.service, hint)
+		}
+		return true
+	case "STREAM_UNAVAILABLE":
+		_, _ = c.router.CacheExpired(ctx, c.opts.namespace, key, c.service)
+		return true
+	}
+	return false
+}
+
+// acquirePod 返回 Pod 的连接，不存在时建立；建立连接时不持锁
+func (c *statefulRpcClient) acquirePod(ctx context.Context, pod int) (*podConn, error) {
+	c.mu.Lock()
+	pc, err := c.refPod(pod)
+	c.mu.Unlock()
+	if pc != nil || err != nil {
+		return pc, err
+	}
+
+	client, err := NewRpcProxy(ctx, c.podTarget(pod), c.srcService, true, nil, c.opts.dialOpts...)
+	if err != nil {
+		return nil, errors.New(503, "STREAM_UNAVAILABLE", fmt.Sprintf("connect pod %d failed", pod)).WithCause(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pc, err = c.refPod(pod); pc != nil || err != nil {
+		// 并发调用已建立连接
+		_ = client.Close()
+		return pc, err
+	}
+	pc = &podConn{client: client, refs: 1}
+	c.pods[pod] = pc
+	return pc, nil
+}
+
+// refPod 引用已有的连接，需持有 c.mu
+func (c *statefulRpcClient) refPod(pod int) (*podConn, error) {
+	if c.closed {
+		return nil, errors.New(500, "PROXY_CLOSED", "RPC proxy is closed")
+	}
+	pc := c.pods[pod]
+	if pc != nil {
+		pc.refs++
+	}
+	return pc, nil
+}
+
+// releasePod 调用结束；已退役且无进行中调用的连接关闭
+func (c *statefulRpcClient) releasePod(pod int, pc *podConn) {
+	c.mu.Lock()
+	pc.refs--
+	closeNow := pc.retired && pc.refs == 0
+	c.mu.Unlock()
+	if closeNow {
+		_ = pc.client.Close()
+	}
+}
+
+// retirePod 移除 Pod 的连接，进行中的调用结束后关闭
+func (c *statefulRpcClient) retirePod(pod int) {
+	c.mu.Lock()
+	pc := c.pods[pod]
+	if pc == nil {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.pods, pod)
+	pc.retired = true
+	closeNow := pc.refs == 0
+	c.mu.Unlock()
+	if closeNow {
+		_ = pc.client.Close()
+	}
+}
+
+// OnStateChanged 实现 route.StateChanged：Pod 不再可路由时释放其连接，后续调用经路由重新解析
+func (c *statefulRpcClient) OnStateChanged(namespace, serviceName string, podID int, pre, now *route.StatefulServiceState) {
+	if namespace != c.opts.namespace || serviceName != c.service || route.IsPodRoutable(now) {
+		return
+	}
+	log.Infof("Pod %s/%d is not routable, release its stream", serviceName, podID)
+	c.retirePod(podID)
+}
+
+// Close 关闭所有 Pod 的连接
+func (c *statefulRpcClient) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	pods := make([]int, 0, len(c.pods))
+	for pod := range c.pods {
+		pods = append(pods, pod)
+	}
+	c.mu.Unlock()
+
+	for _, pod := range pods {
+		c.retirePod(pod)
+	}
+	return nil
+}
+
+// IsClosed 检查是否已关闭
+func (c *statefulRpcClient) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// GetService 获取服务名
+func (c *statefulRpcClient) GetService() string {
+	return c.srcService
+}
+
+var _ route.StateChanged = (*statefulRpcClient)(nil)
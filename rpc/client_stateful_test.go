@@ -0,0 +1,184 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeRouter 路由缓存：ComputeLinkedPod 优先返回缓存，未命中时返回 owners 中的链接
+type fakeRouter struct {
+	route.StatefulRouteForClientDriver
+
+	mu       sync.Mutex
+	cache    map[string]int
+	owners   map[string]int
+	computed int
+}
+
+func (r *fakeRouter) ComputeLinkedPod(_ context.Context, _, uid, _ string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.computed++
+	if pod, ok := r.cache[uid]; ok {
+		return pod, nil
+	}
+	if pod, ok := r.owners[uid]; ok {
+		r.cache[uid] = pod
+		return pod, nil
+	}
+	return 0, fmt.Errorf("no available pod found")
+}
+
+func (r *fakeRouter) CacheExpired(_ context.Context, _, uid, _ string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cache, uid)
+	return 0, nil
+}
+
+func (r *fakeRouter) SetCache(_ context.Context, _, uid, _ string, podIndex int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache[uid] = podIndex
+	return podIndex, nil
+}
+
+// podService 只处理链接到本 Pod 的 id，其余以 ALREADY_IN_OTHER_POD 拒绝
+type podService struct {
+	pod    int
+	router *fakeRouter
+}
+
+func (s *podService) Where(_ context.Context, id string) (string, error) {
+	s.router.mu.Lock()
+	owner := s.router.owners[id]
+	s.router.mu.Unlock()
+	if owner != s.pod {
+		return "", ErrAlreadyInOtherPod(owner)
+	}
+	return fmt.Sprintf("pod-%d:%s", s.pod, id), nil
+}
+
+// startPods 启动 n 个内存 Pod，返回拨号选项
+func startPods(t *testing.T, router *fakeRouter, n int) grpc.DialOption {
+	lis := make(map[string]*bufconn.Listener)
+	for pod := 1; pod <= n; pod++ {
+		drv := NewRpcServer()
+		require.NoError(t, drv.Register(&podService{pod: pod, router: router}))
+		l := bufconn.Listen(1 << 20)
+		srv := grpc.NewServer()
+		rpc.RegisterRouterServiceServer(srv, NewRouterServiceServer(drv))
+		go func() { _ = srv.Serve(l) }()
+		t.Cleanup(srv.Stop)
+		lis[fmt.Sprintf("pod-%d", pod)] = l
+	}
+	return grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		l, ok := lis[addr]
+		if !ok {
+			return nil, fmt.Errorf("unknown pod %s", addr)
+		}
+		return l.DialContext(ctx)
+	})
+}
+
+func newStatefulProxy(t *testing.T, router *fakeRouter, pods int) *statefulRpcClient {
+	c, err := NewStatefulRpcProxy("game", "src", router, func(pod int) string { return fmt.Sprintf("passthrough:///pod-%d", pod) },
+		WithPodDialOptions(startPods(t, router, pods), grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func where(c *statefulRpcClient, id string) (string, error) {
+	var out string
+	p, err := NewServiceProxy[interface {
+		Where(ctx context.Context, id string) (string, error)
+	}](c)
+	if err != nil {
+		return "", err
+	}
+	err = p.Invoke(WithRouteKey(context.Background(), id), "Where", []any{id}, &out)
+	return out, err
+}
+
+func TestStatefulClient_Route(t *testing.T) {
+	router := &fakeRouter{cache: map[string]int{}, owners: map[string]int{"u1": 1, "u2": 2, "u3": 2}}
+	c := newStatefulProxy(t, router, 2)
+
+	for id, want := range map[string]string{"u1": "pod-1:u1", "u2": "pod-2:u2", "u3": "pod-2:u3"} {
+		got, err := where(c, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	// 每个 Pod 一条多路复用流
+	assert.Len(t, c.pods, 2)
+
+	_, err := c.Call(context.Background(), "Where", &Content[[][]byte]{CType: RpcContentBytes})
+	assert.Equal(t, "ROUTE_KEY_REQUIRED", errors.Reason(err))
+}
+
+func TestStatefulClient_Reroute(t *testing.T) {
+	router := &fakeRouter{cache: map[string]int{"u1": 2}, owners: map[string]int{"u1": 1}}
+	c := newStatefulProxy(t, router, 2)
+
+	// 缓存指向旧 Pod：被拒绝后按拒绝中的链接 Pod 重新路由
+	got, err := where(c, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "pod-1:u1", got)
+	assert.Equal(t, 1, router.cache["u1"])
+	assert.Equal(t, 2, router.computed)
+
+	// 链接的 Pod 不可达：重新路由次数用尽后返回错误
+	router.owners["u2"] = 3
+	router.cache["u2"] = 1
+	_, err = where(c, "u2")
+	assert.Equal(t, "STREAM_UNAVAILABLE", errors.Reason(err))
+	assert.Equal(t, 2+1+defaultMaxReroutes, router.computed)
+}
+
+func TestStatefulClient_PodStateChanged(t *testing.T) {
+	router := &fakeRouter{cache: map[string]int{}, owners: map[string]int{"u1": 1}}
+	c := newStatefulProxy(t, router, 2)
+
+	_, err := where(c, "u1")
+	require.NoError(t, err)
+	conn := c.pods[1].client
+
+	// 仍可路由的状态变化不影响连接
+	c.OnStateChanged("", "game", 1, nil, &route.StatefulServiceState{State: route.ServiceStateReady, RoutingState: route.RoutingStateDenyNewCall})
+	assert.Contains(t, c.pods, 1)
+
+	// Pod 下线：释放连接，链接迁移后的调用路由到新 Pod
+	c.OnStateChanged("", "game", 1, nil, &route.StatefulServiceState{State: route.ServiceStateReady, RoutingState: route.RoutingStateShutdown})
+	assert.NotContains(t, c.pods, 1)
+	assert.True(t, conn.IsClosed())
+
+	router.owners["u1"] = 2
+	got, err := where(c, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "pod-2:u1", got)
+}
+
+func TestStatefulClient_SetMaxReroutes(t *testing.T) {
+	router := &fakeRouter{cache: map[string]int{"u1": 2}, owners: map[string]int{"u1": 1}}
+	c := newStatefulProxy(t, router, 2)
+
+	// 不允许重新路由：旧 Pod 的拒绝直接返回
+	c.SetMaxReroutes(0)
+	_, err := where(c, "u1")
+	assert.Equal(t, ReasonAlreadyInOtherPod, errors.Reason(err))
+	assert.Equal(t, 1, router.computed)
+}
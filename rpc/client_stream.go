@@ -0,0 +1,204 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	// streamRetryBase 流重建失败后的初始退避
+	streamRetryBase = 100 * time.Millisecond
+	// streamRetryMax 流重建失败后的最大退避
+	streamRetryMax = 5 * time.Second
+)
+
+type orderKeyCtx struct{}
+
+// WithOrderKey 为有状态调用设置顺序键（如实体 id）：服务端按到达顺序处理同一键的请求，不同键并行
+func WithOrderKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, orderKeyCtx{}, key)
+}
+
+// orderKeyFrom 读取调用上下文中的顺序键
+func orderKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(orderKeyCtx{}).(string)
+	return key
+}
+
+// streamConn 一条已建立的 StreamCall 流
+// 多个调用共享同一条流：发送串行化，接收由 recvLoop 按 ReqId 分发给等待中的调用方。
+type streamConn struct {
+	stream rpc.RouterService_StreamCallClient
+	cancel context.CancelFunc
+	sendMu sync.Mutex // gRPC 流的 Send 不可并发调用
+
+	mu      sync.Mutex
+	pending map[string]chan *rpc.StreamRsp
+
+	done     chan struct{} // 流断开后关闭
+	err      error         // 断开原因，done 关闭后只读
+	failOnce sync.Once
+}
+
+// register 登记等待响应的请求
+func (sc *streamConn) register(reqId string) chan *rpc.StreamRsp {
+	ch := make(chan *rpc.StreamRsp, 1)
+	sc.mu.Lock()
+	sc.pending[reqId] = ch
+	sc.mu.Unlock()
+	return ch
+}
+
+// take 取出并移除等待中的请求；不存在返回 nil
+func (sc *streamConn) take(reqId string) chan *rpc.StreamRsp {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	ch := sc.pending[reqId]
+	delete(sc.pending, reqId)
+	return ch
+}
+
+// send 串行发送请求
+func (sc *streamConn) send(req *rpc.StreamReq) error {
+	sc.sendMu.Lock()
+	defer sc.sendMu.Unlock()
+	return sc.stream.Send(req)
+}
+
+// fail 标记流断开：唤醒所有等待中的调用并取消流；仅首次调用生效
+func (sc *streamConn) fail(err error) {
+	sc.failOnce.Do(func() {
+		sc.mu.Lock()
+		sc.err = err
+		sc.pending = map[string]chan *rpc.StreamRsp{}
+		sc.mu.Unlock()
+		close(sc.done)
+		// 先取消以解除阻塞中的 Send，再关闭发送端
+		if sc.cancel != nil {
+			sc.cancel()
+		}
+		sc.sendMu.Lock()
+		_ = sc.stream.CloseSend()
+		sc.sendMu.Unlock()
+	})
+}
+
+// recvLoop 持续接收响应并按 ReqId 分发；流出错时失败所有等待中的调用并让出流，下次调用时重建
+func (c *rpcClient) recvLoop(sc *streamConn) {
+	for {
+		rsp, err := sc.stream.Recv()
+		if err != nil {
+			sc.fail(err)
+			c.dropStream(sc)
+			return
+		}
+		if t, ok := c.transfers.Load(rsp.ReqId); ok {
+			t.(*clientTransfer).onRsp(rsp)
+			continue
+		}
+		if ch := sc.take(rsp.ReqId); ch != nil {
+			ch <- rsp
+		} else {
+			// 调用方已取消或超时
+			log.Debugf("Discard stream response without waiter, msgId: %s, reqId: %s", rsp.MsgId, rsp.ReqId)
+		}
+	}
+}
+
+// acquireStream 返回当前可用的流，不存在时建立新流
+// 建立失败后按指数退避，退避期内的调用直接失败，避免对不可用的服务端反复建流。
+func (c *rpcClient) acquireStream(ctx context.Context) (*streamConn, error) {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+
+	if c.streamClosed {
+		return nil, errors.New(500, "PROXY_CLOSED", "RPC proxy is closed")
+	}
+	if c.sc != nil {
+		return c.sc, nil
+	}
+	if c.client == nil {
+		return nil, errors.New(503, "STREAM_UNAVAILABLE", "RPC proxy has no connection")
+	}
+	if time.Now().Before(c.retryAt) {
+		return nil, errors.New(503, "STREAM_UNAVAILABLE", "stream is reconnecting")
+	}
+	if c.streamFailures > 0 && c.conn != nil {
+		// 服务发现已更新地址列表，跳过连接退避以尽快连上新的实例
+		c.conn.ResetConnectBackoff()
+	}
+
+	// 流的生命周期独立于调用方；仅在建流完成前跟随调用方的取消
+	sctx, cancel := context.WithCancel(context.Background())
+	stop := context.AfterFunc(ctx, cancel)
+	stream, err := c.client.StreamCall(sctx)
+	if !stop() {
+		err = ctx.Err()
+	}
+	if err != nil {
+		cancel()
+		c.streamFailures++
+		c.retryAt = time.Now().Add(streamBackoff(c.streamFailures))
+		return nil, err
+	}
+
+	c.streamFailures = 0
+	c.retryAt = time.Time{}
+	c.sc = &streamConn{
+		stream:  stream,
+		cancel:  cancel,
+		pending: map[string]chan *rpc.StreamRsp{},
+		done:    make(chan struct{}),
+	}
+	go c.recvLoop(c.sc)
+	return c.sc, nil
+}
+
+// dropStream 流断开后从客户端移除，下次调用时重建
+func (c *rpcClient) dropStream(sc *streamConn) {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+	if c.sc == sc {
+		c.sc = nil
+		// 断开后的首次重建立即进行，之后失败才退避
+		c.streamFailures = 1
+		c.retryAt = time.Time{}
+	}
+}
+
+// closeStream 关闭当前流（Close 时调用）
+func (c *rpcClient) closeStream() {
+	c.streamMu.Lock()
+	sc := c.sc
+	c.sc = nil
+	c.streamClosed = true
+	c.streamMu.Unlock()
+	if sc != nil {
+		sc.fail(errors.New(500, "PROXY_CLOSED", "RPC proxy is closed"))
+	}
+}
+
+// streamBackoff 第 n 次失败后的退避时长
+func streamBackoff(n int) time.Duration {
+	d := streamRetryBase
+	for i := 1; i < n && d < streamRetryMax; i++ {
+		d *= 2
+	}
+	if d > streamRetryMax {
+		d = streamRetryMax
+	}
+	return d
+}
+
+// streamBroken 包装流断开错误
+func streamBroken(err error) error {
+	if errors.Reason(err) == "PROXY_CLOSED" {
+		return err
+	}
+	return errors.New(503, "STREAM_BROKEN", "stream broken before response").WithCause(err)
+}
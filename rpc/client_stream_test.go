@@ -0,0 +1,243 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// fakeStream 内存中的双向流：Send 的请求写入 reqs，Recv 读取 rsps
+type fakeStream struct {
+	grpc.ClientStream
+	ctx  context.Context
+	reqs chan *rpc.StreamReq
+	rsps chan *rpc.StreamRsp
+	errc chan error
+}
+
+func newFakeStream(ctx context.Context) *fakeStream {
+	return &fakeStream{
+		ctx:  ctx,
+		reqs: make(chan *rpc.StreamReq, 64),
+		rsps: make(chan *rpc.StreamRsp, 64),
+		errc: make(chan error, 1),
+	}
+}
+
+func (s *fakeStream) Send(req *rpc.StreamReq) error {
+	select {
+	case <-s.ctx.Done():
+		return io.EOF
+	case s.reqs <- req:
+		return nil
+	}
+}
+
+func (s *fakeStream) Recv() (*rpc.StreamRsp, error) {
+	select {
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	case err := <-s.errc:
+		return nil, err
+	case rsp := <-s.rsps:
+		return rsp, nil
+	}
+}
+
+func (s *fakeStream) CloseSend() error { return nil }
+
+// reply 回复请求（回显首段内容）
+func (s *fakeStream) reply(req *rpc.StreamReq) {
+	s.rsps <- &rpc.StreamRsp{MsgId: req.MsgId, ReqId: req.ReqId, MsgContent: req.MsgContent}
+}
+
+// fakeStreamClient 每次 StreamCall 创建新的 fakeStream
+type fakeStreamClient struct {
+	rpc.RouterServiceClient
+	mu      sync.Mutex
+	streams []*fakeStream
+	fail    atomic.Bool
+	opened  chan *fakeStream
+}
+
+func newFakeStreamClient() *fakeStreamClient {
+	return &fakeStreamClient{opened: make(chan *fakeStream, 8)}
+}
+
+func (f *fakeStreamClient) StreamCall(ctx context.Context, _ ...grpc.CallOption) (rpc.RouterService_StreamCallClient, error) {
+	if f.fail.Load() {
+		return nil, fmt.Errorf("unavailable")
+	}
+	s := newFakeStream(ctx)
+	f.mu.Lock()
+	f.streams = append(f.streams, s)
+	f.mu.Unlock()
+	f.opened <- s
+	return s, nil
+}
+
+func newStreamTestClient(t *testing.T, fc *fakeStreamClient) *rpcClient {
+	var n atomic.Int64
+	c := &rpcClient{
+		client:     fc,
+		stateful:   true,
+		srcService: "src",
+		reqIdGen:   func() string { return fmt.Sprintf("r%d", n.Add(1)) },
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func bytesContent(s string) RpcContent {
+	return &Content[[][]byte]{CType: RpcContentBytes, Dt: [][]byte{[]byte(s)}}
+}
+
+func TestStreamMux_ConcurrentOutOfOrder(t *testing.T) {
+	fc := newFakeStreamClient()
+	c := newStreamTestClient(t, fc)
+
+	const n = 8
+	var wg sync.WaitGroup
+	results := make([]string, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rsp, err := c.Call(context.Background(), "m", bytesContent(fmt.Sprintf("p%d", i)))
+			if assert.NoError(t, err) {
+				results[i] = string(rsp.Data().([][]byte)[0])
+			}
+		}(i)
+	}
+
+	s := <-fc.opened
+	// 收齐全部请求后逆序回复：证明调用并发在途且按 ReqId 分发
+	reqs := make([]*rpc.StreamReq, 0, n)
+	for len(reqs) < n {
+		reqs = append(reqs, <-s.reqs)
+	}
+	for i := n - 1; i >= 0; i-- {
+		s.reply(reqs[i])
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		assert.Equal(t, fmt.Sprintf("p%d", i), results[i])
+	}
+	assert.Len(t, fc.streams, 1)
+}
+
+func TestStreamMux_ContextCancel(t *testing.T) {
+	fc := newFakeStreamClient()
+	c := newStreamTestClient(t, fc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.Call(ctx, "slow", bytesContent("x"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	s := <-fc.opened
+	slow := <-s.reqs
+	c.streamMu.Lock()
+	sc := c.sc
+	c.streamMu.Unlock()
+	sc.mu.Lock()
+	assert.Empty(t, sc.pending)
+	sc.mu.Unlock()
+
+	// 迟到的响应被丢弃，不影响后续调用
+	s.reply(slow)
+	done := make(chan RpcContent, 1)
+	go func() {
+		rsp, err := c.Call(context.Background(), "fast", bytesContent("y"))
+		assert.NoError(t, err)
+		done <- rsp
+	}()
+	s.reply(<-s.reqs)
+	rsp := <-done
+	assert.Equal(t, "y", string(rsp.Data().([][]byte)[0]))
+}
+
+func TestStreamMux_Reconnect(t *testing.T) {
+	fc := newFakeStreamClient()
+	c := newStreamTestClient(t, fc)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.Call(context.Background(), "m", bytesContent("a"))
+		errc <- err
+	}()
+	s1 := <-fc.opened
+	<-s1.reqs
+	// 流断开：在途调用失败
+	s1.errc <- io.ErrUnexpectedEOF
+	err := <-errc
+	require.Error(t, err)
+	assert.Equal(t, "STREAM_BROKEN", errors.Reason(err))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// 下次调用透明重建流
+	go func() {
+		_, err := c.Call(context.Background(), "m", bytesContent("b"))
+		errc <- err
+	}()
+	s2 := <-fc.opened
+	assert.NotSame(t, s1, s2)
+	s2.reply(<-s2.reqs)
+	assert.NoError(t, <-errc)
+}
+
+func TestStreamMux_ReconnectBackoff(t *testing.T) {
+	fc := newFakeStreamClient()
+	c := newStreamTestClient(t, fc)
+
+	fc.fail.Store(true)
+	_, err := c.Call(context.Background(), "m", bytesContent("a"))
+	require.Error(t, err)
+	// 退避期内直接失败，不再建流
+	_, err = c.Call(context.Background(), "m", bytesContent("a"))
+	assert.Equal(t, "STREAM_UNAVAILABLE", errors.Reason(err))
+
+	fc.fail.Store(false)
+	c.streamMu.Lock()
+	c.retryAt = time.Time{}
+	c.streamMu.Unlock()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.Call(context.Background(), "m", bytesContent("b"))
+		errc <- err
+	}()
+	s := <-fc.opened
+	s.reply(<-s.reqs)
+	assert.NoError(t, <-errc)
+	assert.Equal(t, 200*time.Millisecond, streamBackoff(2))
+	assert.Equal(t, streamRetryMax, streamBackoff(100))
+}
+
+func TestStreamMux_Close(t *testing.T) {
+	fc := newFakeStreamClient()
+	c := newStreamTestClient(t, fc)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.Call(context.Background(), "m", bytesContent("a"))
+		errc <- err
+	}()
+	s := <-fc.opened
+	<-s.reqs
+	require.NoError(t, c.Close())
+	assert.Equal(t, "PROXY_CLOSED", errors.Reason(<-errc))
+
+	_, err := c.callStream(context.Background(), "m", nil)
+	assert.Equal(t, "PROXY_CLOSED", errors.Reason(err))
+}
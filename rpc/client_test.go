@@ -123,8 +123,8 @@ func TestRpcClient_Call(t *testing.T) {
 		}
 
 		content := &Content[string]{
-			CType:   RpcContentJson,
-			Content: `{"test": "data"}`,
+			CType: RpcContentJson,
+			Dt:    `{"test": "data"}`,
 		}
 
 		result, err := client.Call(ctx, "test-method", content)
@@ -139,8 +139,8 @@ func TestRpcClient_Call(t *testing.T) {
 		}
 
 		content := &Content[string]{
-			CType:   RpcContentFile, // 不支持的类型
-			Content: "test",
+			CType: RpcContentType(99), // 不支持的类型
+			Dt:    "test",
 		}
 
 		result, err := client.Call(ctx, "test-method", content)
@@ -167,8 +167,8 @@ func TestRpcClient_CallJson(t *testing.T) {
 		}
 
 		content := &Content[string]{
-			CType:   RpcContentJson,
-			Content: `{"test": "data"}`,
+			CType: RpcContentJson,
+			Dt:    `{"test": "data"}`,
 		}
 
 		result, err := client.callJson(ctx, "test-method", content)
@@ -189,8 +189,8 @@ func TestRpcClient_CallJson(t *testing.T) {
 		}
 
 		content := &Content[int]{
-			CType:   RpcContentJson,
-			Content: 123, // 错误的数据类型
+			CType: RpcContentJson,
+			Dt:    123, // 错误的数据类型
 		}
 
 		result, err := client.callJson(ctx, "test-method", content)
@@ -210,8 +210,8 @@ func TestRpcClient_CallJson(t *testing.T) {
 		}
 
 		content := &Content[string]{
-			CType:   RpcContentJson,
-			Content: `{"test": "data"}`,
+			CType: RpcContentJson,
+			Dt:    `{"test": "data"}`,
 		}
 
 		result, err := client.callJson(ctx, "test-method", content)
@@ -241,8 +241,8 @@ func TestRpcClient_CallBytes(t *testing.T) {
 		}
 
 		content := &Content[[][]byte]{
-			CType:   RpcContentBytes,
-			Content: [][]byte{[]byte("test")},
+			CType: RpcContentBytes,
+			Dt:    [][]byte{[]byte("test")},
 		}
 
 		result, err := client.callBytes(ctx, "test-method", content)
@@ -263,8 +263,8 @@ func TestRpcClient_CallBytes(t *testing.T) {
 		}
 
 		content := &Content[string]{
-			CType:   RpcContentBytes,
-			Content: "invalid-data", // 错误的数据类型
+			CType: RpcContentBytes,
+			Dt:    "invalid-data", // 错误的数据类型
 		}
 
 		result, err := client.callBytes(ctx, "test-method", content)
@@ -417,8 +417,8 @@ func TestRpcClient_Concurrency(t *testing.T) {
 		}
 
 		content := &Content[string]{
-			CType:   RpcContentJson,
-			Content: `{"test": "data"}`,
+			CType: RpcContentJson,
+			Dt:    `{"test": "data"}`,
 		}
 
 		// 并发调用
@@ -462,8 +462,8 @@ func TestRpcClient_Integration(t *testing.T) {
 
 		// 测试完整调用流程
 		content := &Content[string]{
-			CType:   RpcContentJson,
-			Content: `{"integration": "test"}`,
+			CType: RpcContentJson,
+			Dt:    `{"integration": "test"}`,
 		}
 
 		result, err := client.Call(context.Background(), "integration-method", content)
@@ -555,8 +555,8 @@ func TestRpcRemoteCall(t *testing.T) {
 		}
 
 		content := &Content[[][]byte]{
-			CType:   RpcContentBytes,
-			Content: [][]byte{fstIntZero, bytesData},
+			CType: RpcContentBytes,
+			Dt:    [][]byte{fstIntZero, bytesData},
 		}
 		resp, err := client.callBytes(ctx, "onEntityCall", content)
 		assert.NoError(t, err)
@@ -607,8 +607,8 @@ func TestRpcRemoteCall(t *testing.T) {
 		}
 
 		content := &Content[[][]byte]{
-			CType:   RpcContentBytes,
-			Content: [][]byte{fstIntZero, paramMsgBytes, entityReqBytes},
+			CType: RpcContentBytes,
+			Dt:    [][]byte{fstIntZero, paramMsgBytes, entityReqBytes},
 		}
 		resp, err := client.callBytes(ctx, "userLogin", content)
 		assert.NoError(t, err)
@@ -0,0 +1,252 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// transferResumeTimeout 流断开后客户端重建流并续传的最长时间
+const transferResumeTimeout = 30 * time.Second
+
+// clientTransfer 客户端的一次分块传输调用：上传 FileData.Reader，接收结果与下载分块
+// 承载的流断开后在新流上续传，双方从对端报告的偏移继续，已确认的数据不重传。
+type clientTransfer struct {
+	c        *rpcClient
+	header   *rpc.StreamReq // 首条消息，续传时携带相同的调用信息
+	upload   *chunkSender
+	download *chunkReceiver
+
+	result chan *rpc.StreamRsp // 结果响应（无下载时）或首个下载分块
+	done   chan struct{}
+
+	mu        sync.Mutex
+	sc        *streamConn
+	gotResult bool
+	err       error
+	closeOnce sync.Once
+}
+
+// callFile 处理 RpcContentFile 调用：始终经由 StreamCall 传输
+// 返回内容中的 Reader 为下载数据，读取受调用 ctx 约束；不再读取时应关闭（io.Closer）。
+func (c *rpcClient) callFile(ctx context.Context, msgId string, data *FileData) (content RpcContent, err error) {
+	req := &rpc.StreamReq{
+		SrcService: c.srcService,
+		MsgId:      msgId,
+		MsgContent: data.Args,
+		ReqId:      c.reqIdGen(),
+		OrderKey:   orderKeyFrom(ctx),
+		Chunk:      &rpc.Chunk{},
+	}
+	ctx, span := startStreamClientSpan(ctx, req)
+	defer func() { clientTracer.End(ctx, span, nil, err) }()
+
+	src := data.Reader
+	if src == nil {
+		src = bytes.NewReader(nil)
+	}
+	t := &clientTransfer{
+		c:      c,
+		header: req,
+		upload: newChunkSender(src, defaultChunkSize, defaultTransferWindow),
+		result: make(chan *rpc.StreamRsp, 1),
+		done:   make(chan struct{}),
+	}
+	t.download = newChunkReceiver(defaultTransferWindow, t.ackDownload)
+	t.download.onClose = func() { t.close(errors.New(499, "TRANSFER_CLOSED", "reader closed")) }
+
+	sc, err := c.acquireStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.transfers.Store(req.ReqId, t)
+	t.sc = sc
+	if err := sc.send(req); err != nil {
+		// 服务端未收到首条消息：由 watch 在新流上以偏移 0 续传，服务端重新开始
+		sc.fail(err)
+		c.dropStream(sc)
+	} else {
+		_ = t.upload.bind(t.sendUpload, 0)
+	}
+	go func() {
+		if err := t.upload.run(); err != errTransferDone {
+			// 读取上传数据失败
+			t.close(err)
+		}
+	}()
+	go t.watch(ctx)
+
+	select {
+	case rsp := <-t.result:
+		return t.content(rsp)
+	case <-t.done:
+		// 结果与结束同时到达时优先返回结果
+		select {
+		case rsp := <-t.result:
+			return t.content(rsp)
+		default:
+		}
+		return nil, t.err
+	}
+}
+
+// content 将结果响应转换为返回内容
+func (t *clientTransfer) content(rsp *rpc.StreamRsp) (RpcContent, error) {
+	if rsp.Error != nil {
+		return nil, fromRpcError(rsp.Error)
+	}
+	data := &FileData{Args: rsp.MsgContent}
+	if rsp.Chunk != nil {
+		data.Reader = t.download
+	}
+	return &Content[*FileData]{CType: RpcContentFile, Dt: data}, nil
+}
+
+// onRsp 处理传输的响应；在 recvLoop 中调用，不阻塞
+func (t *clientTransfer) onRsp(rsp *rpc.StreamRsp) {
+	c := rsp.Chunk
+	switch {
+	case rsp.Error != nil:
+		t.deliver(rsp)
+		t.close(fromRpcError(rsp.Error))
+	case c == nil:
+		// 无下载的结果
+		t.deliver(rsp)
+		t.close(errTransferDone)
+	case c.Resume:
+		t.upload.ack(c.Ack)
+		if err := t.upload.bind(t.sendUpload, c.Offset); err != nil {
+			t.close(err)
+		}
+	case c.Cancel:
+		t.close(errors.New(499, "TRANSFER_CANCELED", "transfer canceled by server"))
+	case c.Ack > 0 && len(c.Data) == 0 && !c.Eof:
+		t.upload.ack(c.Ack)
+	default:
+		// 下载分块：offset 为 0 的分块携带结果
+		if c.Offset == 0 {
+			t.deliver(rsp)
+		}
+		if err := t.download.push(c); err != nil {
+			t.close(err)
+			return
+		}
+		if c.Eof {
+			t.close(errTransferDone)
+		}
+	}
+}
+
+// deliver 交付首个结果
+func (t *clientTransfer) deliver(rsp *rpc.StreamRsp) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.gotResult {
+		t.gotResult = true
+		t.result <- rsp
+	}
+}
+
+// send 在当前流上发送
+func (t *clientTransfer) send(req *rpc.StreamReq) error {
+	t.mu.Lock()
+	sc := t.sc
+	t.mu.Unlock()
+	if sc == nil {
+		return errTransferDetached
+	}
+	return sc.send(req)
+}
+
+func (t *clientTransfer) sendUpload(c *rpc.Chunk) error {
+	return t.send(&rpc.StreamReq{ReqId: t.header.ReqId, Chunk: c})
+}
+
+func (t *clientTransfer) ackDownload(offset int64) {
+	_ = t.send(&rpc.StreamReq{ReqId: t.header.ReqId, Chunk: &rpc.Chunk{Ack: offset}})
+}
+
+// watch 监视承载的流与调用 ctx：流断开时续传，ctx 结束时取消传输
+func (t *clientTransfer) watch(ctx context.Context) {
+	for {
+		t.mu.Lock()
+		sc := t.sc
+		t.mu.Unlock()
+		select {
+		case <-t.done:
+			return
+		case <-ctx.Done():
+			t.close(ctx.Err())
+			return
+		case <-sc.done:
+		}
+		t.upload.unbind()
+		if !t.resume(ctx) {
+			return
+		}
+	}
+}
+
+// resume 建立新流并请求续传；超过 transferResumeTimeout 仍失败则终止传输
+func (t *clientTransfer) resume(ctx context.Context) bool {
+	deadline := time.Now().Add(transferResumeTimeout)
+	for {
+		sc, err := t.c.acquireStream(ctx)
+		if err == nil {
+			t.mu.Lock()
+			t.sc = sc
+			t.mu.Unlock()
+			h := t.header
+			req := &rpc.StreamReq{
+				SrcService: h.SrcService,
+				MsgId:      h.MsgId,
+				MsgContent: h.MsgContent,
+				ReqId:      h.ReqId,
+				Traceinfo:  h.Traceinfo,
+				OrderKey:   h.OrderKey,
+				Header:     h.Header,
+			}
+			received, consumed := t.download.resumePoint()
+			req.Chunk = &rpc.Chunk{Resume: true, Offset: received, Ack: consumed}
+			if err = sc.send(req); err == nil {
+				return true
+			}
+			sc.fail(err)
+			t.c.dropStream(sc)
+		}
+		if errors.Reason(err) == "PROXY_CLOSED" {
+			t.close(err)
+			return false
+		}
+		if time.Now().After(deadline) {
+			t.close(errors.New(504, "TRANSFER_RESUME_TIMEOUT", "transfer not resumed in time").WithCause(err))
+			return false
+		}
+		select {
+		case <-t.done:
+			return false
+		case <-ctx.Done():
+			t.close(ctx.Err())
+			return false
+		case <-time.After(streamRetryBase):
+		}
+	}
+}
+
+// close 结束传输：正常结束时通知服务端释放，否则取消服务端的传输
+func (t *clientTransfer) close(err error) {
+	t.closeOnce.Do(func() {
+		t.mu.Lock()
+		t.err = err
+		t.mu.Unlock()
+		close(t.done)
+		t.c.transfers.Delete(t.header.ReqId)
+		t.upload.close(errTransferDone)
+		t.download.fail(err)
+		_ = t.send(&rpc.StreamReq{ReqId: t.header.ReqId, Chunk: &rpc.Chunk{Cancel: true}})
+	})
+}
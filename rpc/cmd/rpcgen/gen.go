@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const rpcImportPath = "github.com/go-kratos/kratos/v2/rpc"
+
+// method 待生成的接口方法
+type method struct {
+	Name    string
+	HasCtx  bool     // 第一个参数为 context.Context
+	Params  []string // 除 ctx 外的参数类型
+	Results []string // 除末尾 error 外的返回值类型
+}
+
+// generate 解析 dir 下的 Go 源码（不含测试文件），为接口 typeName 生成代理代码
+func generate(dir, typeName string) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		it := findInterface(file, typeName)
+		if it == nil {
+			continue
+		}
+		used := map[string]bool{}
+		methods, err := collectMethods(fset, it, used)
+		if err != nil {
+			return nil, err
+		}
+		return render(file.Name.Name, typeName, methods, usedImports(file, used, len(methods) > 0))
+	}
+	return nil, fmt.Errorf("interface %s not found in %s", typeName, dir)
+}
+
+// findInterface 在文件中查找接口定义
+func findInterface(file *ast.File, typeName string) *ast.InterfaceType {
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if ts.Name.Name != typeName {
+				continue
+			}
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				return it
+			}
+		}
+	}
+	return nil
+}
+
+// collectMethods 提取接口方法；要求最后一个返回值为 error，且不支持嵌入接口与可变参数
+func collectMethods(fset *token.FileSet, it *ast.InterfaceType, used map[string]bool) ([]method, error) {
+	expr := func(e ast.Expr) string {
+		ast.Inspect(e, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok {
+					used[id.Name] = true
+				}
+			}
+			return true
+		})
+		var buf bytes.Buffer
+		_ = printer.Fprint(&buf, fset, e)
+		return buf.String()
+	}
+
+	var methods []method
+	for _, f := range it.Methods.List {
+		ft, ok := f.Type.(*ast.FuncType)
+		if !ok || len(f.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded interfaces are not supported", fset.Position(f.Pos()))
+		}
+		m := method{Name: f.Names[0].Name}
+		var params []string
+		for _, p := range ft.Params.List {
+			if _, ok := p.Type.(*ast.Ellipsis); ok {
+				return nil, fmt.Errorf("%s: %s: variadic parameters are not supported", fset.Position(p.Pos()), m.Name)
+			}
+			n := len(p.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				params = append(params, expr(p.Type))
+			}
+		}
+		if len(params) > 0 && params[0] == "context.Context" {
+			m.HasCtx = true
+			params = params[1:]
+		}
+		m.Params = params
+
+		var results []string
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				n := len(r.Names)
+				if n == 0 {
+					n = 1
+				}
+				for i := 0; i < n; i++ {
+					results = append(results, expr(r.Type))
+				}
+			}
+		}
+		if len(results) == 0 || results[len(results)-1] != "error" {
+			return nil, fmt.Errorf("%s: %s: last result must be error", fset.Position(f.Pos()), m.Name)
+		}
+		m.Results = results[:len(results)-1]
+		methods = append(methods, m)
+	}
+	return methods, nil
+}
+
+// render 输出代理源码
+func render(pkg, typeName string, methods []method, imports []string) ([]byte, error) {
+	var b bytes.Buffer
+	p := func(format string, args ...any) { fmt.Fprintf(&b, format, args...) }
+
+	p("// Code generated by rpcgen. DO NOT EDIT.\n\n")
+	p("package %s\n\n", pkg)
+	// 标准库与第三方分组
+	var std, third []string
+	for _, imp := range imports {
+		path := imp[strings.Index(imp, `"`)+1:]
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			third = append(third, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	if pkg != "rpc" {
+		third = append(third, fmt.Sprintf("kratosrpc %q", rpcImportPath))
+	}
+	p("import (\n")
+	for _, imp := range std {
+		p("\t%s\n", imp)
+	}
+	if len(std) > 0 && len(third) > 0 {
+		p("\n")
+	}
+	for _, imp := range third {
+		p("\t%s\n", imp)
+	}
+	p(")\n\n")
+
+	rpcPkg := "kratosrpc."
+	if pkg == "rpc" {
+		rpcPkg = ""
+	}
+	proxy := typeName + "Proxy"
+	p("// %s %s 的 RPC 客户端代理\n", proxy, typeName)
+	p("type %s struct {\n\tproxy *%sServiceProxy[%s]\n}\n\n", proxy, rpcPkg, typeName)
+	p("// New%s 基于 RPC 客户端创建 %s 代理\n", proxy, typeName)
+	p("func New%s(client %sRpcClientDriver) (*%s, error) {\n", proxy, rpcPkg, proxy)
+	p("\tp, err := %sNewServiceProxy[%s](client)\n", rpcPkg, typeName)
+	p("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	p("\treturn &%s{proxy: p}, nil\n}\n", proxy)
+
+	for _, m := range methods {
+		var sig, args, dsts, rets []string
+		ctx := "context.Background()"
+		if m.HasCtx {
+			sig = append(sig, "ctx context.Context")
+			ctx = "ctx"
+		}
+		for i, t := range m.Params {
+			sig = append(sig, fmt.Sprintf("p%d %s", i, t))
+			args = append(args, fmt.Sprintf("p%d", i))
+		}
+		for i := range m.Results {
+			dsts = append(dsts, fmt.Sprintf("&r%d", i))
+			rets = append(rets, fmt.Sprintf("r%d", i))
+		}
+		results := append(append([]string(nil), m.Results...), "error")
+
+		p("\n// %s 调用远端 %s.%s\n", m.Name, typeName, m.Name)
+		p("func (p *%s) %s(%s) (%s) {\n", proxy, m.Name, strings.Join(sig, ", "), strings.Join(results, ", "))
+		for i, t := range m.Results {
+			p("\tvar r%d %s\n", i, t)
+		}
+		call := fmt.Sprintf("p.proxy.Invoke(%s, %q, []any{%s}", ctx, m.Name, strings.Join(args, ", "))
+		if len(dsts) > 0 {
+			call += ", " + strings.Join(dsts, ", ")
+		}
+		call += ")"
+		if len(rets) == 0 {
+			p("\treturn %s\n}\n", call)
+			continue
+		}
+		p("\terr := %s\n", call)
+		p("\treturn %s, err\n}\n", strings.Join(rets, ", "))
+	}
+	p("\nvar _ %s = (*%s)(nil)\n", typeName, proxy)
+
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, b.String())
+	}
+	return out, nil
+}
+
+// usedImports 返回被接口方法签名引用的 import 声明（按包名匹配），needContext 时确保导入 context
+func usedImports(file *ast.File, used map[string]bool, needContext bool) []string {
+	var out []string
+	hasContext := false
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if !used[name] && !(needContext && path == "context" && imp.Name == nil) {
+			continue
+		}
+		if path == "context" && imp.Name == nil {
+			hasContext = true
+		}
+		if imp.Name != nil {
+			out = append(out, imp.Name.Name+" "+imp.Path.Value)
+		} else {
+			out = append(out, imp.Path.Value)
+		}
+	}
+	if needContext && !hasContext {
+		out = append(out, strconv.Quote("context"))
+	}
+	sort.Strings(out)
+	return out
+}
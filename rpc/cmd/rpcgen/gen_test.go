@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("testdata", "greeter")
+	got, err := generate(dir, "Greeter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join(dir, "greeter_proxy.go.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("generated code mismatch:\n%s", got)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := t.TempDir()
+	src := `package x
+
+type NoError interface {
+	Get() string
+}
+
+type Variadic interface {
+	Sum(xs ...int) (int, error)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "x.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range []string{"NoError", "Variadic", "Missing"} {
+		if _, err := generate(dir, typ); err == nil {
+			t.Errorf("%s: expected error", typ)
+		}
+	}
+}
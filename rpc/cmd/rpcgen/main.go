@@ -0,0 +1,41 @@
+// rpcgen 为 Go 接口生成类型化 RPC 客户端代理
+//
+// 接口与服务端 rpcServer.Register 注册的服务方法一一对应（方法名即 msgId），
+// 生成的 <Type>Proxy 实现该接口，内部通过 rpc.ServiceProxy 按与服务端相同的规则编解码。
+// 用法（在接口所在包内）：
+//
+//	//go:generate go run github.com/go-kratos/kratos/v2/rpc/cmd/rpcgen -type Greeter
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeName := flag.String("type", "", "interface type name")
+	output := flag.String("output", "", "output file name; default <type>_proxy.go")
+	dir := flag.String("dir", ".", "package directory")
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	src, err := generate(*dir, *typeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "rpcgen:", err)
+		os.Exit(1)
+	}
+	out := *output
+	if out == "" {
+		out = filepath.Join(*dir, strings.ToLower(*typeName)+"_proxy.go")
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "rpcgen:", err)
+		os.Exit(1)
+	}
+}
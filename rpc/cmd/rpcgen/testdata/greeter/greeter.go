@@ -0,0 +1,19 @@
+package greeter
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Greeter 测试用接口
+type Greeter interface {
+	Hello(ctx context.Context, name string) (string, error)
+	Login(ctx context.Context, req *pb.UserLoginRequest) (*pb.UserLoginResponse, error)
+	Stats(from, to time.Time) (int, []string, error)
+	Ping(ctx context.Context) error
+}
+
+var _ = log.Info
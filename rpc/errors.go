@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/grpc/status"
+)
+
+// toRpcError 将错误转换为传输形式；非 kratos 错误按 500 处理
+func toRpcError(err error) *rpc.RpcError {
+	se := errors.FromError(err)
+	return &rpc.RpcError{
+		Code:     se.Code,
+		Reason:   se.Reason,
+		Message:  se.Message,
+		Metadata: se.Metadata,
+	}
+}
+
+// fromRpcError 还原 kratos 错误
+func fromRpcError(e *rpc.RpcError) *errors.Error {
+	return errors.New(int(e.GetCode()), e.GetReason(), e.GetMessage()).WithMetadata(e.GetMetadata())
+}
+
+// toStatusError 一元调用失败时返回的 gRPC 错误
+// status 同时携带 ErrorInfo（兼容 kratos 客户端与 HTTP 传输）与 RpcError（保留原始 code）。
+func toStatusError(err error) error {
+	se := errors.FromError(err)
+	st := se.GRPCStatus()
+	if ds, derr := st.WithDetails(toRpcError(se)); derr == nil {
+		st = ds
+	}
+	return st.Err()
+}
+
+// fromCallError 还原一元调用返回的错误；不含错误详情的错误原样返回
+func fromCallError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	details := st.Details()
+	for _, d := range details {
+		if re, ok := d.(*rpc.RpcError); ok {
+			return fromRpcError(re)
+		}
+	}
+	if len(details) > 0 {
+		return errors.FromError(err)
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// errServerDriver 所有调用返回同一错误
+type errServerDriver struct{ err error }
+
+func (d errServerDriver) OnCall(context.Context, string, string, RpcContent) (RpcContent, error) {
+	return nil, d.err
+}
+
+func (errServerDriver) Register(any) error { return nil }
+
+// dialBufconn 启动内存 gRPC 服务并创建直连代理
+func dialBufconn(t *testing.T, drv RpcServerDriver, stateful bool, opts ...StreamOption) *rpcClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	rpc.RegisterRouterServiceServer(srv, NewRouterServiceServer(drv, opts...))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	c, err := NewRpcProxyDirect(context.Background(), "passthrough:///bufnet", "src", stateful,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestErrorPropagation(t *testing.T) {
+	// 1001 不是 HTTP 状态码，仅靠 gRPC code 映射无法还原
+	want := errors.New(1001, "ENTITY_LOCKED", "entity is locked").WithMetadata(map[string]string{"id": "u1"})
+	cases := []struct {
+		name     string
+		stateful bool
+		content  RpcContent
+	}{
+		{"json", false, &Content[string]{CType: RpcContentJson, Dt: "{}"}},
+		{"unary", false, &Content[[][]byte]{CType: RpcContentBytes, Dt: [][]byte{}}},
+		{"stream", true, &Content[[][]byte]{CType: RpcContentBytes, Dt: [][]byte{}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := dialBufconn(t, errServerDriver{err: want}, tc.stateful)
+			rsp, err := c.Call(context.Background(), "m", tc.content)
+			assert.Nil(t, rsp)
+			require.Error(t, err)
+
+			se := errors.FromError(err)
+			assert.EqualValues(t, 1001, se.Code)
+			assert.Equal(t, "ENTITY_LOCKED", errors.Reason(err))
+			assert.Equal(t, "entity is locked", se.Message)
+			assert.Equal(t, map[string]string{"id": "u1"}, se.Metadata)
+			assert.True(t, errors.Is(err, want))
+		})
+	}
+}
+
+func TestErrorPropagation_PlainError(t *testing.T) {
+	c := dialBufconn(t, errServerDriver{err: assert.AnError}, true)
+	_, err := c.Call(context.Background(), "m", &Content[[][]byte]{CType: RpcContentBytes})
+	require.Error(t, err)
+	assert.Equal(t, errors.UnknownCode, errors.Code(err))
+	assert.Equal(t, assert.AnError.Error(), errors.FromError(err).Message)
+}
+
+func TestFromCallError(t *testing.T) {
+	// 不含详情的错误原样返回
+	assert.Equal(t, assert.AnError, fromCallError(assert.AnError))
+
+	// 仅有 ErrorInfo（如其他 kratos 服务返回）时按 gRPC code 映射
+	err := fromCallError(errors.NotFound("USER_NOT_FOUND", "no user").GRPCStatus().Err())
+	assert.Equal(t, 404, errors.Code(err))
+	assert.Equal(t, "USER_NOT_FOUND", errors.Reason(err))
+}
@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// MethodOption 方法注册选项，覆盖按签名推断的打包器与编解码器
+type MethodOption func(*methodOptions)
+
+type methodOptions struct {
+	packer string
+	codec  string
+}
+
+// WithPacker 指定方法使用的打包器（RegisterPacker 注册的名称）
+func WithPacker(name string) MethodOption {
+	return func(o *methodOptions) { o.packer = name }
+}
+
+// WithCodec 指定方法全部参数与返回值使用的编解码器，如热点路径强制 msgpack
+func WithCodec(codec string) MethodOption {
+	return func(o *methodOptions) { o.codec = codec }
+}
+
+// MethodOptionProvider 服务可实现该接口，为方法（按方法名）声明注册选项
+type MethodOptionProvider interface {
+	RpcMethodOptions() map[string][]MethodOption
+}
+
+// methodOptionsOf 获取服务声明的方法选项；服务实现了 MethodOptionProvider 时返回非 nil
+func methodOptionsOf(service any) map[string][]MethodOption {
+	p, ok := service.(MethodOptionProvider)
+	if !ok {
+		return nil
+	}
+	if opts := p.RpcMethodOptions(); opts != nil {
+		return opts
+	}
+	return map[string][]MethodOption{}
+}
+
+// newFunInfo 分析方法签名并应用选项，选择打包器
+// 服务端注册与客户端代理共用，保证双方编解码规则一致。
+func newFunInfo(name string, methodType reflect.Type, opts ...MethodOption) (*FunInfo, error) {
+	var o methodOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	params, returns := analyzeSignature(methodType)
+	if o.codec != "" {
+		if encoding.GetCodec(o.codec) == nil {
+			return nil, errors.New(400, "CODEC_NOT_FOUND", fmt.Sprintf("codec %s not registered", o.codec))
+		}
+		for i := range params {
+			params[i].Codec = o.codec
+		}
+		for i := range returns {
+			returns[i].Codec = o.codec
+		}
+	}
+	fun := &FunInfo{Name: name, Param: params, ReturnParam: returns}
+	if o.packer != "" {
+		if fun.Packer = GetPacker(o.packer); fun.Packer == nil {
+			return nil, errors.New(400, "PACKER_NOT_FOUND", fmt.Sprintf("packer %s not registered", o.packer))
+		}
+	} else {
+		fun.Packer = SelectPacker(fun)
+	}
+	return fun, nil
+}
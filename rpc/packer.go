@@ -1,15 +1,65 @@
 package rpc
 
+import "sync"
+
+// Packers 已注册的打包器（按名称），请通过 RegisterPacker/GetPacker 访问
 var Packers = map[string]Packer{}
 
+var (
+	packerMu    sync.RWMutex
+	packerOrder []Packer // 注册顺序，后注册的优先匹配
+)
+
+// ContentPacker 声明所处理内容类型的打包器，用于内容协商：
+// 请求内容类型与方法默认打包器不一致时，改用处理该内容类型的打包器。
+type ContentPacker interface {
+	Packer
+	ContentType() RpcContentType
+}
+
+// RegisterPacker 注册打包器，同名打包器会被替换
+// 自动选择时后注册的打包器优先，自定义打包器可借此覆盖内置规则。
 func RegisterPacker(packer Packer) {
+	packerMu.Lock()
+	defer packerMu.Unlock()
+	if _, ok := Packers[packer.Name()]; ok {
+		for i, p := range packerOrder {
+			if p.Name() == packer.Name() {
+				packerOrder = append(packerOrder[:i], packerOrder[i+1:]...)
+				break
+			}
+		}
+	}
 	Packers[packer.Name()] = packer
+	packerOrder = append(packerOrder, packer)
+}
+
+// GetPacker 按名称获取打包器
+func GetPacker(name string) Packer {
+	packerMu.RLock()
+	defer packerMu.RUnlock()
+	return Packers[name]
 }
+
+// SelectPacker 按方法签名选择打包器
 func SelectPacker(fun *FunInfo) Packer {
-	// 选择逻辑
-	for _, packer := range Packers {
-		if packer.Match(fun) {
-			return packer
+	packerMu.RLock()
+	defer packerMu.RUnlock()
+	for i := len(packerOrder) - 1; i >= 0; i-- {
+		if packerOrder[i].Match(fun) {
+			return packerOrder[i]
+		}
+	}
+	return nil
+}
+
+// contentPacker 返回处理内容类型 ct 的打包器
+func contentPacker(ct RpcContentType) Packer {
+	packerMu.RLock()
+	defer packerMu.RUnlock()
+	for i := len(packerOrder) - 1; i >= 0; i-- {
+		if cp, ok := packerOrder[i].(ContentPacker); ok && cp.ContentType() == ct {
+			return cp
 		}
 	}
 	return nil
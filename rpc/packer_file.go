@@ -0,0 +1,129 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// FileData RpcContentFile 的数据
+// 普通参数（返回值）按顺序编码在 Args 中，io.Reader 参数（返回值）的内容通过 StreamCall 分块传输。
+type FileData struct {
+	Args   [][]byte
+	Reader io.Reader
+}
+
+var readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+// FilePacker 大负载打包器：处理至多一个 io.Reader 参数和/或至多一个 io.Reader 返回值的方法
+type FilePacker struct{}
+
+func (p *FilePacker) Name() string {
+	return "file"
+}
+
+func (p *FilePacker) ContentType() RpcContentType {
+	return RpcContentFile
+}
+
+func (p *FilePacker) Match(fun *FunInfo) bool {
+	in, out := countReader(fun.Param), countReader(fun.ReturnParam)
+	return in <= 1 && out <= 1 && in+out > 0
+}
+
+func countReader(params []ParamInfo) int {
+	n := 0
+	for _, param := range params {
+		if param.Type == readerType {
+			n++
+		}
+	}
+	return n
+}
+
+func (p *FilePacker) UnSerialize(content RpcContent, fun *FunInfo) ([]interface{}, error) {
+	if content.Type() != RpcContentFile {
+		return nil, errors.New(400, "INVALID_CONTENT_TYPE", "expect file content type for file packer")
+	}
+	data, ok := content.Data().(*FileData)
+	if !ok {
+		return nil, errors.New(400, "INVALID_DATA_TYPE", "expect *FileData for file content")
+	}
+	args := make([]interface{}, len(fun.Param))
+	j := 0
+	for i, paramInfo := range fun.Param {
+		t := paramInfo.Type
+		if t == readerType {
+			// 未携带负载时传入空 Reader
+			if data.Reader != nil {
+				args[i] = data.Reader
+			} else {
+				args[i] = bytes.NewReader(nil)
+			}
+			continue
+		}
+		if t.Kind() == reflect.Interface {
+			return nil, errors.New(400, "INVALID_PARAM_TYPE", "interface type param not supported without factory")
+		}
+		if j >= len(data.Args) {
+			args[i] = reflect.Zero(t).Interface()
+			continue
+		}
+		paramInstance := reflect.New(t).Interface()
+		if err := encoding.GetCodec(paramInfo.Codec).Unmarshal(data.Args[j], paramInstance); err != nil {
+			return nil, err
+		}
+		args[i] = reflect.ValueOf(paramInstance).Elem().Interface()
+		j++
+	}
+	return args, nil
+}
+
+func (p *FilePacker) Serialize(fun *FunInfo, returnValues []any) (RpcContent, error) {
+	data := &FileData{Args: make([][]byte, 0, len(returnValues))}
+	for i, ret := range fun.ReturnParam {
+		if i >= len(returnValues) {
+			continue
+		}
+		if ret.Type == readerType {
+			if r, ok := returnValues[i].(io.Reader); ok {
+				data.Reader = r
+			}
+			continue
+		}
+		b, err := encoding.GetCodec(ret.Codec).Marshal(returnValues[i])
+		if err != nil {
+			return nil, err
+		}
+		data.Args = append(data.Args, b)
+	}
+	return &Content[*FileData]{CType: RpcContentFile, Dt: data}, nil
+}
+
+func (p *FilePacker) Call(ctx context.Context, req RpcContent, fun *FunInfo) (RpcContent, error) {
+	args, err := p.UnSerialize(req, fun)
+	if err != nil {
+		return nil, err
+	}
+	callArgs := make([]reflect.Value, 0, len(args)+1)
+	mt := fun.Method.Type()
+	if mt.NumIn() > 0 && mt.In(0) == reflect.TypeOf((*context.Context)(nil)).Elem() {
+		callArgs = append(callArgs, reflect.ValueOf(ctx))
+	}
+	callArgs = append(callArgs, sliceToValues(args)...)
+	results := fun.Method.Call(callArgs)
+
+	for _, result := range results {
+		if result.Type().Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+			if !result.IsNil() {
+				return nil, result.Interface().(error)
+			}
+		}
+	}
+
+	return p.Serialize(fun, sliceToInterfaces(results))
+}
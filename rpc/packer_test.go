@@ -0,0 +1,170 @@
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// HotPathReq 通过结构体标签强制使用 msgpack
+type HotPathReq struct {
+	_     struct{} `rpc:"codec=msgpack"`
+	Key   string
+	Value int
+}
+
+// upperPacker 自定义打包器：仅处理参数为 upperText 的方法，不声明内容类型
+type upperPacker struct{ bytes BytesPacker }
+
+type upperText string
+
+func (p *upperPacker) Name() string { return "upper" }
+
+func (p *upperPacker) UnSerialize(content RpcContent, fun *FunInfo) ([]interface{}, error) {
+	return p.bytes.UnSerialize(content, fun)
+}
+
+func (p *upperPacker) Serialize(fun *FunInfo, returnValues []any) (RpcContent, error) {
+	return p.bytes.Serialize(fun, returnValues)
+}
+
+func (p *upperPacker) Call(ctx context.Context, req RpcContent, fun *FunInfo) (RpcContent, error) {
+	return p.bytes.Call(ctx, req, fun)
+}
+
+func (p *upperPacker) Match(fun *FunInfo) bool {
+	return len(fun.Param) == 1 && fun.Param[0].Type == reflect.TypeOf(upperText(""))
+}
+
+type negotiateService struct{}
+
+func (negotiateService) Add(a, b int) (int, error) { return a + b, nil }
+
+func (negotiateService) Echo(req *rpc.TestRequest) (*rpc.TestResponse, error) {
+	return &rpc.TestResponse{Success: true, Message: req.Name}, nil
+}
+
+func (negotiateService) Hot(req HotPathReq) (HotPathReq, error) {
+	req.Value++
+	return req, nil
+}
+
+func (negotiateService) Person(p Person) (Person, error) { return p, nil }
+
+func (negotiateService) Shout(s upperText) (string, error) { return string(s), nil }
+
+func (negotiateService) Ping() {}
+
+func (negotiateService) RpcMethodOptions() map[string][]MethodOption {
+	return map[string][]MethodOption{"Person": {WithCodec("msgpack")}}
+}
+
+func TestPackerRegistry(t *testing.T) {
+	RegisterPacker(&upperPacker{})
+	assert.Equal(t, "upper", GetPacker("upper").Name())
+	assert.Nil(t, GetPacker("missing"))
+
+	d := NewCallDispatcher("negotiate")
+	svc := negotiateService{}
+	for name, opts := range svc.RpcMethodOptions() {
+		require.NoError(t, d.RegisterMethod(name, reflect.ValueOf(svc).MethodByName(name).Interface(), opts...))
+	}
+	require.NoError(t, d.RegisterMethod("Shout", svc.Shout))
+	require.NoError(t, d.RegisterMethod("Hot", svc.Hot))
+	require.NoError(t, d.RegisterMethod("Echo", svc.Echo))
+
+	// 后注册的自定义打包器优先匹配
+	assert.Equal(t, "upper", d.RpcMethod["Shout"].Packer.Name())
+	// proto 消息不再被 JSON 打包器匹配
+	assert.Equal(t, "bytes", d.RpcMethod["Echo"].Packer.Name())
+	// 结构体标签与注册选项覆盖默认的 json 编解码器
+	assert.Equal(t, "msgpack", d.RpcMethod["Hot"].Param[0].Codec)
+	assert.Equal(t, "bytes", d.RpcMethod["Hot"].Packer.Name())
+	assert.Equal(t, "msgpack", d.RpcMethod["Person"].Param[0].Codec)
+	assert.Equal(t, "msgpack", d.RpcMethod["Person"].ReturnParam[0].Codec)
+
+	err := d.RegisterMethod("Add", svc.Add, WithPacker("missing"))
+	assert.Equal(t, "PACKER_NOT_FOUND", errors.Reason(err))
+	err = d.RegisterMethod("Add", svc.Add, WithCodec("missing"))
+	assert.Equal(t, "CODEC_NOT_FOUND", errors.Reason(err))
+	require.NoError(t, d.RegisterMethod("Add", svc.Add, WithPacker("json")))
+	assert.Equal(t, "json", d.RpcMethod["Add"].Packer.Name())
+}
+
+func TestContentNegotiation(t *testing.T) {
+	srv := NewRpcServer()
+	require.NoError(t, srv.Register(negotiateService{}))
+	ctx := context.Background()
+	call := func(msgId string, content RpcContent) RpcContent {
+		t.Helper()
+		rsp, err := srv.OnCall(ctx, "test", msgId, content)
+		require.NoError(t, err)
+		return rsp
+	}
+	jsonContent := func(s string) RpcContent { return &Content[string]{CType: RpcContentJson, Dt: s} }
+
+	_, err := srv.OnCall(ctx, "test", "RpcMethodOptions", jsonContent("{}"))
+	assert.Equal(t, "SERVICE_NOT_FOUND", errors.Reason(err))
+
+	// bytes 方法按 JSON 调用：多参数使用 JSON 数组
+	assert.Equal(t, "5", call("Add", jsonContent("[2,3]")).Data())
+	// proto 方法按 JSON 调用：使用 protojson
+	rsp := call("Echo", jsonContent(`{"name":"web"}`))
+	assert.Equal(t, RpcContentJson, rsp.Type())
+	assert.JSONEq(t, `{"success":true,"message":"web"}`, rsp.Data().(string))
+	// 同一方法按 bytes 调用
+	b, err := encoding.GetCodec("proto").Marshal(&rpc.TestRequest{Name: "svc"})
+	require.NoError(t, err)
+	rsp = call("Echo", &Content[[][]byte]{CType: RpcContentBytes, Dt: [][]byte{b}})
+	var echo rpc.TestResponse
+	require.NoError(t, encoding.GetCodec("proto").Unmarshal(rsp.Data().([][]byte)[0], &echo))
+	assert.Equal(t, "svc", echo.Message)
+	// msgpack 结构体按 JSON 调用
+	assert.JSONEq(t, `{"Key":"k","Value":2}`, call("Hot", jsonContent(`{"Key":"k","Value":1}`)).Data().(string))
+	// 无参数无返回值的方法
+	call("Ping", &Content[[][]byte]{CType: RpcContentBytes, Dt: [][]byte{}})
+}
+
+type negotiateClient interface {
+	Add(ctx context.Context, a, b int) (int, error)
+	Echo(ctx context.Context, req *rpc.TestRequest) (*rpc.TestResponse, error)
+	Hot(ctx context.Context, req HotPathReq) (HotPathReq, error)
+	Person(ctx context.Context, p Person) (Person, error)
+	Ping(ctx context.Context) error
+}
+
+func TestServiceProxy_Negotiation(t *testing.T) {
+	srv := NewRpcServer()
+	require.NoError(t, srv.Register(negotiateService{}))
+	p, err := NewServiceProxy[negotiateClient](dialBufconn(t, srv, false),
+		WithMethodOptions("Add", WithPacker("json")),
+		WithMethodOptions("Echo", WithPacker("json")),
+		WithMethodOptions("Person", WithCodec("msgpack")),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var sum int
+	require.NoError(t, p.Invoke(ctx, "Add", []any{2, 3}, &sum))
+	assert.Equal(t, 5, sum)
+
+	var echo *rpc.TestResponse
+	require.NoError(t, p.Invoke(ctx, "Echo", []any{&rpc.TestRequest{Name: "x"}}, &echo))
+	assert.Equal(t, "x", echo.Message)
+
+	var hot HotPathReq
+	require.NoError(t, p.Invoke(ctx, "Hot", []any{HotPathReq{Key: "k", Value: 1}}, &hot))
+	assert.Equal(t, 2, hot.Value)
+
+	var person Person
+	require.NoError(t, p.Invoke(ctx, "Person", []any{Person{Name: "amy", Age: 3}}, &person))
+	assert.Equal(t, Person{Name: "amy", Age: 3}, person)
+
+	require.NoError(t, p.Invoke(ctx, "Ping", nil))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"reflect"
+	"strings"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 
@@ -18,11 +19,15 @@ func (p *BytesPacker) Name() string {
 	return "bytes"
 }
 
+func (p *BytesPacker) ContentType() RpcContentType {
+	return RpcContentBytes
+}
+
 func (p *BytesPacker) Match(fun *FunInfo) bool {
 	// 只要不是“全部 JSON 结构体参数 + 返回”，其余情况均由 bytes 处理
 	paramTypes := fun.ParamTypes()
 	returnTypes := fun.ReturnTypes()
-	if isAllJson(paramTypes) && isAllJsonReturn(returnTypes) {
+	if (&JsonPacker{}).Match(fun) {
 		return false
 	}
 	return len(paramTypes) > 0 || len(returnTypes) > 0
@@ -146,16 +151,30 @@ func (p *JsonPacker) Name() string {
 	return "json"
 }
 
+func (p *JsonPacker) ContentType() RpcContentType {
+	return RpcContentJson
+}
+
 func (p *JsonPacker) Match(fun *FunInfo) bool {
 	paramTypes := fun.ParamTypes()
 	returnTypes := fun.ReturnTypes()
 
-	paramMatch := isAllJson(paramTypes)
-	returnMatch := isAllJsonReturn(returnTypes)
+	paramMatch := isAllJson(paramTypes) && isAllCodec(fun.Param, "json")
+	returnMatch := isAllJsonReturn(returnTypes) && isAllCodec(fun.ReturnParam, "json")
 
 	return paramMatch && returnMatch
 }
 
+// isAllCodec 参数是否全部使用编解码器 codec（proto 消息或被选项/标签指定其他编解码器时不走 JSON 打包）
+func isAllCodec(params []ParamInfo, codec string) bool {
+	for _, param := range params {
+		if param.Codec != codec {
+			return false
+		}
+	}
+	return true
+}
+
 func isAllJson(types []reflect.Type) bool {
 	// 如果没有参数，返回false
 	if len(types) == 0 {
@@ -200,11 +219,25 @@ func (p *JsonPacker) UnSerialize(content RpcContent, fun *FunInfo) ([]interface{
 		return nil, errors.New(400, "INVALID_DATA_TYPE", "expect string data for JSON content")
 	}
 
-	// 直接解析JSON字符串到对应的结构体
+	// 单个参数直接解析；多个参数时 JSON 数组按位置解析，JSON 对象解析到每个参数
+	parts := []json.RawMessage(nil)
+	if len(fun.Param) > 1 && strings.HasPrefix(strings.TrimSpace(data), "[") {
+		if err := json.Unmarshal([]byte(data), &parts); err != nil {
+			return nil, errors.New(400, "INVALID_JSON", err.Error())
+		}
+	}
 	args := make([]interface{}, 0, len(fun.Param))
-	for _, paramInfo := range fun.Param {
+	for i, paramInfo := range fun.Param {
+		raw := []byte(data)
+		if parts != nil {
+			if i >= len(parts) {
+				args = append(args, reflect.Zero(paramInfo.Type).Interface())
+				continue
+			}
+			raw = parts[i]
+		}
 		paramInstance := reflect.New(paramInfo.Type).Interface()
-		if err := json.Unmarshal([]byte(data), paramInstance); err != nil {
+		if err := json.Unmarshal(raw, paramInstance); err != nil {
 			return nil, err
 		}
 		args = append(args, reflect.ValueOf(paramInstance).Elem().Interface())
@@ -288,4 +321,5 @@ func sliceToValues(args []interface{}) []reflect.Value {
 func init() {
 	RegisterPacker(&BytesPacker{})
 	RegisterPacker(&JsonPacker{})
+	RegisterPacker(&FilePacker{})
 }
@@ -0,0 +1,250 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// ServiceProxy 基于 Go 接口的类型化 RPC 客户端代理
+// T 为与服务端 rpcServer.Register 注册的服务对应的接口：方法名即 msgId，
+// 参数/返回值按与服务端相同的 ParamInfo.Codec 规则编解码，打包器按相同规则选择。
+// 通常不直接使用，而是由 rpcgen 为接口生成实现该接口的代理类型：
+//
+//	//go:generate go run github.com/go-kratos/kratos/v2/rpc/cmd/rpcgen -type Greeter
+//	type Greeter interface {
+//		Hello(ctx context.Context, name string) (string, error)
+//	}
+//
+//	greeter, err := NewGreeterProxy(client) // greeter 实现 Greeter
+type ServiceProxy[T any] struct {
+	client  RpcClientDriver
+	methods map[string]*FunInfo
+}
+
+// ProxyOption 代理选项
+type ProxyOption func(*proxyOptions)
+
+type proxyOptions struct {
+	methods map[string][]MethodOption
+}
+
+// WithMethodOptions 为代理方法指定与服务端注册时相同的方法选项，
+// 也可仅在客户端指定 WithPacker("json") 等，由服务端按内容类型协商。
+func WithMethodOptions(method string, opts ...MethodOption) ProxyOption {
+	return func(o *proxyOptions) {
+		o.methods[method] = append(o.methods[method], opts...)
+	}
+}
+
+// NewServiceProxy 分析接口 T 的方法签名并创建代理
+func NewServiceProxy[T any](client RpcClientDriver, opts ...ProxyOption) (*ServiceProxy[T], error) {
+	it := reflect.TypeOf((*T)(nil)).Elem()
+	if it.Kind() != reflect.Interface {
+		return nil, fmt.Errorf("rpc: proxy type %v is not an interface", it)
+	}
+	o := proxyOptions{methods: map[string][]MethodOption{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	p := &ServiceProxy[T]{client: client, methods: make(map[string]*FunInfo, it.NumMethod())}
+	for i := 0; i < it.NumMethod(); i++ {
+		m := it.Method(i)
+		fun, err := newFunInfo(m.Name, m.Type, o.methods[m.Name]...)
+		if err != nil {
+			return nil, err
+		}
+		p.methods[m.Name] = fun
+	}
+	return p, nil
+}
+
+// Invoke 调用接口方法 method：args 为除 context.Context 外的参数，results 为非 error 返回值的指针
+func (p *ServiceProxy[T]) Invoke(ctx context.Context, method string, args []any, results ...any) error {
+	fun, ok := p.methods[method]
+	if !ok {
+		return errors.New(404, "METHOD_NOT_FOUND", fmt.Sprintf("method %s not found in proxy interface", method))
+	}
+	packer := fun.Packer
+	if packer == nil {
+		// 无参数且无返回值的方法没有默认打包器，与服务端协商规则一致按 bytes 发送
+		packer = contentPacker(RpcContentBytes)
+	}
+	if packer == nil {
+		return errors.New(400, "UNSUPPORTED_METHOD", fmt.Sprintf("no packer for method %s", method))
+	}
+	if len(args) != len(fun.Param) {
+		return errors.New(400, "INVALID_ARGS", fmt.Sprintf("method %s expects %d args, got %d", method, len(fun.Param), len(args)))
+	}
+	if len(results) != len(fun.ReturnParam) {
+		return errors.New(400, "INVALID_RESULTS", fmt.Sprintf("method %s returns %d values, got %d destinations", method, len(fun.ReturnParam), len(results)))
+	}
+
+	for i, param := range fun.Param {
+		if !assignable(args[i], param.Type) {
+			return errors.New(400, "INVALID_ARGS", fmt.Sprintf("method %s arg %d expects %v, got %T", method, i, param.Type, args[i]))
+		}
+	}
+
+	req, err := encodeArgs(packer, fun, args)
+	if err != nil {
+		return err
+	}
+	rsp, err := p.client.Call(ctx, method, req)
+	if err != nil {
+		return err
+	}
+	return decodeResults(fun, rsp, results)
+}
+
+// encodeArgs 按打包器的内容类型编码参数（与服务端 Packer.UnSerialize 对应）
+func encodeArgs(packer Packer, fun *FunInfo, args []any) (RpcContent, error) {
+	cp, ok := packer.(ContentPacker)
+	if !ok {
+		return nil, errors.New(400, "UNSUPPORTED_METHOD", fmt.Sprintf("packer %s does not declare a content type", packer.Name()))
+	}
+	switch cp.ContentType() {
+	case RpcContentJson:
+		// 单个参数直接编码，多个参数编码为按位置排列的 JSON 数组
+		codec := encoding.GetCodec("json")
+		if len(args) == 1 {
+			b, err := codec.Marshal(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return &Content[string]{CType: RpcContentJson, Dt: string(b)}, nil
+		}
+		parts := make([]json.RawMessage, len(args))
+		for i, arg := range args {
+			b, err := codec.Marshal(arg)
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = b
+		}
+		b, err := json.Marshal(parts)
+		if err != nil {
+			return nil, err
+		}
+		return &Content[string]{CType: RpcContentJson, Dt: string(b)}, nil
+	case RpcContentBytes:
+		data := make([][]byte, len(args))
+		for i, param := range fun.Param {
+			b, err := encoding.GetCodec(param.Codec).Marshal(args[i])
+			if err != nil {
+				return nil, err
+			}
+			data[i] = b
+		}
+		return &Content[[][]byte]{CType: RpcContentBytes, Dt: data}, nil
+	case RpcContentFile:
+		// io.Reader 参数分块上传，其余参数按编解码器编码
+		data := &FileData{Args: make([][]byte, 0, len(args))}
+		for i, param := range fun.Param {
+			if param.Type == readerType {
+				data.Reader, _ = args[i].(io.Reader)
+				continue
+			}
+			b, err := encoding.GetCodec(param.Codec).Marshal(args[i])
+			if err != nil {
+				return nil, err
+			}
+			data.Args = append(data.Args, b)
+		}
+		return &Content[*FileData]{CType: RpcContentFile, Dt: data}, nil
+	default:
+		return nil, errors.New(400, "UNSUPPORTED_METHOD", fmt.Sprintf("unsupported content type %d", cp.ContentType()))
+	}
+}
+
+// decodeResults 解码返回值到 results（与服务端 Packer.Serialize 对应）
+func decodeResults(fun *FunInfo, rsp RpcContent, results []any) error {
+	if len(results) == 0 {
+		return nil
+	}
+	if rsp == nil {
+		return errors.New(500, "INVALID_RESPONSE", "empty response")
+	}
+	switch data := rsp.Data().(type) {
+	case string:
+		// JsonPacker 只返回第一个非 error 返回值
+		return decodeInto(results[0], fun.ReturnParam[0].Type, func(v any) error {
+			return encoding.GetCodec("json").Unmarshal([]byte(data), v)
+		})
+	case [][]byte:
+		for i, ret := range fun.ReturnParam {
+			if i >= len(data) {
+				break
+			}
+			codec := encoding.GetCodec(ret.Codec)
+			if err := decodeInto(results[i], ret.Type, func(v any) error {
+				return codec.Unmarshal(data[i], v)
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *FileData:
+		j := 0
+		for i, ret := range fun.ReturnParam {
+			if ret.Type == readerType {
+				if err := decodeInto(results[i], ret.Type, func(v any) error {
+					if data.Reader != nil {
+						reflect.ValueOf(v).Elem().Set(reflect.ValueOf(data.Reader))
+					}
+					return nil
+				}); err != nil {
+					return err
+				}
+				continue
+			}
+			if j >= len(data.Args) {
+				continue
+			}
+			codec, b := encoding.GetCodec(ret.Codec), data.Args[j]
+			j++
+			if err := decodeInto(results[i], ret.Type, func(v any) error {
+				return codec.Unmarshal(b, v)
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errors.New(500, "INVALID_RESPONSE", fmt.Sprintf("unexpected response data %T", data))
+	}
+}
+
+// assignable 参数值能否作为类型 t 的实参
+func assignable(arg any, t reflect.Type) bool {
+	if arg == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			return true
+		}
+		return false
+	}
+	return reflect.TypeOf(arg).AssignableTo(t)
+}
+
+// decodeInto 按返回值类型 t 解码并写入目标指针 dst
+func decodeInto(dst any, t reflect.Type, unmarshal func(v any) error) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Type() != t {
+		return errors.New(400, "INVALID_RESULTS", fmt.Sprintf("result destination must be *%v, got %T", t, dst))
+	}
+	if t.Kind() == reflect.Ptr {
+		v := reflect.New(t.Elem())
+		if err := unmarshal(v.Interface()); err != nil {
+			return err
+		}
+		dv.Elem().Set(v)
+		return nil
+	}
+	return unmarshal(dst)
+}
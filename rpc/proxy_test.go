@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Calculator 服务端与客户端共用的接口定义
+type Calculator interface {
+	Add(ctx context.Context, a, b int) (int, error)
+	Greet(name string) (string, error)
+	Echo(ctx context.Context, req *rpc.TestRequest) (*rpc.TestResponse, error)
+	Describe(ctx context.Context, in JsonStructParam) (*JsonStructResult, error)
+	Fail(ctx context.Context, reason string) error
+}
+
+type JsonStructParam struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+type JsonStructResult struct {
+	Summary string `json:"summary"`
+}
+
+type calculatorImpl struct{}
+
+func (calculatorImpl) Add(_ context.Context, a, b int) (int, error) { return a + b, nil }
+
+func (calculatorImpl) Greet(name string) (string, error) { return "hi " + name, nil }
+
+func (calculatorImpl) Echo(_ context.Context, req *rpc.TestRequest) (*rpc.TestResponse, error) {
+	return &rpc.TestResponse{Success: true, Message: req.Name}, nil
+}
+
+func (calculatorImpl) Describe(_ context.Context, in JsonStructParam) (*JsonStructResult, error) {
+	return &JsonStructResult{Summary: in.Name + "/" + string(rune('0'+in.Age))}, nil
+}
+
+func (calculatorImpl) Fail(_ context.Context, reason string) error {
+	return errors.New(409, reason, "failed")
+}
+
+var _ Calculator = calculatorImpl{}
+
+func TestServiceProxy(t *testing.T) {
+	for _, stateful := range []bool{false, true} {
+		name := "unary"
+		if stateful {
+			name = "stream"
+		}
+		t.Run(name, func(t *testing.T) {
+			srv := NewRpcServer()
+			require.NoError(t, srv.Register(calculatorImpl{}))
+			p, err := NewServiceProxy[Calculator](dialBufconn(t, srv, stateful))
+			require.NoError(t, err)
+			ctx := context.Background()
+
+			var sum int
+			require.NoError(t, p.Invoke(ctx, "Add", []any{2, 3}, &sum))
+			assert.Equal(t, 5, sum)
+
+			var greeting string
+			require.NoError(t, p.Invoke(ctx, "Greet", []any{"bob"}, &greeting))
+			assert.Equal(t, "hi bob", greeting)
+
+			var echo *rpc.TestResponse
+			require.NoError(t, p.Invoke(ctx, "Echo", []any{&rpc.TestRequest{Name: "x"}}, &echo))
+			assert.True(t, echo.Success)
+			assert.Equal(t, "x", echo.Message)
+
+			var desc *JsonStructResult
+			require.NoError(t, p.Invoke(ctx, "Describe", []any{JsonStructParam{Name: "amy", Age: 7}}, &desc))
+			assert.Equal(t, "amy/7", desc.Summary)
+
+			err = p.Invoke(ctx, "Fail", []any{"CONFLICT"})
+			assert.Equal(t, "CONFLICT", errors.Reason(err))
+			assert.Equal(t, 409, errors.Code(err))
+		})
+	}
+}
+
+func TestServiceProxy_InvalidUse(t *testing.T) {
+	_, err := NewServiceProxy[calculatorImpl](nil)
+	assert.Error(t, err)
+
+	p, err := NewServiceProxy[Calculator](nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var sum int
+	assert.Equal(t, "METHOD_NOT_FOUND", errors.Reason(p.Invoke(ctx, "Sub", nil)))
+	assert.Equal(t, "INVALID_ARGS", errors.Reason(p.Invoke(ctx, "Add", []any{1}, &sum)))
+	assert.Equal(t, "INVALID_ARGS", errors.Reason(p.Invoke(ctx, "Add", []any{1, "2"}, &sum)))
+	assert.Equal(t, "INVALID_RESULTS", errors.Reason(p.Invoke(ctx, "Add", []any{1, 2})))
+}
@@ -6,7 +6,10 @@ import (
 	"reflect"
 
 	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 // RpcServer默认实现
@@ -14,17 +17,36 @@ type rpcServer struct {
 	rpcType      RpcType
 	funToService map[string]*CallDispatcher //通过msgId找到对应的service
 	services     map[string]*CallDispatcher //通过serviceName找到对应的service
+	middleware   matcher.Matcher            //按msgId匹配调用经过的中间件
+
+	// handlers 通过msgId找到注册时构建好的调用链（中间件 + 分发）
+	handlers map[string]middleware.Handler
 }
 
-// NewRpcServer 创建新的RPC服务器
-func NewRpcServer(rpcType RpcType) RpcServerDriver {
+// NewRpcServer 创建新的RPC服务器；默认 gRPC 传输，WithHttpTransport 选择 HTTP
+func NewRpcServer(opts ...ServerOption) RpcServerDriver {
+	o := serverOptions{methodMs: make(map[string][]middleware.Middleware)}
+	for _, fn := range opts {
+		fn(&o)
+	}
+	rpcType := grpcType
+	if o.http {
+		rpcType = httpType
+	}
 	return &rpcServer{
 		rpcType:      rpcType,
 		funToService: make(map[string]*CallDispatcher),
 		services:     make(map[string]*CallDispatcher),
+		middleware:   newMiddlewareMatcher(&o),
+		handlers:     make(map[string]middleware.Handler),
 	}
 }
 
+// RpcType 服务器的传输类型
+func (s *rpcServer) RpcType() RpcType {
+	return s.rpcType
+}
+
 // Register 注册服务（支持扩展）
 func (s *rpcServer) Register(service any) error {
 	// 检查nil值
@@ -54,15 +76,16 @@ func (s *rpcServer) Register(service any) error {
 
 	// 创建服务实例
 	callDispatcher := NewCallDispatcher(serviceName)
+	methodOpts := methodOptionsOf(service)
 	// 遍历服务的方法
 	for i := 0; i < serviceType.NumMethod(); i++ {
 		method := serviceType.Method(i)
 		methodValue := serviceValue.Method(i)
 
-		// 跳过私有方法
-		if method.IsExported() {
+		// 跳过私有方法与方法选项声明
+		if method.IsExported() && !(methodOpts != nil && method.Name == "RpcMethodOptions") {
 			// 注册方法
-			err := callDispatcher.RegisterMethod(method.Name, methodValue.Interface())
+			err := callDispatcher.RegisterMethod(method.Name, methodValue.Interface(), methodOpts[method.Name]...)
 			if err != nil {
 				log.Errorf("Failed to register Method %s: %v", method.Name, err)
 				continue
@@ -83,19 +106,42 @@ func (s *rpcServer) registerService(service *CallDispatcher) {
 	// 将服务的方法注册到funToService映射中
 	for msgId, funInfo := range service.RpcMethod {
 		s.funToService[msgId] = service
+		s.handlers[msgId] = s.buildHandler(service, msgId)
 		log.Infof("Registered CallDispatcher Method: %s -> %s.%s", msgId, service.name, funInfo.Name)
 	}
 }
 
+// buildHandler 构建 msgId 的调用链：匹配的中间件在注册时组装一次，调用时直接执行
+func (s *rpcServer) buildHandler(callDispatcher *CallDispatcher, msgId string) middleware.Handler {
+	h := func(ctx context.Context, req any) (any, error) {
+		content, ok := req.(RpcContent)
+		if !ok {
+			return nil, errors.New(400, "INVALID_DATA_TYPE", fmt.Sprintf("unexpected request type %T", req))
+		}
+		return callDispatcher.Dispatch(ctx, msgId, content)
+	}
+	if ms := s.middleware.Match(msgId); len(ms) > 0 {
+		h = middleware.Chain(ms...)(h)
+	}
+	return h
+}
+
 // OnCall 处理RPC调用
+// 调用依次经过 WithMiddleware 与匹配 msgId 的中间件后分发；上下文中的 *Transport 携带 msgId 与调用方服务名。
 func (s *rpcServer) OnCall(ctx context.Context, srcService string, msgId string, data RpcContent) (RpcContent, error) {
-	log.Infof("RPC call from %s, msgId: %s", srcService, msgId)
+	log.Debugf("RPC call from %s, msgId: %s", srcService, msgId)
 
 	// 查找对应的服务
-	callDispatcher, exists := s.funToService[msgId]
+	h, exists := s.handlers[msgId]
 	if !exists {
 		return nil, errors.New(404, "SERVICE_NOT_FOUND", fmt.Sprintf("CallDispatcher not found for msgId: %s", msgId))
 	}
-	return callDispatcher.Dispatch(ctx, msgId, data)
-
+	ctx = transport.NewServerContext(ctx, newServerTransport(ctx, srcService, msgId))
+	reply, err := h(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	// 中间件可能以 nil 接口短路返回
+	result, _ := reply.(RpcContent)
+	return result, nil
 }
@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc"
+)
+
+// rpcCodeHeader 携带原始错误码的响应头
+// kratos 错误码不一定是合法的 HTTP 状态码（如 1001），此时状态码取 500，客户端从该头还原原始错误码。
+const rpcCodeHeader = "X-Rpc-Code"
+
+// RouterServiceHTTPServerImpl RouterService的HTTP服务器实现
+// 路由由 call_http.pb.go 生成（POST /rpc/JsonMessage、/rpc/RpcCall），与 gRPC 共用调用逻辑；
+// 错误以 kratos 错误返回，由 HTTP 传输按错误码映射为响应状态码。
+type RouterServiceHTTPServerImpl struct {
+	server *RouterServiceServerImpl
+}
+
+// NewRouterServiceHTTPServer 创建RouterService的HTTP服务器
+func NewRouterServiceHTTPServer(rpcServer RpcServerDriver) rpc.RouterServiceHTTPServer {
+	return &RouterServiceHTTPServerImpl{
+		server: &RouterServiceServerImpl{rpcServer: rpcServer},
+	}
+}
+
+// JsonMessage 处理JSON消息
+func (s *RouterServiceHTTPServerImpl) JsonMessage(ctx context.Context, req *rpc.JsonReq) (rsp *rpc.JsonRsp, err error) {
+	log.Debugf("Received HTTP JSON message from %s, msgId: %s", req.SrcService, req.MsgId)
+
+	ctx, span := startUnaryServerSpan(ctx, req.MsgId, req.SrcService)
+	defer func() { serverTracer.End(ctx, span, rsp, err) }()
+
+	if rsp, err = s.server.jsonMessage(ctx, req); err != nil {
+		return nil, toHttpError(ctx, err)
+	}
+	return rsp, nil
+}
+
+// RpcCall 处理RPC调用
+func (s *RouterServiceHTTPServerImpl) RpcCall(ctx context.Context, req *rpc.Req) (rsp *rpc.Rsp, err error) {
+	log.Debugf("Received HTTP RPC call from %s, msgId: %s", req.SrcService, req.MsgId)
+
+	ctx, span := startUnaryServerSpan(ctx, req.MsgId, req.SrcService)
+	defer func() { serverTracer.End(ctx, span, rsp, err) }()
+
+	if rsp, err = s.server.rpcCall(ctx, req); err != nil {
+		return nil, toHttpError(ctx, err)
+	}
+	return rsp, nil
+}
+
+// toHttpError HTTP 调用失败时返回的错误
+// 错误码写入 X-Rpc-Code 响应头；不是合法 HTTP 状态码时以 500 响应。
+func toHttpError(ctx context.Context, err error) error {
+	se := errors.FromError(err)
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		tr.ReplyHeader().Set(rpcCodeHeader, strconv.Itoa(int(se.Code)))
+	}
+	if se.Code < 100 || se.Code > 599 {
+		se = errors.Clone(se)
+		se.Code = 500
+	}
+	return se
+}
+
+// RegisterRouterService 按 RPC 服务器的类型将 RouterService 注册到传输服务器
+// 默认注册到 gRPC 服务器（*grpc.Server 或 kratos transport/grpc.Server），
+// 以 WithHttpTransport 创建的服务器注册到 kratos transport/http.Server；opts 仅对 gRPC 的 StreamCall 生效。
+// 未实现 RpcType() 的服务器驱动按传输服务器的类型注册。
+func RegisterRouterService(srv any, rpcServer RpcServerDriver, opts ...StreamOption) error {
+	var rpcType RpcType
+	switch srv.(type) {
+	case *khttp.Server:
+		rpcType = httpType
+	case grpc.ServiceRegistrar:
+		rpcType = grpcType
+	default:
+		return errors.New(400, "UNSUPPORTED_TRANSPORT", fmt.Sprintf("unsupported transport server %T", srv))
+	}
+	if typed, ok := rpcServer.(interface{ RpcType() RpcType }); ok && typed.RpcType() != rpcType {
+		return errors.New(400, "RPC_TYPE_MISMATCH", fmt.Sprintf("rpc server type %d does not match transport server %T", typed.RpcType(), srv))
+	}
+
+	if rpcType == httpType {
+		rpc.RegisterRouterServiceHTTPServer(srv.(*khttp.Server), NewRouterServiceHTTPServer(rpcServer))
+	} else {
+		rpc.RegisterRouterServiceServer(srv.(grpc.ServiceRegistrar), NewRouterServiceServer(rpcServer, opts...))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/aegis/ratelimit"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/middleware"
+	kratelimit "github.com/go-kratos/kratos/v2/middleware/ratelimit"
+)
+
+// ServerOption RPC服务器选项
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	http      bool // RouterService 注册到 transport/http.Server
+	ms        []middleware.Middleware
+	selectors []string                           // 按添加顺序
+	methodMs  map[string][]middleware.Middleware // 选择器 -> 中间件
+}
+
+// WithHttpTransport 服务器经 HTTP 传输（RegisterRouterService 注册到 kratos transport/http.Server）
+func WithHttpTransport() ServerOption {
+	return func(o *serverOptions) { o.http = true }
+}
+
+// WithMiddleware 所有调用经过的中间件
+// 中间件可通过 transport.FromServerContext 取得 *Transport，读取 msgId（Operation）与调用方服务名。
+func WithMiddleware(m ...middleware.Middleware) ServerOption {
+	return func(o *serverOptions) { o.ms = append(o.ms, m...) }
+}
+
+// WithMethodMiddleware 为匹配 selector 的调用添加中间件，在 WithMiddleware 的中间件之后执行
+// selector 为 msgId，或以 * 结尾的 msgId 前缀（如 "Entity*"）；
+// 同一 msgId 只匹配一个选择器：精确匹配优先，其次最长前缀，与 transport/grpc.Server.Use 一致。
+func WithMethodMiddleware(selector string, m ...middleware.Middleware) ServerOption {
+	return func(o *serverOptions) {
+		if _, ok := o.methodMs[selector]; !ok {
+			o.selectors = append(o.selectors, selector)
+		}
+		o.methodMs[selector] = append(o.methodMs[selector], m...)
+	}
+}
+
+// WithMethodTimeout 匹配 selector 的调用的超时：为调用上下文设置截止时间，由接收 context.Context 的方法响应
+func WithMethodTimeout(selector string, timeout time.Duration) ServerOption {
+	return WithMethodMiddleware(selector, timeoutMiddleware(timeout))
+}
+
+// WithMethodRateLimit 匹配 selector 的调用的限流：limiter 拒绝时返回 429 RATELIMIT
+// limiter 在匹配的调用间共享，如需每个方法独立限流应为每个 msgId 分别设置。
+func WithMethodRateLimit(selector string, limiter ratelimit.Limiter) ServerOption {
+	return WithMethodMiddleware(selector, kratelimit.Server(kratelimit.WithLimiter(limiter)))
+}
+
+func timeoutMiddleware(timeout time.Duration) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return handler(ctx, req)
+		}
+	}
+}
+
+// newMiddlewareMatcher 按选项构建中间件匹配器
+func newMiddlewareMatcher(o *serverOptions) matcher.Matcher {
+	m := matcher.New()
+	m.Use(o.ms...)
+	for _, selector := range o.selectors {
+		m.Add(selector, o.methodMs[selector]...)
+	}
+	return m
+}
@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/aegis/ratelimit"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Deadliner 报告调用上下文的剩余时间
+type Deadliner interface {
+	Remaining(ctx context.Context) (int64, error)
+	RemainingAdmin(ctx context.Context) (int64, error)
+}
+
+type deadlineService struct{}
+
+func (deadlineService) Remaining(ctx context.Context) (int64, error) {
+	if d, ok := ctx.Deadline(); ok {
+		return int64(time.Until(d)), nil
+	}
+	return -1, nil
+}
+
+func (s deadlineService) RemainingAdmin(ctx context.Context) (int64, error) { return s.Remaining(ctx) }
+
+// countLimiter 放行前 n 次调用
+type countLimiter struct{ n atomic.Int32 }
+
+func (l *countLimiter) Allow() (ratelimit.DoneFunc, error) {
+	if l.n.Add(-1) < 0 {
+		return nil, ratelimit.ErrLimitExceed
+	}
+	return func(ratelimit.DoneInfo) {}, nil
+}
+
+func TestServerMiddleware(t *testing.T) {
+	var seen []string
+	record := func(name string) middleware.Middleware {
+		return func(next middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req any) (any, error) {
+				tr, ok := transport.FromServerContext(ctx)
+				require.True(t, ok)
+				rt := tr.(*Transport)
+				assert.Equal(t, KindRpc, tr.Kind())
+				assert.Equal(t, "src", rt.SrcService())
+				seen = append(seen, name+":"+tr.Operation())
+				return next(ctx, req)
+			}
+		}
+	}
+	deny := func(middleware.Handler) middleware.Handler {
+		return func(context.Context, any) (any, error) {
+			return nil, errors.Forbidden("DENIED", "denied by middleware")
+		}
+	}
+
+	srv := NewRpcServer(
+		WithMiddleware(record("all")),
+		WithMethodMiddleware("G*", record("prefix")),
+		WithMethodMiddleware("Greet", record("exact")),
+		WithMethodMiddleware("Fa*", deny),
+	)
+	require.NoError(t, srv.Register(calculatorImpl{}))
+	p, err := NewServiceProxy[Calculator](dialBufconn(t, srv, false))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var sum int
+	require.NoError(t, p.Invoke(ctx, "Add", []any{1, 2}, &sum))
+	var greeting string
+	require.NoError(t, p.Invoke(ctx, "Greet", []any{"bob"}, &greeting))
+	assert.Equal(t, "hi bob", greeting)
+	// 精确匹配优先于前缀
+	assert.Equal(t, []string{"all:Add", "all:Greet", "exact:Greet"}, seen)
+
+	err = p.Invoke(ctx, "Fail", []any{"CONFLICT"})
+	assert.Equal(t, "DENIED", errors.Reason(err))
+	assert.Equal(t, 403, errors.Code(err))
+}
+
+func TestServerMethodOptions(t *testing.T) {
+	limiter := &countLimiter{}
+	limiter.n.Store(2)
+	srv := NewRpcServer(
+		WithMethodTimeout("Remaining*", time.Minute),
+		WithMethodTimeout("RemainingAdmin", time.Hour),
+		WithMethodRateLimit("RemainingAdmin", limiter),
+	)
+	require.NoError(t, srv.Register(deadlineService{}))
+	p, err := NewServiceProxy[Deadliner](dialBufconn(t, srv, true))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var remaining int64
+	require.NoError(t, p.Invoke(ctx, "Remaining", nil, &remaining))
+	assert.InDelta(t, float64(time.Minute), float64(remaining), float64(time.Second))
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, p.Invoke(ctx, "RemainingAdmin", nil, &remaining))
+		assert.InDelta(t, float64(time.Hour), float64(remaining), float64(time.Second))
+	}
+	err = p.Invoke(ctx, "RemainingAdmin", nil, &remaining)
+	assert.Equal(t, "RATELIMIT", errors.Reason(err))
+	assert.Equal(t, 429, errors.Code(err))
+}
+
+func TestServerMiddlewareBuiltOnce(t *testing.T) {
+	var built atomic.Int32
+	count := func(next middleware.Handler) middleware.Handler {
+		built.Add(1)
+		return next
+	}
+	srv := NewRpcServer(WithMiddleware(count))
+	require.NoError(t, srv.Register(calculatorImpl{}))
+	// 注册时为每个 msgId 组装一次，调用时不再重建
+	registered := built.Load()
+	require.Positive(t, registered)
+
+	p, err := NewServiceProxy[Calculator](dialBufconn(t, srv, false))
+	require.NoError(t, err)
+	var sum int
+	for i := 0; i < 3; i++ {
+		require.NoError(t, p.Invoke(context.Background(), "Add", []any{1, 2}, &sum))
+	}
+	assert.Equal(t, registered, built.Load())
+}
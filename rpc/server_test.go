@@ -88,7 +88,7 @@ func (s *MessagePackService) ProcessMessagePack(data map[string]interface{}) (ma
 
 func TestRpcServer(t *testing.T) {
 	// 创建RPC服务器
-	rpcServer := NewRpcServer(grpcType)
+	rpcServer := NewRpcServer()
 
 	// 创建测试服务
 	testService := NewTestService("test-CallDispatcher")
@@ -337,7 +337,7 @@ func TestContentInfo(t *testing.T) {
 
 // TestPackerSelection 测试打包器选择逻辑
 func TestPackerSelection(t *testing.T) {
-	rpcServer := NewRpcServer(grpcType)
+	rpcServer := NewRpcServer()
 
 	// 测试基础类型服务（应该使用MessagePacker）
 	t.Run("TestBasicTypes", func(t *testing.T) {
@@ -470,7 +470,7 @@ func TestPackerSelection(t *testing.T) {
 
 // TestPackerComparison 同时测试三种打包器
 func TestPackerComparison(t *testing.T) {
-	rpcServer := NewRpcServer(grpcType)
+	rpcServer := NewRpcServer()
 
 	// 创建测试服务
 	protoService := &ProtoService{}
@@ -700,7 +700,7 @@ func TestPackerComparison(t *testing.T) {
 
 // TestInterfaceRegistration 测试接口类型注册
 func TestInterfaceRegistration(t *testing.T) {
-	rpcServer := NewRpcServer(grpcType)
+	rpcServer := NewRpcServer()
 
 	// 测试匿名结构体服务注册（应该失败）
 	t.Run("TestAnonymousStructServiceShouldFail", func(t *testing.T) {
@@ -0,0 +1,370 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// errTransferDetached 传输所在的流已断开，等待续传
+var errTransferDetached = errors.New(503, "TRANSFER_DETACHED", "transfer is waiting for resume")
+
+// transferManager 服务端分块传输（RpcContentFile）管理
+// 传输按 reqId 登记，生命周期独立于承载它的流：流断开后保留 resumeTimeout，期间客户端可在新流上续传。
+type transferManager struct {
+	server RpcServerDriver
+	opts   streamOptions
+
+	mu        sync.Mutex
+	transfers map[string]*serverTransfer
+}
+
+func newTransferManager(server RpcServerDriver, opts streamOptions) *transferManager {
+	return &transferManager{
+		server:    server,
+		opts:      opts,
+		transfers: make(map[string]*serverTransfer),
+	}
+}
+
+// serverTransfer 服务端的一次分块传输调用
+type serverTransfer struct {
+	m      *transferManager
+	reqId  string
+	msgId  string
+	cancel context.CancelFunc
+	upload *chunkReceiver // 方法的 io.Reader 参数
+
+	mu       sync.Mutex
+	sender   *streamSender  // 当前流，断开时为 nil
+	download *chunkSender   // 方法返回的 io.Reader
+	args     [][]byte       // 方法的普通返回值，随 offset 为 0 的下载分块发送
+	result   *rpc.StreamRsp // 无下载时的结果响应，续传时重发
+	timer    *time.Timer    // 断开后的续传超时或结束后的保留期
+	closed   bool
+}
+
+// onChunk 处理携带分块的请求；在流的接收循环中调用，不阻塞
+func (m *transferManager) onChunk(ctx context.Context, sender *streamSender, req *rpc.StreamReq) {
+	c := req.Chunk
+	m.mu.Lock()
+	t := m.transfers[req.ReqId]
+	m.mu.Unlock()
+
+	if t == nil {
+		switch {
+		case c.Cancel || req.MsgId == "":
+			// 已结束传输的迟到消息
+		case c.Resume && c.Offset > 0:
+			sendTransferError(sender, req, errors.New(410, "TRANSFER_NOT_FOUND", fmt.Sprintf("transfer %s not found", req.ReqId)))
+		default:
+			// 新的传输；续传偏移为 0 时服务端尚未收到首条消息，同样重新开始
+			m.start(ctx, sender, req)
+		}
+		return
+	}
+
+	switch {
+	case c.Cancel:
+		t.abort(nil)
+	case c.Resume:
+		t.resume(sender, c.Offset, c.Ack)
+	case c.Ack > 0 && len(c.Data) == 0 && !c.Eof:
+		t.ackDownload(c.Ack)
+	default:
+		if err := t.upload.push(c); err != nil {
+			t.abort(err)
+		}
+	}
+}
+
+// start 登记传输并异步调用方法；调用的生命周期不随流结束
+func (m *transferManager) start(ctx context.Context, sender *streamSender, req *rpc.StreamReq) {
+	m.mu.Lock()
+	if len(m.transfers) >= m.opts.maxTransfers {
+		m.mu.Unlock()
+		sendTransferError(sender, req, errors.New(503, "TOO_MANY_TRANSFERS", fmt.Sprintf("transfer limit %d reached", m.opts.maxTransfers)))
+		return
+	}
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	t := &serverTransfer{
+		m:      m,
+		reqId:  req.ReqId,
+		msgId:  req.MsgId,
+		cancel: cancel,
+		sender: sender,
+	}
+	// 上传按客户端的窗口发送
+	t.upload = newChunkReceiver(defaultTransferWindow, t.ackUpload)
+	m.transfers[req.ReqId] = t
+	m.mu.Unlock()
+
+	if req.Chunk.Resume {
+		t.resume(sender, 0, 0)
+	} else if err := t.upload.push(req.Chunk); err != nil {
+		t.abort(err)
+		return
+	}
+	go t.invoke(ctx, req)
+}
+
+// detach 流结束：其上的传输暂停并等待续传
+func (m *transferManager) detach(sender *streamSender) {
+	m.mu.Lock()
+	transfers := make([]*serverTransfer, 0, len(m.transfers))
+	for _, t := range m.transfers {
+		transfers = append(transfers, t)
+	}
+	m.mu.Unlock()
+	for _, t := range transfers {
+		t.detach(sender)
+	}
+}
+
+func (m *transferManager) remove(t *serverTransfer) {
+	m.mu.Lock()
+	if m.transfers[t.reqId] == t {
+		delete(m.transfers, t.reqId)
+	}
+	m.mu.Unlock()
+}
+
+// invoke 调用方法：上传内容作为 io.Reader 参数，返回的 io.Reader 分块下载
+func (t *serverTransfer) invoke(ctx context.Context, req *rpc.StreamReq) {
+	var (
+		rsp *rpc.StreamRsp
+		err error
+	)
+	ctx, span := startStreamServerSpan(ctx, req)
+	if span != nil {
+		defer func() { serverTracer.End(ctx, span, rsp, err) }()
+	}
+
+	content := &Content[*FileData]{
+		CType: RpcContentFile,
+		Dt:    &FileData{Args: req.MsgContent, Reader: t.upload},
+	}
+	result, err := t.m.server.OnCall(ctx, req.SrcService, req.MsgId, content)
+	// 方法返回后不再接收上传
+	t.upload.fail(errTransferDone)
+
+	rsp = &rpc.StreamRsp{
+		MsgId:      req.MsgId,
+		MsgContent: [][]byte{},
+		ReqId:      req.ReqId,
+		Spnid:      req.GetTraceinfo().GetSpanid(),
+	}
+	if err != nil {
+		log.Errorf("Transfer RPC call failed: %v", err)
+		rsp.Error = toRpcError(err)
+		t.finish(rsp)
+		return
+	}
+
+	var reader io.Reader
+	if result != nil {
+		switch data := result.Data().(type) {
+		case *FileData:
+			rsp.MsgContent, reader = data.Args, data.Reader
+		case [][]byte:
+			rsp.MsgContent = data
+		}
+	}
+	if reader == nil {
+		t.finish(rsp)
+		return
+	}
+	t.startDownload(rsp, reader)
+}
+
+// finish 发送结果响应；保留至客户端确认收到（Cancel）或保留期结束，以便续传时重发
+func (t *serverTransfer) finish(rsp *rpc.StreamRsp) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.result = rsp
+	sender := t.sender
+	t.resetTimer(nil)
+	t.mu.Unlock()
+	if sender != nil {
+		if err := sender.send(rsp); err != nil {
+			log.Errorf("Failed to send transfer result: %v", err)
+		}
+	}
+}
+
+// startDownload 分块发送方法返回的 io.Reader，完成后关闭（若实现 io.Closer）
+func (t *serverTransfer) startDownload(rsp *rpc.StreamRsp, reader io.Reader) {
+	ds := newChunkSender(reader, t.m.opts.chunkSize, t.m.opts.transferWindow)
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		closeReader(reader)
+		return
+	}
+	t.download = ds
+	t.args = rsp.MsgContent
+	bound := t.sender != nil
+	t.mu.Unlock()
+	if bound {
+		_ = ds.bind(t.sendDownload, 0)
+	}
+
+	go func() {
+		err := ds.run()
+		closeReader(reader)
+		if err != errTransferDone {
+			t.abort(errors.FromError(err))
+		}
+	}()
+}
+
+func closeReader(r io.Reader) {
+	if c, ok := r.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+// sendDownload 在当前流上发送下载分块；offset 为 0 的分块携带普通返回值
+func (t *serverTransfer) sendDownload(c *rpc.Chunk) error {
+	t.mu.Lock()
+	sender, args := t.sender, t.args
+	t.mu.Unlock()
+	if sender == nil {
+		return errTransferDetached
+	}
+	rsp := &rpc.StreamRsp{MsgId: t.msgId, ReqId: t.reqId, Chunk: c}
+	if c.Offset == 0 {
+		rsp.MsgContent = args
+	}
+	return sender.send(rsp)
+}
+
+// ackUpload 方法消费上传数据后向客户端确认
+func (t *serverTransfer) ackUpload(offset int64) {
+	t.mu.Lock()
+	sender := t.sender
+	t.mu.Unlock()
+	if sender != nil {
+		_ = sender.send(&rpc.StreamRsp{MsgId: t.msgId, ReqId: t.reqId, Chunk: &rpc.Chunk{Ack: offset}})
+	}
+}
+
+// ackDownload 客户端确认消费下载数据；全部消费后结束传输
+func (t *serverTransfer) ackDownload(offset int64) {
+	t.mu.Lock()
+	ds := t.download
+	t.mu.Unlock()
+	if ds == nil {
+		return
+	}
+	ds.ack(offset)
+	if ds.finished() {
+		t.abort(nil)
+	}
+}
+
+// resume 客户端在新流上续传：回复上传的续传位置，并从 offset 继续下载或重发结果；ack 为客户端已消费的下载偏移
+func (t *serverTransfer) resume(sender *streamSender, offset, ack int64) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.sender = sender
+	ds, result := t.download, t.result
+	if result == nil {
+		t.stopTimer()
+	}
+	t.mu.Unlock()
+
+	received, consumed := t.upload.resumePoint()
+	_ = sender.send(&rpc.StreamRsp{MsgId: t.msgId, ReqId: t.reqId, Chunk: &rpc.Chunk{Resume: true, Offset: received, Ack: consumed}})
+	switch {
+	case ds != nil:
+		ds.ack(ack)
+		if err := ds.bind(t.sendDownload, offset); err != nil {
+			t.abort(err)
+		}
+	case result != nil:
+		_ = sender.send(result)
+	}
+}
+
+// detach 流断开：暂停发送，超过 resumeTimeout 未续传则终止
+func (t *serverTransfer) detach(sender *streamSender) {
+	t.mu.Lock()
+	if t.closed || t.sender != sender {
+		t.mu.Unlock()
+		return
+	}
+	t.sender = nil
+	ds := t.download
+	if t.result == nil {
+		t.resetTimer(errors.New(504, "TRANSFER_RESUME_TIMEOUT", "transfer not resumed in time"))
+	}
+	t.mu.Unlock()
+	if ds != nil {
+		ds.unbind()
+	}
+}
+
+// resetTimer 在 resumeTimeout 后以 err 终止传输，需持有 t.mu
+func (t *serverTransfer) resetTimer(err error) {
+	t.stopTimer()
+	t.timer = time.AfterFunc(t.m.opts.resumeTimeout, func() { t.abort(err) })
+}
+
+// stopTimer 需持有 t.mu
+func (t *serverTransfer) stopTimer() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// abort 结束传输并释放资源；err 非 nil 时取消方法调用并通知客户端
+func (t *serverTransfer) abort(err error) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	t.stopTimer()
+	sender, ds, done := t.sender, t.download, t.result != nil
+	t.mu.Unlock()
+
+	t.m.remove(t)
+	t.cancel()
+	reason := err
+	if reason == nil {
+		reason = errors.New(499, "TRANSFER_CANCELED", "transfer canceled")
+	}
+	t.upload.fail(reason)
+	if ds != nil {
+		if err == nil && ds.finished() {
+			ds.close(errTransferDone)
+		} else {
+			ds.close(reason)
+		}
+	}
+	if err != nil && !done && sender != nil {
+		log.Errorf("Transfer %s aborted: %v", t.reqId, err)
+		_ = sender.send(&rpc.StreamRsp{MsgId: t.msgId, MsgContent: [][]byte{}, ReqId: t.reqId, Error: toRpcError(err)})
+	}
+}
+
+// sendTransferError 拒绝传输请求
+func sendTransferError(sender *streamSender, req *rpc.StreamReq, err error) {
+	log.Errorf("Transfer %s rejected: %v", req.ReqId, err)
+	_ = sender.send(&rpc.StreamRsp{MsgId: req.MsgId, MsgContent: [][]byte{}, ReqId: req.ReqId, Error: toRpcError(err)})
+}
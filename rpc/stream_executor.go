@@ -0,0 +1,253 @@
+package rpc
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// errStreamHandlerPanic 处理请求时发生 panic，作为该请求的错误响应返回
+var errStreamHandlerPanic = errors.InternalServer("STREAM_HANDLER_PANIC", "stream handler panicked")
+
+// StreamOption 配置服务端 StreamCall 的执行模型
+type StreamOption func(*streamOptions)
+
+type streamOptions struct {
+	workers      int                         // 每条流的并发处理数
+	maxInFlight  int                         // 每条流已接收未完成的请求上限
+	orderKey     func(*rpc.StreamReq) string // 顺序键；空键的请求无序并行
+	drainTimeout time.Duration               // 流关闭后等待在途请求完成的最长时间
+
+	// 分块传输（RpcContentFile）
+	transferWindow int64         // 下载已发送未确认的最大字节数
+	chunkSize      int           // 单个分块的最大字节数
+	resumeTimeout  time.Duration // 流断开后等待续传的最长时间
+	maxTransfers   int           // 同时进行的传输上限
+}
+
+func defaultStreamOptions() streamOptions {
+	return streamOptions{
+		workers:      16,
+		maxInFlight:  256,
+		orderKey:     (*rpc.StreamReq).GetOrderKey,
+		drainTimeout: 30 * time.Second,
+
+		transferWindow: defaultTransferWindow,
+		chunkSize:      defaultChunkSize,
+		resumeTimeout:  time.Minute,
+		maxTransfers:   64,
+	}
+}
+
+// WithStreamWorkers 设置每条流的并发处理数（默认 16；1 表示逐个处理）
+func WithStreamWorkers(n int) StreamOption {
+	return func(o *streamOptions) {
+		if n > 0 {
+			o.workers = n
+		}
+	}
+}
+
+// WithStreamMaxInFlight 设置每条流在途请求上限（默认 256）
+// 达到上限后暂停读取，由 gRPC 流控向客户端施加背压。
+func WithStreamMaxInFlight(n int) StreamOption {
+	return func(o *streamOptions) {
+		if n > 0 {
+			o.maxInFlight = n
+		}
+	}
+}
+
+// WithStreamOrderKey 设置顺序键提取函数（默认取 StreamReq.OrderKey）
+// 同一键的请求按到达顺序逐个处理，不同键及空键的请求并行处理。
+func WithStreamOrderKey(fn func(*rpc.StreamReq) string) StreamOption {
+	return func(o *streamOptions) {
+		if fn != nil {
+			o.orderKey = fn
+		}
+	}
+}
+
+// WithStreamDrainTimeout 设置流关闭后排空在途请求的超时（默认 30s）
+func WithStreamDrainTimeout(d time.Duration) StreamOption {
+	return func(o *streamOptions) {
+		if d > 0 {
+			o.drainTimeout = d
+		}
+	}
+}
+
+// WithTransferWindow 设置下载的背压窗口：已发送未被客户端消费的数据不超过该值
+// 默认且最大为客户端的接收窗口 1MiB；上传方向使用客户端的窗口。
+func WithTransferWindow(n int64) StreamOption {
+	return func(o *streamOptions) {
+		if n > 0 {
+			o.transferWindow = min(n, defaultTransferWindow)
+		}
+	}
+}
+
+// WithTransferChunkSize 设置下载分块大小（默认 64KiB）
+func WithTransferChunkSize(n int) StreamOption {
+	return func(o *streamOptions) {
+		if n > 0 {
+			o.chunkSize = n
+		}
+	}
+}
+
+// WithTransferResumeTimeout 设置流断开后保留传输等待续传的时间（默认 1m）
+func WithTransferResumeTimeout(d time.Duration) StreamOption {
+	return func(o *streamOptions) {
+		if d > 0 {
+			o.resumeTimeout = d
+		}
+	}
+}
+
+// WithMaxTransfers 设置同时进行的分块传输上限（默认 64），超出时拒绝新的传输
+func WithMaxTransfers(n int) StreamOption {
+	return func(o *streamOptions) {
+		if n > 0 {
+			o.maxTransfers = n
+		}
+	}
+}
+
+// streamExecutor 单条流的请求执行器
+// 固定数量的 worker 处理请求；有顺序键的请求按键排队，同一时刻每个键至多一个请求在执行。
+// 单个请求的处理 panic 时经 fail 返回错误响应，worker 与同键的后续请求不受影响。
+type streamExecutor struct {
+	handle   func(*rpc.StreamReq)
+	fail     func(*rpc.StreamReq, error)
+	orderKey func(*rpc.StreamReq) string
+	tasks    chan func()
+	slots    chan struct{} // 在途请求令牌
+
+	mu   sync.Mutex
+	keys map[string][]*rpc.StreamReq // 存在即表示该键正在执行，值为其后排队的请求
+
+	inflight sync.WaitGroup
+}
+
+func newStreamExecutor(o streamOptions, handle func(*rpc.StreamReq), fail func(*rpc.StreamReq, error)) *streamExecutor {
+	e := &streamExecutor{
+		handle:   handle,
+		fail:     fail,
+		orderKey: o.orderKey,
+		tasks:    make(chan func(), o.maxInFlight),
+		slots:    make(chan struct{}, o.maxInFlight),
+		keys:     make(map[string][]*rpc.StreamReq),
+	}
+	for i := 0; i < o.workers; i++ {
+		go func() {
+			for task := range e.tasks {
+				task()
+			}
+		}()
+	}
+	return e
+}
+
+// submit 提交请求；在途请求达到上限时阻塞，ctx 结束返回 false
+func (e *streamExecutor) submit(ctx context.Context, req *rpc.StreamReq) bool {
+	select {
+	case e.slots <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+	e.inflight.Add(1)
+
+	key := e.orderKey(req)
+	if key == "" {
+		e.tasks <- func() { e.run(req) }
+		return true
+	}
+	e.mu.Lock()
+	if q, ok := e.keys[key]; ok {
+		e.keys[key] = append(q, req)
+		e.mu.Unlock()
+		return true
+	}
+	e.keys[key] = nil
+	e.mu.Unlock()
+	e.tasks <- func() { e.runKey(key, req) }
+	return true
+}
+
+func (e *streamExecutor) run(req *rpc.StreamReq) {
+	defer func() {
+		<-e.slots
+		e.inflight.Done()
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			buf := make([]byte, 64<<10)
+			buf = buf[:runtime.Stack(buf, false)]
+			log.Errorf("Stream handler panic, msgId: %s, reqId: %s: %v\n%s", req.MsgId, req.ReqId, r, buf)
+			e.fail(req, errStreamHandlerPanic)
+		}
+	}()
+	e.handle(req)
+}
+
+// runKey 依次处理同一键的请求直到队列为空
+func (e *streamExecutor) runKey(key string, req *rpc.StreamReq) {
+	for {
+		e.run(req)
+		e.mu.Lock()
+		q := e.keys[key]
+		if len(q) == 0 {
+			delete(e.keys, key)
+			e.mu.Unlock()
+			return
+		}
+		req, e.keys[key] = q[0], q[1:]
+		e.mu.Unlock()
+	}
+}
+
+// drain 等待在途请求完成并停止 worker；超时返回 false（未完成的请求继续执行，但结果不再发送）
+func (e *streamExecutor) drain(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		e.inflight.Wait()
+		close(done)
+	}()
+	defer close(e.tasks)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// streamSender 串行发送响应；流处理结束后丢弃迟到的响应
+type streamSender struct {
+	mu     sync.Mutex
+	stream rpc.RouterService_StreamCallServer
+	closed bool
+}
+
+func (s *streamSender) send(rsp *rpc.StreamRsp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return context.Canceled
+	}
+	return s.stream.Send(rsp)
+}
+
+func (s *streamSender) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+}
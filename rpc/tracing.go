@@ -0,0 +1,135 @@
+package rpc
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
+	"github.com/go-kratos/kratos/v2/transport"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// 与 middleware/tracing 相同的 Tracer 与传播器（W3C trace context + baggage + kratos 元数据），
+// 使用全局 TracerProvider（otel.SetTracerProvider），未设置时为 noop。
+var (
+	clientTracer = tracing.NewTracer(trace.SpanKindClient)
+	serverTracer = tracing.NewTracer(trace.SpanKindServer)
+)
+
+// traceparentHeader W3C trace context 头
+const traceparentHeader = "traceparent"
+
+// headerCarrier 基于 gRPC 元数据的 TextMapCarrier（一元调用），同时满足 transport.Header
+type headerCarrier metadata.MD
+
+// Get returns the value associated with the passed key.
+func (mc headerCarrier) Get(key string) string {
+	vals := metadata.MD(mc).Get(key)
+	if len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// Set stores the key-value pair.
+func (mc headerCarrier) Set(key string, value string) {
+	metadata.MD(mc).Set(key, value)
+}
+
+// Add append value to key-values pair.
+func (mc headerCarrier) Add(key string, value string) {
+	metadata.MD(mc).Append(key, value)
+}
+
+// Keys lists the keys stored in this carrier.
+func (mc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for k := range metadata.MD(mc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns a slice of values associated with the passed key.
+func (mc headerCarrier) Values(key string) []string {
+	return metadata.MD(mc).Get(key)
+}
+
+// startUnaryClientSpan 创建客户端 span，并把追踪上下文注入出站 gRPC 元数据
+func startUnaryClientSpan(ctx context.Context, msgId, srcService string) (context.Context, trace.Span) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	ctx, span := clientTracer.Start(ctx, msgId, headerCarrier(md))
+	setRpcSpan(span, msgId, srcService)
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// startUnaryServerSpan 从入站请求头提取追踪上下文并创建服务端 span
+// 经 kratos 传输服务器（gRPC/HTTP）时读取传输层请求头，否则读取 gRPC 元数据。
+func startUnaryServerSpan(ctx context.Context, msgId, srcService string) (context.Context, trace.Span) {
+	var carrier propagation.TextMapCarrier
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		carrier = tr.RequestHeader()
+	} else {
+		md, _ := metadata.FromIncomingContext(ctx)
+		carrier = headerCarrier(md)
+	}
+	ctx, span := serverTracer.Start(ctx, msgId, carrier)
+	setRpcSpan(span, msgId, srcService)
+	return ctx, span
+}
+
+// startHttpClientSpan 创建客户端 span，并把追踪上下文注入 HTTP 请求头
+func startHttpClientSpan(ctx context.Context, msgId, srcService string, header http.Header) (context.Context, trace.Span) {
+	ctx, span := clientTracer.Start(ctx, msgId, propagation.HeaderCarrier(header))
+	setRpcSpan(span, msgId, srcService)
+	return ctx, span
+}
+
+// startStreamClientSpan 创建客户端 span，追踪上下文写入消息头并同步填充 TraceInfo（兼容旧服务端）
+func startStreamClientSpan(ctx context.Context, req *rpc.StreamReq) (context.Context, trace.Span) {
+	header := propagation.MapCarrier{}
+	ctx, span := clientTracer.Start(ctx, req.MsgId, header)
+	setRpcSpan(span, req.MsgId, req.SrcService)
+	req.Header = header
+	req.Traceinfo = traceInfoOf(span.SpanContext())
+	return ctx, span
+}
+
+// startStreamServerSpan 从消息头提取追踪上下文并创建服务端 span
+// 消息未携带 traceparent 且 TraceInfo.Sample 为 false 时不采样，返回 nil span。
+func startStreamServerSpan(ctx context.Context, req *rpc.StreamReq) (context.Context, trace.Span) {
+	header := propagation.MapCarrier(req.GetHeader())
+	if header.Get(traceparentHeader) == "" && !req.GetTraceinfo().GetSample() {
+		return ctx, nil
+	}
+	ctx, span := serverTracer.Start(ctx, req.MsgId, header)
+	setRpcSpan(span, req.MsgId, req.SrcService)
+	return ctx, span
+}
+
+// traceInfoOf 将 span 上下文折叠为 TraceInfo：traceid 取低 64 位
+func traceInfoOf(sc trace.SpanContext) *rpc.TraceInfo {
+	if !sc.IsValid() {
+		return &rpc.TraceInfo{}
+	}
+	tid, sid := sc.TraceID(), sc.SpanID()
+	return &rpc.TraceInfo{
+		Traceid: int64(binary.BigEndian.Uint64(tid[8:])),
+		Spanid:  int64(binary.BigEndian.Uint64(sid[:])),
+		Sample:  sc.IsSampled(),
+	}
+}
+
+func setRpcSpan(span trace.Span, msgId, srcService string) {
+	span.SetAttributes(
+		attribute.String("rpc.system", "kratos-rpc"),
+		attribute.String("rpc.method", msgId),
+		attribute.String("rpc.src_service", srcService),
+	)
+}
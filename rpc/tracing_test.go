@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/api/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceServerDriver 记录服务端处理时的 span 上下文
+type traceServerDriver struct{ seen chan trace.SpanContext }
+
+func (d traceServerDriver) OnCall(ctx context.Context, _ string, _ string, data RpcContent) (RpcContent, error) {
+	d.seen <- trace.SpanContextFromContext(ctx)
+	return data, nil
+}
+
+func (traceServerDriver) Register(any) error { return nil }
+
+func TestTracePropagation(t *testing.T) {
+	// 全局 TracerProvider 只能被包级 Tracer 绑定一次，各子测试共用同一个 recorder
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	tracer := otel.Tracer("test")
+
+	cases := []struct {
+		name     string
+		stateful bool
+		content  RpcContent
+	}{
+		{"json", false, &Content[string]{CType: RpcContentJson, Dt: "{}"}},
+		{"unary", false, &Content[[][]byte]{CType: RpcContentBytes, Dt: [][]byte{}}},
+		{"stream", true, &Content[[][]byte]{CType: RpcContentBytes, Dt: [][]byte{}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			drv := traceServerDriver{seen: make(chan trace.SpanContext, 1)}
+			c := dialBufconn(t, drv, tc.stateful)
+
+			ctx, parent := tracer.Start(context.Background(), "parent")
+			_, err := c.Call(ctx, "msg."+tc.name, tc.content)
+			parent.End()
+			require.NoError(t, err)
+
+			serverCtx := <-drv.seen
+			assert.Equal(t, parent.SpanContext().TraceID(), serverCtx.TraceID())
+			assert.True(t, serverCtx.IsSampled())
+
+			var client, server sdktrace.ReadOnlySpan
+			for _, s := range rec.Ended() {
+				if s.SpanContext().TraceID() != parent.SpanContext().TraceID() || s.Name() != "msg."+tc.name {
+					continue
+				}
+				switch s.SpanKind() {
+				case trace.SpanKindClient:
+					client = s
+				case trace.SpanKindServer:
+					server = s
+				}
+			}
+			require.NotNil(t, client)
+			require.NotNil(t, server)
+			assert.Equal(t, parent.SpanContext().SpanID(), client.Parent().SpanID())
+			assert.Equal(t, client.SpanContext().SpanID(), server.Parent().SpanID())
+			assert.Equal(t, server.SpanContext().SpanID(), serverCtx.SpanID())
+		})
+	}
+
+	t.Run("stream unsampled", func(t *testing.T) {
+		drv := traceServerDriver{seen: make(chan trace.SpanContext, 1)}
+		srv := NewRouterServiceServer(drv).(*RouterServiceServerImpl)
+		rsp := srv.handleStream(context.Background(), &rpc.StreamReq{MsgId: "legacy", Traceinfo: &rpc.TraceInfo{Traceid: 1, Spanid: 7}})
+		assert.EqualValues(t, 7, rsp.Spnid)
+		assert.False(t, (<-drv.seen).IsValid())
+		for _, s := range rec.Ended() {
+			assert.NotEqual(t, "legacy", s.Name())
+		}
+	})
+}
+
+func TestTraceInfoOf(t *testing.T) {
+	assert.Equal(t, &rpc.TraceInfo{}, traceInfoOf(trace.SpanContext{}))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{15: 2},
+		SpanID:     trace.SpanID{7: 3},
+		TraceFlags: trace.FlagsSampled,
+	})
+	assert.Equal(t, &rpc.TraceInfo{Traceid: 2, Spanid: 3, Sample: true}, traceInfoOf(sc))
+}
@@ -0,0 +1,183 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedReader 读出前 n 字节后阻塞，直到 gate 关闭
+type gatedReader struct {
+	r       io.Reader
+	n       int
+	gate    chan struct{}
+	blocked chan struct{} // 开始阻塞时关闭
+	once    sync.Once
+}
+
+func newGatedReader(b []byte, n int) *gatedReader {
+	return &gatedReader{r: bytes.NewReader(b), n: n, gate: make(chan struct{}), blocked: make(chan struct{})}
+}
+
+func (g *gatedReader) Read(p []byte) (int, error) {
+	if g.n <= 0 {
+		g.once.Do(func() { close(g.blocked) })
+		<-g.gate
+		return g.r.Read(p)
+	}
+	if len(p) > g.n {
+		p = p[:g.n]
+	}
+	n, err := g.r.Read(p)
+	g.n -= n
+	return n, err
+}
+
+type blobService struct {
+	download io.Reader // 非 nil 时 Download 返回该 Reader
+}
+
+func (blobService) Upload(_ context.Context, name string, r io.Reader) (string, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d:%08x", name, len(b), crc32.ChecksumIEEE(b)), nil
+}
+
+func (s blobService) Download(size int) (io.Reader, int, error) {
+	if s.download != nil {
+		return s.download, size, nil
+	}
+	return bytes.NewReader(randomPayload(size)), size, nil
+}
+
+func (blobService) Echo(r io.Reader) (io.Reader, error) {
+	b, err := io.ReadAll(r)
+	return bytes.NewReader(b), err
+}
+
+func (blobService) Reject(r io.Reader) error {
+	return errors.New(413, "TOO_LARGE", "payload too large")
+}
+
+type blobClient interface {
+	Upload(ctx context.Context, name string, r io.Reader) (string, error)
+	Download(ctx context.Context, size int) (io.Reader, int, error)
+	Echo(ctx context.Context, r io.Reader) (io.Reader, error)
+	Reject(ctx context.Context, r io.Reader) error
+}
+
+func newBlobProxy(t *testing.T, svc blobService) (*ServiceProxy[blobClient], *rpcClient) {
+	srv := NewRpcServer()
+	require.NoError(t, srv.Register(svc))
+	c := dialBufconn(t, srv, false, WithTransferWindow(64<<10), WithTransferChunkSize(8<<10))
+	p, err := NewServiceProxy[blobClient](c)
+	require.NoError(t, err)
+	return p, c
+}
+
+func TestFileTransfer(t *testing.T) {
+	p, _ := newBlobProxy(t, blobService{})
+	ctx := context.Background()
+
+	payload := randomPayload(3 << 20)
+	var summary string
+	require.NoError(t, p.Invoke(ctx, "Upload", []any{"replay", bytes.NewReader(payload)}, &summary))
+	assert.Equal(t, fmt.Sprintf("replay:%d:%08x", len(payload), crc32.ChecksumIEEE(payload)), summary)
+
+	var (
+		r    io.Reader
+		size int
+	)
+	require.NoError(t, p.Invoke(ctx, "Download", []any{1 << 20}, &r, &size))
+	assert.Equal(t, 1<<20, size)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, randomPayload(1<<20), got)
+
+	require.NoError(t, p.Invoke(ctx, "Echo", []any{bytes.NewReader(payload[:100<<10])}, &r))
+	got, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, payload[:100<<10], got)
+
+	// 空负载
+	require.NoError(t, p.Invoke(ctx, "Echo", []any{nil}, &r))
+	got, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	err = p.Invoke(ctx, "Reject", []any{bytes.NewReader(payload)})
+	assert.Equal(t, "TOO_LARGE", errors.Reason(err))
+}
+
+// breakStream 断开客户端当前的流
+func breakStream(c *rpcClient) {
+	c.streamMu.Lock()
+	sc := c.sc
+	c.streamMu.Unlock()
+	sc.fail(io.ErrUnexpectedEOF)
+	c.dropStream(sc)
+}
+
+func TestFileTransfer_ResumeUpload(t *testing.T) {
+	p, c := newBlobProxy(t, blobService{})
+	payload := randomPayload(2 << 20)
+	src := newGatedReader(payload, 1<<20)
+
+	done := make(chan error, 1)
+	var summary string
+	go func() { done <- p.Invoke(context.Background(), "Upload", []any{"map", src}, &summary) }()
+
+	<-src.blocked
+	breakStream(c)
+	close(src.gate)
+
+	require.NoError(t, <-done)
+	assert.Equal(t, fmt.Sprintf("map:%d:%08x", len(payload), crc32.ChecksumIEEE(payload)), summary)
+}
+
+func TestFileTransfer_ResumeDownload(t *testing.T) {
+	payload := randomPayload(1 << 20)
+	src := newGatedReader(payload, 256<<10)
+	p, c := newBlobProxy(t, blobService{download: src})
+
+	var (
+		r    io.Reader
+		size int
+	)
+	require.NoError(t, p.Invoke(context.Background(), "Download", []any{len(payload)}, &r, &size))
+	head := make([]byte, 200<<10)
+	_, err := io.ReadFull(r, head)
+	require.NoError(t, err)
+
+	// 服务端可能已读到闸门处，也可能仍受窗口限制：两种情况都从客户端已收到的偏移续传
+	breakStream(c)
+	close(src.gate)
+
+	rest, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, payload, append(head, rest...))
+}
+
+func TestFileTransfer_Cancel(t *testing.T) {
+	p, _ := newBlobProxy(t, blobService{download: newGatedReader(randomPayload(1<<20), 128<<10)})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var (
+		r    io.Reader
+		size int
+	)
+	require.NoError(t, p.Invoke(ctx, "Download", []any{1 << 20}, &r, &size))
+	cancel()
+	_, err := io.ReadAll(r)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/grpc/metadata"
+)
+
+// KindRpc rpc 调用的传输类型
+const KindRpc transport.Kind = "rpc"
+
+var _ transport.Transporter = (*Transport)(nil)
+
+// Transport rpc 服务端调用的传输信息，由 rpcServer.OnCall 放入调用上下文
+// 请求头与响应头取自承载调用的 kratos 传输（gRPC/HTTP），否则取自入站 gRPC 元数据。
+type Transport struct {
+	endpoint    string
+	msgId       string
+	srcService  string
+	reqHeader   transport.Header
+	replyHeader transport.Header
+}
+
+// newServerTransport 为一次调用创建传输信息
+func newServerTransport(ctx context.Context, srcService, msgId string) *Transport {
+	tr := &Transport{msgId: msgId, srcService: srcService}
+	if outer, ok := transport.FromServerContext(ctx); ok {
+		tr.endpoint = outer.Endpoint()
+		tr.reqHeader = outer.RequestHeader()
+		tr.replyHeader = outer.ReplyHeader()
+		return tr
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	tr.reqHeader = headerCarrier(md)
+	tr.replyHeader = headerCarrier(metadata.MD{})
+	return tr
+}
+
+// Kind 返回 KindRpc
+func (tr *Transport) Kind() transport.Kind {
+	return KindRpc
+}
+
+// Endpoint 承载调用的服务端地址，未知时为空
+func (tr *Transport) Endpoint() string {
+	return tr.endpoint
+}
+
+// Operation 返回 msgId
+func (tr *Transport) Operation() string {
+	return tr.msgId
+}
+
+// MsgId 调用的 msgId
+func (tr *Transport) MsgId() string {
+	return tr.msgId
+}
+
+// SrcService 调用方服务名
+func (tr *Transport) SrcService() string {
+	return tr.srcService
+}
+
+// RequestHeader 请求头
+func (tr *Transport) RequestHeader() transport.Header {
+	return tr.reqHeader
+}
+
+// ReplyHeader 响应头
+func (tr *Transport) ReplyHeader() transport.Header {
+	return tr.replyHeader
+}
@@ -4,6 +4,8 @@ import (
 	"reflect"
 )
 
+// RpcType RPC 服务器的传输类型，决定 RouterService 注册到哪种传输服务器
+// 默认为 gRPC；HTTP 传输通过 WithHttpTransport 选择。
 type RpcType int
 
 const (
@@ -27,6 +29,19 @@ type FunInfo struct {
 	Packer      Packer
 }
 
+// PackerFor 按请求内容类型协商打包器：默认打包器不处理该内容类型时，
+// 改用处理该内容类型的已注册打包器，使同一方法既可按 JSON 也可按 bytes 调用
+func (f *FunInfo) PackerFor(ct RpcContentType) Packer {
+	// 未声明内容类型的自定义打包器不参与协商
+	if cp, ok := f.Packer.(ContentPacker); f.Packer != nil && (!ok || cp.ContentType() == ct) {
+		return f.Packer
+	}
+	if p := contentPacker(ct); p != nil {
+		return p
+	}
+	return f.Packer
+}
+
 // ParamTypes 返回方法参数类型列表
 func (f *FunInfo) ParamTypes() []reflect.Type {
 	types := make([]reflect.Type, 0, len(f.Param))